@@ -13,7 +13,6 @@ require (
 	github.com/google/go-cmp v0.7.0
 	github.com/mattn/go-runewidth v0.0.23
 	github.com/mhmtszr/concurrent-swiss-map v1.0.10
-	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/ryanbradynd05/go-tmdb v0.0.0-20230108222638-2a68dc6ff40c
 	github.com/spf13/cobra v1.10.2
 	gopkg.in/vansante/go-ffprobe.v2 v2.3.0
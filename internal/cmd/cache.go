@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/config"
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeCacheProvider  string
+	purgeCacheOlderThan string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or purge the provider metadata cache",
+	Long: `Inspect or purge the on-disk cache of provider responses.
+
+Responses from online providers are cached in ~/.title-tidy/cache so repeated
+runs don't query the same titles again. Entries expire after the provider's TTL,
+seven days unless cache_ttl_hours in the config says otherwise.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cached entries per provider",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheStats,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached entries",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheList,
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove cached entries",
+	Long: `Remove cached entries. Without flags every entry is removed.
+
+--provider limits the purge to one provider, and --older-than to entries stored
+longer ago than the given duration, such as 12h or 30d. Both may be combined.`,
+	Args: cobra.NoArgs,
+	RunE: runCachePurge,
+}
+
+func openMetadataCacheForCommand() (*provider.DiskCache, error) {
+	path, err := provider.DefaultCachePath()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := provider.OpenDiskCache(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg, err := config.Load(); err == nil {
+		cfg.ApplyCacheTTLs(cache)
+	}
+	return cache, nil
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	cache, err := openMetadataCacheForCommand()
+	if err != nil {
+		return err
+	}
+	writeCacheStats(cmd.OutOrStdout(), cache.Stats())
+	return nil
+}
+
+func writeCacheStats(out io.Writer, stats []provider.CacheStats) {
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "The metadata cache is empty.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tENTRIES\tEXPIRED\tOLDEST\tNEWEST")
+	total := 0
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", s.Provider, s.Entries, s.Expired, s.Oldest.Format("2006-01-02"), s.Newest.Format("2006-01-02"))
+		total += s.Entries
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d entries in total.\n", total)
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	cache, err := openMetadataCacheForCommand()
+	if err != nil {
+		return err
+	}
+	writeCacheEntries(cmd.OutOrStdout(), cache)
+	return nil
+}
+
+func writeCacheEntries(out io.Writer, cache *provider.DiskCache) {
+	entries := cache.Entries()
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "The metadata cache is empty.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tREQUEST\tSTORED\tSTATUS")
+	for _, entry := range entries {
+		status := "fresh"
+		if cache.Expired(entry) {
+			status = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Provider, entry.Key, entry.StoredAt.Format("2006-01-02 15:04"), status)
+	}
+	w.Flush()
+}
+
+func runCachePurge(cmd *cobra.Command, args []string) error {
+	var maxAge time.Duration
+	if purgeCacheOlderThan != "" {
+		age, err := parseAge(purgeCacheOlderThan)
+		if err != nil {
+			return err
+		}
+		maxAge = age
+	}
+
+	cache, err := openMetadataCacheForCommand()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := cache.Purge(func(entry provider.CacheEntry) bool {
+		if purgeCacheProvider != "" && entry.Provider != purgeCacheProvider {
+			return false
+		}
+		return maxAge == 0 || entry.StoredAt.Before(cutoff)
+	})
+	if err := cache.Flush(); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if removed == 1 {
+		fmt.Fprintln(out, "Removed 1 cached entry.")
+	} else {
+		fmt.Fprintf(out, "Removed %d cached entries.\n", removed)
+	}
+	return nil
+}
+
+// parseAge parses a positive duration, accepting a "d" suffix for days on top
+// of what time.ParseDuration understands.
+func parseAge(value string) (time.Duration, error) {
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --older-than %q: %w", value, err)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --older-than %q: %w", value, err)
+		}
+		age = d
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("invalid --older-than %q: must be positive", value)
+	}
+	return age, nil
+}
+
+func init() {
+	cachePurgeCmd.Flags().StringVar(&purgeCacheProvider, "provider", "", "Only remove entries from this provider")
+	cachePurgeCmd.Flags().StringVar(&purgeCacheOlderThan, "older-than", "", "Only remove entries older than this, e.g. 12h or 30d")
+	cacheCmd.AddCommand(cacheStatsCmd, cacheListCmd, cachePurgeCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// writeTestCache points the home directory at a temp dir and stores entries
+// there as the metadata cache.
+func writeTestCache(t *testing.T, entries []provider.CacheEntry) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	path, err := provider.DefaultCachePath()
+	if err != nil {
+		t.Fatalf("DefaultCachePath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+// testCacheEntries returns a fresh and an expired TMDB entry and an expired
+// TVDB entry.
+func testCacheEntries() []provider.CacheEntry {
+	now := time.Now()
+	meta := json.RawMessage(`{"core": {"title": "Heat"}}`)
+	return []provider.CacheEntry{
+		{Provider: "tmdb", Key: "movie|heat|1995", StoredAt: now.Add(-time.Hour), Metadata: meta},
+		{Provider: "tmdb", Key: "movie|ronin|1998", StoredAt: now.Add(-40 * 24 * time.Hour), Metadata: meta},
+		{Provider: "tvdb", Key: "show|lost|", StoredAt: now.Add(-40 * 24 * time.Hour), Metadata: meta},
+	}
+}
+
+// cachedKeys lists the keys left in the metadata cache.
+func cachedKeys(t *testing.T) []string {
+	t.Helper()
+	cache, err := openMetadataCacheForCommand()
+	if err != nil {
+		t.Fatalf("openMetadataCacheForCommand() error = %v", err)
+	}
+	keys := []string{}
+	for _, entry := range cache.Entries() {
+		keys = append(keys, entry.Key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// runCacheCommand runs a cache subcommand and returns what it printed.
+func runCacheCommand(t *testing.T, run func(*cobra.Command, []string) error) string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := run(cmd, nil); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	return out.String()
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "30d", want: 30 * 24 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "xd", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseAge(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseAge(%q) = %v, want error", tc.value, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("parseAge(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunCachePurge(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		olderThan string
+		wantOut   string
+		wantKeys  []string
+	}{
+		{
+			name:     "everything",
+			wantOut:  "Removed 3 cached entries.",
+			wantKeys: []string{},
+		},
+		{
+			name:     "provider",
+			provider: "tmdb",
+			wantOut:  "Removed 2 cached entries.",
+			wantKeys: []string{"show|lost|"},
+		},
+		{
+			name:      "older_than",
+			olderThan: "30d",
+			wantOut:   "Removed 2 cached entries.",
+			wantKeys:  []string{"movie|heat|1995"},
+		},
+		{
+			name:      "provider_and_older_than",
+			provider:  "tmdb",
+			olderThan: "30d",
+			wantOut:   "Removed 1 cached entry.",
+			wantKeys:  []string{"movie|heat|1995", "show|lost|"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			writeTestCache(t, testCacheEntries())
+			purgeCacheProvider, purgeCacheOlderThan = tc.provider, tc.olderThan
+			t.Cleanup(func() { purgeCacheProvider, purgeCacheOlderThan = "", "" })
+
+			out := runCacheCommand(t, runCachePurge)
+			if strings.TrimSpace(out) != tc.wantOut {
+				t.Errorf("output = %q, want %q", out, tc.wantOut)
+			}
+			if got := cachedKeys(t); !slices.Equal(got, tc.wantKeys) {
+				t.Errorf("cached keys = %v, want %v", got, tc.wantKeys)
+			}
+		})
+	}
+}
+
+func TestRunCachePurgeInvalidAge(t *testing.T) {
+	writeTestCache(t, testCacheEntries())
+	purgeCacheOlderThan = "soon"
+	t.Cleanup(func() { purgeCacheOlderThan = "" })
+
+	if err := runCachePurge(&cobra.Command{}, nil); err == nil {
+		t.Error("runCachePurge() error = nil, want an invalid --older-than error")
+	}
+	if got := cachedKeys(t); len(got) != 3 {
+		t.Errorf("cached keys = %v, want all 3 kept", got)
+	}
+}
+
+func TestRunCacheStats(t *testing.T) {
+	writeTestCache(t, testCacheEntries())
+
+	out := runCacheCommand(t, runCacheStats)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("stats output has %d lines, want 5:\n%s", len(lines), out)
+	}
+	if fields := strings.Fields(lines[0]); !slices.Equal(fields, []string{"PROVIDER", "ENTRIES", "EXPIRED", "OLDEST", "NEWEST"}) {
+		t.Errorf("header = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 5 || fields[0] != "tmdb" || fields[1] != "2" || fields[2] != "1" {
+		t.Errorf("tmdb row = %q, want 2 entries with 1 expired", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 5 || fields[0] != "tvdb" || fields[1] != "1" || fields[2] != "1" {
+		t.Errorf("tvdb row = %q, want 1 expired entry", lines[2])
+	}
+	if lines[4] != "3 entries in total." {
+		t.Errorf("total = %q, want 3 entries in total.", lines[4])
+	}
+}
+
+func TestRunCacheList(t *testing.T) {
+	writeTestCache(t, testCacheEntries())
+
+	out := runCacheCommand(t, runCacheList)
+	status := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		fields := strings.Fields(line)
+		status[fields[1]] = fields[len(fields)-1]
+	}
+	want := map[string]string{"movie|heat|1995": "fresh", "movie|ronin|1998": "expired", "show|lost|": "expired"}
+	if !maps.Equal(status, want) {
+		t.Errorf("list statuses = %v, want %v\n%s", status, want, out)
+	}
+}
+
+func TestCacheCommandsEmptyCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for name, run := range map[string]func(*cobra.Command, []string) error{"stats": runCacheStats, "list": runCacheList} {
+		if out := runCacheCommand(t, run); strings.TrimSpace(out) != "The metadata cache is empty." {
+			t.Errorf("%s output = %q, want the empty cache message", name, out)
+		}
+	}
+}
@@ -2,15 +2,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
 	"github.com/Digital-Shane/title-tidy/internal/log"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	providerInit "github.com/Digital-Shane/title-tidy/internal/provider/init"
+	"github.com/Digital-Shane/title-tidy/internal/provider/library"
 	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+	"github.com/Digital-Shane/title-tidy/internal/provider/plugin"
 	"github.com/Digital-Shane/title-tidy/internal/tui"
 	"github.com/Digital-Shane/title-tidy/internal/tui/theme"
 	"github.com/Digital-Shane/treeview/v2"
@@ -39,6 +44,18 @@ func RunMediaCommand(cmd *cobra.Command, cmdConfig CommandConfig) error {
 	}
 
 	log.Initialize(formatConfig.EnableLogging, formatConfig.LogRetentionDays)
+	localProvider.SetAliases(formatConfig.TitleAliases)
+
+	if offline {
+		formatConfig.Offline = true
+	}
+	if checkIntegrity {
+		formatConfig.CheckIntegrity = true
+	}
+	// Links land in the destination, so that is the library to match against
+	if linkPath != "" {
+		formatConfig.LibraryPath = linkPath
+	}
 
 	// Index files
 	t, err := indexFiles(formatConfig, cmdConfig)
@@ -46,12 +63,16 @@ func RunMediaCommand(cmd *cobra.Command, cmdConfig CommandConfig) error {
 		return err
 	}
 
-	// Fetch metadata if enabled
-	metadata := fetchMetadataIfEnabled(t, formatConfig)
+	// Fetch metadata if enabled. Lookups made while resolving matches in the
+	// preview are cached too, so the cache is written once the command ends.
+	cache := openMetadataCache(formatConfig)
+	defer flushMetadataCache(cache)
+	metadata := fetchMetadataIfEnabled(t, formatConfig, cache)
 
 	// Annotate tree with rename information
 	cmdConfig.TreeAnnotator(t, formatConfig, metadata)
-	markFilesForDeletion(t)
+	markFilesForDeletion(t, metadata)
+	flagSuspectFiles(t, formatConfig, metadata)
 
 	// Create and configure the rename model
 	model := tui.NewRenameModel(t)
@@ -136,23 +157,86 @@ func runIndexing(indexConfig tui.IndexConfig) (*treeview.Tree[treeview.FileInfo]
 	return im.Tree(), nil
 }
 
-// fetchMetadataIfEnabled fetches TMDB metadata if configured
-func fetchMetadataIfEnabled(t *treeview.Tree[treeview.FileInfo], formatConfig *config.FormatConfig) map[string]*provider.Metadata {
-	shouldFetch := false
-	if formatConfig.EnableTMDBLookup && formatConfig.TMDBAPIKey != "" {
-		shouldFetch = true
+// setupProviders registers the built-in and plugin providers and configures
+// the ones the config enables. Plugin and configuration problems leave the
+// affected provider out and are returned for display.
+func setupProviders(formatConfig *config.FormatConfig) error {
+	if err := providerInit.LoadBuiltinProviders(); err != nil {
+		return fmt.Errorf("failed to load providers: %w", err)
+	}
+
+	var errs []error
+	pluginsDir := formatConfig.PluginsDir
+	if pluginsDir == "" {
+		dir, err := plugin.DefaultDir()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		pluginsDir = dir
+	}
+	if pluginsDir != "" {
+		if err := providerInit.LoadPluginProviders(pluginsDir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to load plugins: %w", err))
+		}
+	}
+
+	errs = append(errs, formatConfig.ConfigureProviders(provider.GlobalRegistry))
+	return errors.Join(errs...)
+}
+
+// openMetadataCache opens the on-disk provider cache with the configured
+// TTLs. It returns nil when the cache is disabled or can't be read, in which
+// case every lookup goes to the providers. Offline runs read the cache even
+// when it is disabled, since it is their only source.
+func openMetadataCache(formatConfig *config.FormatConfig) *provider.DiskCache {
+	if formatConfig.DisableCache && !formatConfig.Offline {
+		return nil
+	}
+	path, err := provider.DefaultCachePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	cache, err := provider.OpenDiskCache(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	formatConfig.ApplyCacheTTLs(cache)
+	return cache
+}
+
+// flushMetadataCache writes lookups made during the run to disk.
+func flushMetadataCache(cache *provider.DiskCache) {
+	if cache == nil {
+		return
 	}
-	if formatConfig.EnableOMDBLookup && formatConfig.OMDBAPIKey != "" {
-		shouldFetch = true
+	if err := cache.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
-	if formatConfig.EnableTVDBLookup && formatConfig.TVDBAPIKey != "" {
-		shouldFetch = true
+}
+
+// attachMetadataCache makes every rate limited provider, and ffprobe, read
+// from and write to cache.
+func attachMetadataCache(reg *provider.Registry, cache *provider.DiskCache) {
+	for _, name := range reg.List() {
+		prov, _ := reg.Get(name)
+		if cached, ok := prov.(provider.DiskCacher); ok {
+			cached.SetCache(cache)
+		}
+	}
+}
+
+// fetchMetadataIfEnabled fetches provider metadata if any provider is enabled
+func fetchMetadataIfEnabled(t *treeview.Tree[treeview.FileInfo], formatConfig *config.FormatConfig, cache *provider.DiskCache) map[string]*provider.Metadata {
+	if err := setupProviders(formatConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
-	if formatConfig.EnableFFProbe {
-		shouldFetch = true
+	if cache != nil {
+		attachMetadataCache(provider.GlobalRegistry, cache)
 	}
 
-	if !shouldFetch {
+	if !core.HasMetadataSources(provider.GlobalRegistry) {
 		return nil
 	}
 
@@ -162,10 +246,14 @@ func fetchMetadataIfEnabled(t *treeview.Tree[treeview.FileInfo], formatConfig *c
 		return nil
 	}
 
-	if mm, ok := finalMetaModel.(*tui.MetadataProgressModel); ok {
-		return mm.Metadata()
+	mm, ok := finalMetaModel.(*tui.MetadataProgressModel)
+	if !ok {
+		return nil
 	}
-	return nil
+	if summary := mm.Summary(); summary.Offline && summary.Unenriched > 0 {
+		fmt.Fprintf(os.Stderr, "Offline: %d of %d items had no cached metadata\n", summary.Unenriched, summary.TotalItems)
+	}
+	return mm.Metadata()
 }
 
 // executeInstantMode runs the rename operation in non-interactive mode
@@ -224,17 +312,31 @@ func unwrapRoot(t *treeview.Tree[treeview.FileInfo]) []*treeview.Node[treeview.F
 	return ns
 }
 
-func markFilesForDeletion(t *treeview.Tree[treeview.FileInfo]) {
+// markFilesForDeletion marks the files the --no-nfo, --no-img and
+// --no-sample flags remove. Samples are told apart from titles that merely
+// contain the word by their size and, when ffprobe is enabled, duration.
+// Possible samples with nothing to compare against are flagged, not marked.
+func markFilesForDeletion(t *treeview.Tree[treeview.FileInfo], metadata map[string]*provider.Metadata) {
 	if !noNfo && !noImg && !noSample {
 		return
 	}
 
+	samples := core.SampleDetector{Probe: fileProbe(provider.GlobalRegistry), Metadata: metadata}
 	for ni := range t.All(context.Background()) {
 		name := ni.Node.Name()
 		shouldDelete := false
+		reason := ""
 
-		if noSample && local.IsSample(name) {
-			shouldDelete = true
+		if noSample {
+			sampleReason, confirmed := samples.Classify(context.Background(), ni.Node)
+			switch {
+			case confirmed:
+				reason = sampleReason
+				shouldDelete = true
+			case sampleReason != "":
+				// Too little evidence to delete; show it in the preview instead
+				core.EnsureMeta(ni.Node).Suspect = sampleReason
+			}
 		}
 
 		if !ni.Node.Data().IsDir() {
@@ -250,8 +352,76 @@ func markFilesForDeletion(t *treeview.Tree[treeview.FileInfo]) {
 		if shouldDelete {
 			meta := core.EnsureMeta(ni.Node)
 			meta.MarkedForDeletion = true
+			meta.DeletionReason = reason
+		}
+	}
+}
+
+// flagSuspectFiles runs the integrity check over the video files when it is
+// enabled. Suspect files are highlighted in the preview, and left out of the
+// rename plan when skip_suspect_files is set. Files already marked for
+// deletion aren't checked.
+func flagSuspectFiles(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatConfig, metadata map[string]*provider.Metadata) {
+	if !cfg.CheckIntegrity {
+		return
+	}
+
+	probe := fileProbe(provider.GlobalRegistry)
+	if probe == nil {
+		fmt.Fprintln(os.Stderr, "Warning: the integrity check needs ffprobe enabled; only empty files are flagged")
+	}
+	checker := core.IntegrityChecker{Probe: probe, Metadata: metadata}
+	for ni := range t.All(context.Background()) {
+		if meta := core.GetMeta(ni.Node); meta != nil && meta.MarkedForDeletion {
+			continue
 		}
+		reason := checker.Check(context.Background(), ni.Node)
+		if reason == "" {
+			continue
+		}
+		core.EnsureMeta(ni.Node).Suspect = reason
+		if instant {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", ni.Node.Data().Path, reason)
+		}
+		if cfg.SkipSuspectFiles {
+			skipRename(ni.Node)
+		}
+	}
+}
+
+// skipRename leaves node out of the rename plan. A virtual folder created to
+// hold the file is dropped along with it, so nothing in it is moved.
+func skipRename(node *treeview.Node[treeview.FileInfo]) {
+	meta := core.EnsureMeta(node)
+	meta.NewName = ""
+	meta.DestinationPath = ""
+	if parent := node.Parent(); parent != nil {
+		if pm := core.GetMeta(parent); pm != nil && pm.IsVirtual {
+			pm.NewName = ""
+			pm.DestinationPath = ""
+			pm.NeedsDirectory = false
+		}
+	}
+}
+
+// libraryFolder returns the existing library folder meta was matched to, or
+// "" when the show or movie is new to the library.
+func libraryFolder(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	folder, _ := meta.Extended[library.FolderKey].(string)
+	return folder
+}
+
+// fileProbe returns the ffprobe provider when it is enabled, or nil. Other
+// providers that read files, such as hash lookups, don't report durations.
+func fileProbe(reg *provider.Registry) provider.Provider {
+	if !slices.Contains(reg.Enabled(), "ffprobe") {
+		return nil
 	}
+	prov, _ := reg.Get("ffprobe")
+	return prov
 }
 
 func createFormatContext(cfg *config.FormatConfig, showName, movieName string, year string, season, episode int, metadata *provider.Metadata) *config.FormatContext {
@@ -217,6 +217,7 @@ func TestMarkFilesForDeletion(t *testing.T) {
 		deleteSamples bool
 		filename      string
 		wantDeleted   bool
+		wantSuspect   bool
 	}{
 		{
 			name:         "delete_nfo_file",
@@ -240,13 +241,26 @@ func TestMarkFilesForDeletion(t *testing.T) {
 			wantDeleted:  true,
 		},
 		{
-			name:          "delete_sample_file",
+			name:          "flag_lone_sample_file",
 			deleteNFO:     false,
 			deleteImages:  false,
 			deleteSamples: true,
 			filename:      "sample.mp4",
+			wantDeleted:   false,
+			wantSuspect:   true,
+		},
+		{
+			name:          "delete_sample_folder",
+			deleteSamples: true,
+			filename:      "Sample",
 			wantDeleted:   true,
 		},
+		{
+			name:          "keep_title_containing_sample",
+			deleteSamples: true,
+			filename:      "Free Samples (2012).mp4",
+			wantDeleted:   false,
+		},
 		{
 			name:         "keep_video_file",
 			deleteNFO:    true,
@@ -265,7 +279,7 @@ func TestMarkFilesForDeletion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			isDir := tt.filename == "Season 01"
+			isDir := tt.filename == "Season 01" || tt.filename == "Sample"
 			node := treeview.NewNode("test", tt.filename, treeview.FileInfo{
 				FileInfo: core.NewSimpleFileInfo(tt.filename, isDir),
 				Extra:    make(map[string]any),
@@ -276,7 +290,7 @@ func TestMarkFilesForDeletion(t *testing.T) {
 			noImg = tt.deleteImages
 			noSample = tt.deleteSamples
 
-			markFilesForDeletion(tree)
+			markFilesForDeletion(tree, nil)
 
 			meta := core.GetMeta(node)
 			gotDeleted := meta != nil && meta.MarkedForDeletion
@@ -285,6 +299,53 @@ func TestMarkFilesForDeletion(t *testing.T) {
 				t.Errorf("markFilesForDeletion() for %s: MarkedForDeletion = %v, want %v",
 					tt.filename, gotDeleted, tt.wantDeleted)
 			}
+			if gotSuspect := meta != nil && meta.Suspect != ""; gotSuspect != tt.wantSuspect {
+				t.Errorf("markFilesForDeletion() for %s: flagged = %v, want %v", tt.filename, gotSuspect, tt.wantSuspect)
+			}
+		})
+	}
+}
+
+func TestFlagSuspectFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		skip     bool
+		wantName string
+	}{
+		{name: "highlight", skip: false, wantName: "Heat (1995).mkv"},
+		{name: "skip", skip: true, wantName: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An empty file is suspect without ffprobe
+			file := treeview.NewNode("heat.mkv", "heat.mkv", treeview.FileInfo{
+				FileInfo: core.NewSimpleFileInfo("heat.mkv", false),
+				Path:     "/library/heat.mkv",
+			})
+			core.EnsureMeta(file).NewName = "Heat (1995).mkv"
+			folder := treeview.NewNode("Heat (1995)", "Heat (1995)", treeview.FileInfo{
+				FileInfo: core.NewSimpleFileInfo("Heat (1995)", true),
+			})
+			folder.AddChild(file)
+			folderMeta := core.EnsureMeta(folder)
+			folderMeta.NewName = "Heat (1995)"
+			folderMeta.IsVirtual = true
+			folderMeta.NeedsDirectory = true
+			tree := treeview.NewTree([]*treeview.Node[treeview.FileInfo]{folder})
+
+			flagSuspectFiles(tree, &config.FormatConfig{CheckIntegrity: true, SkipSuspectFiles: tt.skip}, nil)
+
+			meta := core.GetMeta(file)
+			if meta.Suspect != "suspect: empty file" {
+				t.Errorf("Suspect = %q, want %q", meta.Suspect, "suspect: empty file")
+			}
+			if meta.NewName != tt.wantName {
+				t.Errorf("NewName = %q, want %q", meta.NewName, tt.wantName)
+			}
+			if folderMeta.NeedsDirectory == tt.skip {
+				t.Errorf("virtual folder NeedsDirectory = %v, want %v", folderMeta.NeedsDirectory, !tt.skip)
+			}
 		})
 	}
 }
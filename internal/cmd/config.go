@@ -6,7 +6,6 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
-	providerInit "github.com/Digital-Shane/title-tidy/internal/provider/init"
 	"github.com/Digital-Shane/title-tidy/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -22,53 +21,14 @@ and movie naming templates, as well as TMDB API settings and other options.`,
 }
 
 func runConfigCommand(cmd *cobra.Command, args []string) error {
-	// Initialize providers
-	if err := providerInit.LoadBuiltinProviders(); err != nil {
-		return fmt.Errorf("failed to load providers: %w", err)
-	}
-
-	// Load config to get TMDB settings
+	// Load config to get provider settings
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Configure TMDB if enabled and configured
-	if cfg.EnableTMDBLookup && cfg.TMDBAPIKey != "" {
-		tmdbConfig := map[string]interface{}{
-			"api_key":       cfg.TMDBAPIKey,
-			"language":      cfg.TMDBLanguage,
-			"cache_enabled": true,
-		}
-		if err := provider.GlobalRegistry.Configure("tmdb", tmdbConfig); err == nil {
-			// Only enable if configuration succeeded
-			provider.GlobalRegistry.Enable("tmdb")
-		}
-	}
-
-	// Configure OMDb if enabled and configured
-	if cfg.EnableOMDBLookup && cfg.OMDBAPIKey != "" {
-		omdbConfig := map[string]interface{}{
-			"api_key": cfg.OMDBAPIKey,
-		}
-		if err := provider.GlobalRegistry.Configure("omdb", omdbConfig); err == nil {
-			provider.GlobalRegistry.Enable("omdb")
-		}
-	}
-
-	if cfg.EnableTVDBLookup && cfg.TVDBAPIKey != "" {
-		tvdbConfig := map[string]interface{}{
-			"api_key": cfg.TVDBAPIKey,
-		}
-		if err := provider.GlobalRegistry.Configure("tvdb", tvdbConfig); err == nil {
-			provider.GlobalRegistry.Enable("tvdb")
-		}
-	}
-
-	if cfg.EnableFFProbe {
-		if err := provider.GlobalRegistry.Enable("ffprobe"); err != nil {
-			fmt.Fprintf(cmd.OutOrStdout(), "Warning: failed to enable ffprobe provider: %v\n", err)
-		}
+	if err := setupProviders(cfg); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "Warning: %v\n", err)
 	}
 
 	// Create template registry
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
@@ -217,12 +218,16 @@ func annotateMoviesTree(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatC
 					meta = metadata[key]
 				}
 
-				ctx := createFormatContext(cfg, "", movieName, year, 0, 0, meta)
-				generated := cfg.ApplyMovieTemplate(ctx)
-				m.NewName = core.PreserveExistingBracketTags(generated, ni.Node.Name(), cfg.PreserveExistingTags)
+				if folder := libraryFolder(meta); folder != "" {
+					m.NewName = folder
+				} else {
+					ctx := createFormatContext(cfg, "", movieName, year, 0, 0, meta)
+					generated := cfg.ApplyMovieTemplate(ctx)
+					m.NewName = core.PreserveExistingBracketTags(generated, ni.Node.Name(), cfg.PreserveExistingTags)
+				}
 
 				if linkPath != "" {
-					m.DestinationPath = linkPath
+					m.DestinationPath = filepath.Join(movieLinkDir(cfg, meta), m.NewName)
 				}
 			} else {
 				if local.IsVideo(ni.Node.Name()) {
@@ -298,8 +303,8 @@ func annotateMoviesTree(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatC
 				}
 
 				if linkPath != "" {
-					if parentMeta := core.GetMeta(ni.Node.Parent()); parentMeta != nil && parentMeta.NewName != "" {
-						m.DestinationPath = filepath.Join(linkPath, parentMeta.NewName)
+					if parentMeta := core.GetMeta(ni.Node.Parent()); parentMeta != nil && parentMeta.DestinationPath != "" {
+						m.DestinationPath = filepath.Join(parentMeta.DestinationPath, m.NewName)
 					}
 				}
 			} else if local.IsSubtitle(ni.Node.Name()) {
@@ -325,8 +330,8 @@ func annotateMoviesTree(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatC
 				}
 
 				if linkPath != "" {
-					if parentMeta := core.GetMeta(ni.Node.Parent()); parentMeta != nil && parentMeta.NewName != "" {
-						m.DestinationPath = filepath.Join(linkPath, parentMeta.NewName)
+					if parentMeta := core.GetMeta(ni.Node.Parent()); parentMeta != nil && parentMeta.DestinationPath != "" {
+						m.DestinationPath = filepath.Join(parentMeta.DestinationPath, m.NewName)
 					}
 				}
 			}
@@ -334,6 +339,21 @@ func annotateMoviesTree(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatC
 	}
 }
 
+// movieLinkDir returns the folder a movie folder is linked into. With
+// group_collections set, movies that belong to a collection go in a folder
+// named after it, unless they matched a folder already in the library.
+func movieLinkDir(cfg *config.FormatConfig, meta *provider.Metadata) string {
+	if !cfg.GroupCollections || meta == nil || libraryFolder(meta) != "" {
+		return linkPath
+	}
+	collection, _ := meta.Extended["collection"].(string)
+	collection = strings.TrimSpace(strings.ReplaceAll(collection, "/", "-"))
+	if collection == "" {
+		return linkPath
+	}
+	return filepath.Join(linkPath, collection)
+}
+
 var (
 	noDir bool
 )
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -9,6 +10,7 @@ import (
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/library"
 	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/Digital-Shane/title-tidy/internal/tui"
 	"github.com/Digital-Shane/treeview/v2"
@@ -500,3 +502,70 @@ func TestMoviePreprocessVirtualDirectoryIDsUnique(t *testing.T) {
 		t.Errorf("moviePreprocess virtual dir IDs = %q and %q, want unique IDs", processed[0].ID(), processed[1].ID())
 	}
 }
+
+func TestAnnotateMoviesTreeGroupsCollections(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.GroupCollections = true
+	linkPath = "/movies"
+	t.Cleanup(func() { linkPath = "" })
+
+	newNode := func(name string, isDir bool) *treeview.Node[treeview.FileInfo] {
+		return treeview.NewNode(name, name, treeview.FileInfo{
+			FileInfo: core.NewSimpleFileInfo(name, isDir),
+			Path:     name,
+			Extra:    map[string]any{},
+		})
+	}
+
+	matrixDir := newNode("The.Matrix.1999", true)
+	matrixVideo := newNode("The.Matrix.1999.1080p.mkv", false)
+	matrixDir.AddChild(matrixVideo)
+	reloaded := newNode("The.Matrix.Reloaded.2003.mkv", false)
+	heatDir := newNode("Heat.1995", true)
+	heatVideo := newNode("Heat.1995.mkv", false)
+	heatDir.AddChild(heatVideo)
+	aliensDir := newNode("Aliens.1986", true)
+
+	collection := func(title, year string) *provider.Metadata {
+		return &provider.Metadata{
+			Core:     provider.CoreMetadata{Title: title, Year: year, MediaType: provider.MediaTypeMovie},
+			Extended: map[string]interface{}{"collection": "The Matrix Collection", "collection_id": "2344"},
+		}
+	}
+	metadata := map[string]*provider.Metadata{
+		provider.GenerateMetadataKey("movie", "The Matrix", "1999", 0, 0):          collection("The Matrix", "1999"),
+		provider.GenerateMetadataKey("movie", "The Matrix Reloaded", "2003", 0, 0): collection("The Matrix Reloaded", "2003"),
+		provider.GenerateMetadataKey("movie", "Heat", "1995", 0, 0): {
+			Core: provider.CoreMetadata{Title: "Heat", Year: "1995", MediaType: provider.MediaTypeMovie},
+		},
+		// Already in the library, so it stays in its existing folder
+		provider.GenerateMetadataKey("movie", "Aliens", "1986", 0, 0): {
+			Core: provider.CoreMetadata{Title: "Aliens", Year: "1986", MediaType: provider.MediaTypeMovie},
+			Extended: map[string]interface{}{
+				"collection":      "Alien Collection",
+				library.FolderKey: "Aliens (1986)",
+			},
+		},
+	}
+
+	nodes := moviePreprocess([]*treeview.Node[treeview.FileInfo]{matrixDir, reloaded, heatDir, aliensDir}, cfg, false)
+	tree := treeview.NewTree(nodes)
+	annotateMoviesTree(tree, cfg, metadata)
+
+	got := make(map[string]string)
+	for ni := range tree.All(context.Background()) {
+		got[ni.Node.Name()] = core.GetMeta(ni.Node).DestinationPath
+	}
+	want := map[string]string{
+		"The.Matrix.1999":              filepath.Join("/movies", "The Matrix Collection", "The Matrix (1999)"),
+		"The.Matrix.1999.1080p.mkv":    filepath.Join("/movies", "The Matrix Collection", "The Matrix (1999)", "The Matrix (1999).mkv"),
+		"The.Matrix.Reloaded.2003":     filepath.Join("/movies", "The Matrix Collection", "The Matrix Reloaded (2003)"),
+		"The.Matrix.Reloaded.2003.mkv": filepath.Join("/movies", "The Matrix Collection", "The Matrix Reloaded (2003)", "The Matrix Reloaded (2003).mkv"),
+		"Heat.1995":                    filepath.Join("/movies", "Heat (1995)"),
+		"Heat.1995.mkv":                filepath.Join("/movies", "Heat (1995)", "Heat (1995).mkv"),
+		"Aliens.1986":                  filepath.Join("/movies", "Aliens (1986)"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("destinations mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Digital-Shane/title-tidy/internal/overrides"
+	"github.com/spf13/cobra"
+)
+
+var clearAllOverrides bool
+
+var overridesCmd = &cobra.Command{
+	Use:   "overrides",
+	Short: "Manage remembered manual metadata matches",
+	Long: `List or clear the provider IDs remembered from manual metadata resolution.
+
+When a metadata lookup is resolved by hand, the chosen provider IDs are stored
+and reused automatically for the same show or movie on later runs.`,
+}
+
+var overridesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remembered matches",
+	Args:  cobra.NoArgs,
+	RunE:  runOverridesList,
+}
+
+var overridesClearCmd = &cobra.Command{
+	Use:   "clear [name]",
+	Short: "Forget remembered matches",
+	Long: `Forget the remembered match for a show or movie name, or every match with --all.
+
+Names are compared after normalization, so "The Office" also clears "Office, The".
+Folder .title-tidy-id files are not touched; delete them to drop those IDs.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOverridesClear,
+}
+
+func openOverrideStore() (*overrides.Store, error) {
+	path, err := overrides.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := overrides.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load overrides: %w", err)
+	}
+	return store, nil
+}
+
+func runOverridesList(cmd *cobra.Command, args []string) error {
+	store, err := openOverrideStore()
+	if err != nil {
+		return err
+	}
+	writeOverrides(cmd.OutOrStdout(), store.Entries())
+	return nil
+}
+
+func writeOverrides(out io.Writer, entries []overrides.Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No remembered matches.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tYEAR\tIDS\tUPDATED")
+	for _, entry := range entries {
+		providers := make([]string, 0, len(entry.IDs))
+		for name := range entry.IDs {
+			providers = append(providers, name)
+		}
+		sort.Strings(providers)
+
+		ids := make([]string, 0, len(providers))
+		for _, name := range providers {
+			ids = append(ids, name+"="+entry.IDs[name])
+		}
+
+		year := entry.Year
+		if year == "" {
+			year = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.MediaType, entry.Name, year, strings.Join(ids, " "), entry.UpdatedAt.Format("2006-01-02"))
+	}
+	w.Flush()
+}
+
+func runOverridesClear(cmd *cobra.Command, args []string) error {
+	if clearAllOverrides == (len(args) == 1) {
+		return fmt.Errorf("specify a name or --all")
+	}
+
+	store, err := openOverrideStore()
+	if err != nil {
+		return err
+	}
+
+	var removed int
+	if clearAllOverrides {
+		removed, err = store.Clear()
+	} else {
+		removed, err = store.Remove(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch {
+	case removed == 0 && !clearAllOverrides:
+		fmt.Fprintf(out, "No remembered match for %q.\n", args[0])
+	case removed == 1:
+		fmt.Fprintln(out, "Cleared 1 remembered match.")
+	default:
+		fmt.Fprintf(out, "Cleared %d remembered matches.\n", removed)
+	}
+	return nil
+}
+
+func init() {
+	overridesClearCmd.Flags().BoolVar(&clearAllOverrides, "all", false, "Forget every remembered match")
+	overridesCmd.AddCommand(overridesListCmd, overridesClearCmd)
+	rootCmd.AddCommand(overridesCmd)
+}
@@ -35,11 +35,13 @@ func Execute() {
 }
 
 var (
-	instant  bool
-	noNfo    bool
-	noImg    bool
-	noSample bool
-	linkPath string
+	instant        bool
+	noNfo          bool
+	noImg          bool
+	noSample       bool
+	linkPath       string
+	offline        bool
+	checkIntegrity bool
 )
 
 func init() {
@@ -49,4 +51,6 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&noImg, "no-img", false, "Delete image files during rename")
 	rootCmd.PersistentFlags().BoolVar(&noSample, "no-sample", false, "Delete sample media files and folders during rename")
 	rootCmd.PersistentFlags().StringVar(&linkPath, "link", "", "Create hard links in destination instead of renaming in place")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Use cached metadata only and make no network requests")
+	rootCmd.PersistentFlags().BoolVar(&checkIntegrity, "check-integrity", false, "Flag truncated or corrupt video files before renaming")
 }
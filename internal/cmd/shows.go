@@ -69,8 +69,13 @@ func annotateShowsTree(t *treeview.Tree[treeview.FileInfo], cfg *config.FormatCo
 			}
 
 			ctx := createFormatContext(cfg, showMeta.Core.Title, "", showMeta.Core.Year, 0, 0, meta)
-			generated := cfg.ApplyShowFolderTemplate(ctx)
-			m.NewName = core.PreserveExistingBracketTags(generated, ni.Node.Name(), cfg.PreserveExistingTags)
+			if folder := libraryFolder(meta); folder != "" {
+				// Join the show's existing folder instead of creating a twin
+				m.NewName = folder
+			} else {
+				generated := cfg.ApplyShowFolderTemplate(ctx)
+				m.NewName = core.PreserveExistingBracketTags(generated, ni.Node.Name(), cfg.PreserveExistingTags)
+			}
 
 			if meta != nil {
 				showMetadata[ni.Node] = meta
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/library"
 	"github.com/Digital-Shane/treeview/v2"
 )
 
@@ -67,3 +70,34 @@ func TestAnnotateShowsTreeIgnoresEpisodeBracketHash(t *testing.T) {
 		t.Errorf("episode rename = %q, want %q", episodeMeta.NewName, "S01E17.mkv")
 	}
 }
+
+func TestAnnotateShowsTreeJoinsLibraryFolder(t *testing.T) {
+	cfg := config.DefaultConfig()
+	linkPath = "/library"
+	t.Cleanup(func() { linkPath = "" })
+
+	show := newShowsTestNode("The.Office", true, "The.Office")
+	season := newShowsTestNode("Season 1", true, "The.Office/Season 1")
+	episode := newShowsTestNode("The.Office.S01E01.mkv", false, "The.Office/Season 1/The.Office.S01E01.mkv")
+
+	season.SetChildren([]*treeview.Node[treeview.FileInfo]{episode})
+	show.SetChildren([]*treeview.Node[treeview.FileInfo]{season})
+
+	metadata := map[string]*provider.Metadata{
+		provider.GenerateMetadataKey("show", "The Office", "", 0, 0): {
+			Core:     provider.CoreMetadata{Title: "The Office (US)", Year: "2005"},
+			Extended: map[string]interface{}{library.FolderKey: "The Office (US) (2005)"},
+		},
+	}
+
+	tree := treeview.NewTree([]*treeview.Node[treeview.FileInfo]{show})
+	annotateShowsTree(tree, cfg, metadata)
+
+	if got := core.GetMeta(show).NewName; got != "The Office (US) (2005)" {
+		t.Errorf("show rename = %q, want the library folder", got)
+	}
+	want := filepath.Join("/library", "The Office (US) (2005)", "Season 01", "S01E01.mkv")
+	if got := core.GetMeta(episode).DestinationPath; got != want {
+		t.Errorf("episode destination = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+)
+
+// AddTitleAlias adds alias to the saved configuration, replacing any alias
+// for the same name and path. The file is reloaded first so settings only
+// made for the current run, such as --offline, aren't saved with it.
+func AddTitleAlias(alias local.Alias) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+
+	alias.Name = strings.TrimSpace(alias.Name)
+	name := provider.NormalizeTitle(alias.Name)
+	for i, existing := range cfg.TitleAliases {
+		if provider.NormalizeTitle(existing.Name) == name && existing.Path == alias.Path {
+			cfg.TitleAliases[i] = alias
+			return cfg.Save()
+		}
+	}
+	cfg.TitleAliases = append(cfg.TitleAliases, alias)
+	return cfg.Save()
+}
@@ -2,11 +2,14 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
 	ffprobeProv "github.com/Digital-Shane/title-tidy/internal/provider/ffprobe"
@@ -59,10 +62,119 @@ type FormatConfig struct {
 	EnableTVDBLookup bool   `json:"enable_tvdb_lookup"`
 	EnableFFProbe    bool   `json:"enable_ffprobe"`
 
+	// FFProbePath is the ffprobe executable, "ffprobe" on the PATH when
+	// empty. FFProbeWorkers limits how many files are probed at once, and
+	// FFProbeTimeoutSeconds how long one probe may take.
+	FFProbePath           string `json:"ffprobe_path,omitempty"`
+	FFProbeWorkers        int    `json:"ffprobe_workers,omitempty"`
+	FFProbeTimeoutSeconds int    `json:"ffprobe_timeout_seconds,omitempty"`
+
+	// CheckIntegrity flags video files that look truncated or corrupt in the
+	// preview. The --check-integrity flag sets it for a single run.
+	// SkipSuspectFiles also leaves flagged files out of the rename plan.
+	CheckIntegrity   bool `json:"check_integrity,omitempty"`
+	SkipSuspectFiles bool `json:"skip_suspect_files,omitempty"`
+
+	// LibraryPath is a library whose existing show and movie folders new
+	// items are matched to first. Runs with --link use the link destination
+	// instead. DisableLibraryMatch turns the matching off.
+	LibraryPath         string `json:"library_path,omitempty"`
+	DisableLibraryMatch bool   `json:"disable_library_match,omitempty"`
+
+	// GroupCollections links movies that belong to a collection into a
+	// folder named after the collection, such as "The Matrix Collection".
+	// Standalone movies stay at the top of the link destination.
+	GroupCollections bool `json:"group_collections,omitempty"`
+
+	// TitleAliases maps titles the parser always gets wrong to a canonical
+	// title, year or provider IDs. They are applied to parsed names before
+	// any provider lookup.
+	TitleAliases []local.Alias `json:"title_aliases,omitempty"`
+
+	// CertificationCountry is the ISO 3166-1 country whose content rating
+	// fills {certification}, such as "US" or "GB". When empty, the region of
+	// TMDBLanguage is used.
+	CertificationCountry string `json:"certification_country,omitempty"`
+
+	// TitleLanguages orders the languages used to pick {title}. The special
+	// entry "original" selects the original-language title.
+	TitleLanguages []string `json:"title_languages,omitempty"`
+
+	// EpisodeOrder is the order TMDB and TVDB number episodes in: aired
+	// (the default), dvd, absolute or story. EpisodeOrders overrides it for
+	// individual shows, keyed by show title.
+	EpisodeOrder  string            `json:"episode_order,omitempty"`
+	EpisodeOrders map[string]string `json:"episode_orders,omitempty"`
+
+	// SearchStrategies lists the fallback queries tried, in order, when a
+	// provider search finds nothing. An empty list disables fallbacks.
+	SearchStrategies []string `json:"search_strategies"`
+
+	// MatchThreshold is the minimum confidence (0-1) a provider match needs
+	// to be applied automatically. Weaker matches are queued for review, and
+	// zero accepts every match.
+	MatchThreshold float64 `json:"match_threshold"`
+
+	// WriteIDFiles saves the provider IDs picked during manual resolution to
+	// a .title-tidy-id file in the show or movie folder, in addition to the
+	// override store.
+	WriteIDFiles bool `json:"write_id_files"`
+
+	// FieldPrecedence picks, per metadata field, the providers whose value
+	// wins when several supply it, e.g. {"rating": ["omdb"]}. Fields not
+	// listed follow provider priority.
+	FieldPrecedence map[string][]string `json:"field_precedence,omitempty"`
+
+	// PluginsDir holds external provider executables loaded at startup.
+	// Empty means ~/.title-tidy/plugins.
+	PluginsDir string `json:"plugins_dir,omitempty"`
+
+	// ProviderSettings configures registered providers that have no
+	// dedicated fields above, keyed by registry name. Each map is passed to
+	// the provider's Configure, and "enabled": true turns the provider on.
+	ProviderSettings map[string]map[string]interface{} `json:"provider_settings,omitempty"`
+
+	// RateLimits overrides the request pacing and retry policy of providers,
+	// keyed by registry name.
+	RateLimits map[string]RateLimitConfig `json:"rate_limits,omitempty"`
+
+	// DisableCache turns off the on-disk cache of provider responses.
+	DisableCache bool `json:"disable_cache,omitempty"`
+
+	// Offline answers metadata lookups from the cache only, without network
+	// requests. The --offline flag sets it for a single run.
+	Offline bool `json:"offline,omitempty"`
+
+	// CacheTTLHours sets how many hours cached responses stay fresh, keyed
+	// by registry name. Providers without an entry use seven days.
+	CacheTTLHours map[string]int `json:"cache_ttl_hours,omitempty"`
+
 	// Template resolver for dynamic variable resolution
 	resolver *TemplateResolver
 }
 
+// RateLimitConfig overrides part of a provider's rate limit and retry policy.
+// Zero or missing fields keep the provider's default.
+type RateLimitConfig struct {
+	Requests      int  `json:"requests,omitempty"`       // Lookups allowed per window
+	WindowSeconds int  `json:"window_seconds,omitempty"` // Length of the window
+	MaxRetries    *int `json:"max_retries,omitempty"`    // Retries for temporary errors; 0 disables them
+}
+
+// Apply returns policy with the configured fields replaced.
+func (rl RateLimitConfig) Apply(policy provider.Policy) provider.Policy {
+	if rl.Requests > 0 {
+		policy.Requests = rl.Requests
+	}
+	if rl.WindowSeconds > 0 {
+		policy.Window = time.Duration(rl.WindowSeconds) * time.Second
+	}
+	if rl.MaxRetries != nil && *rl.MaxRetries >= 0 {
+		policy.MaxRetries = *rl.MaxRetries
+	}
+	return policy
+}
+
 // DefaultConfig returns the default format configuration
 func DefaultConfig() *FormatConfig {
 	return &FormatConfig{
@@ -81,10 +193,20 @@ func DefaultConfig() *FormatConfig {
 		EnableOMDBLookup:     false,
 		TVDBAPIKey:           "",
 		EnableTVDBLookup:     false,
+		SearchStrategies:     defaultSearchStrategies(),
+		MatchThreshold:       provider.DefaultMatchThreshold,
 		resolver:             NewTemplateResolver(),
 	}
 }
 
+func defaultSearchStrategies() []string {
+	names := make([]string, 0, len(provider.DefaultSearchStrategies))
+	for _, strategy := range provider.DefaultSearchStrategies {
+		names = append(names, string(strategy))
+	}
+	return names
+}
+
 // ConfigPath returns the path to the config file
 func ConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -114,6 +236,11 @@ func Load() (*FormatConfig, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	// Zero is a valid threshold, so only a missing key takes the default
+	var set struct {
+		MatchThreshold *float64 `json:"match_threshold"`
+	}
+	_ = json.Unmarshal(data, &set)
 
 	// Fill in any missing fields with defaults
 	defaults := DefaultConfig()
@@ -140,6 +267,13 @@ func Load() (*FormatConfig, error) {
 	if cfg.TMDBWorkerCount == 0 {
 		cfg.TMDBWorkerCount = defaults.TMDBWorkerCount
 	}
+	// A missing key uses the defaults, while an explicit empty list disables fallbacks
+	if cfg.SearchStrategies == nil {
+		cfg.SearchStrategies = defaults.SearchStrategies
+	}
+	if set.MatchThreshold == nil {
+		cfg.MatchThreshold = defaults.MatchThreshold
+	}
 
 	// Initialize the template resolver
 	cfg.resolver = NewTemplateResolver()
@@ -149,13 +283,14 @@ func Load() (*FormatConfig, error) {
 
 // NeedsMetadata checks if any template uses variables that would benefit from metadata
 func (cfg *FormatConfig) NeedsMetadata() bool {
-	metadataVars := metadataVariableNames()
-	if len(metadataVars) == 0 {
-		return false
-	}
+	return cfg.UsesVariable(metadataVariableNames()...)
+}
 
+// UsesVariable reports whether any template uses one of the named
+// variables. Providers use it to skip fetching fields no template needs.
+func (cfg *FormatConfig) UsesVariable(names ...string) bool {
 	allTemplates := cfg.ShowFolder + cfg.SeasonFolder + cfg.Episode + cfg.Movie
-	for _, name := range metadataVars {
+	for _, name := range names {
 		placeholder := "{" + name + "}"
 		if strings.Contains(allTemplates, placeholder) {
 			return true
@@ -164,6 +299,36 @@ func (cfg *FormatConfig) NeedsMetadata() bool {
 	return false
 }
 
+// certificationCountry returns the country content ratings are read for, or
+// "" when no template uses {certification}.
+func (cfg *FormatConfig) certificationCountry() string {
+	if !cfg.UsesVariable("certification") {
+		return ""
+	}
+	if country := strings.TrimSpace(cfg.CertificationCountry); country != "" {
+		return strings.ToUpper(country)
+	}
+	if _, region, ok := strings.Cut(cfg.TMDBLanguage, "-"); ok && region != "" {
+		return strings.ToUpper(region)
+	}
+	return "US"
+}
+
+// EpisodeVariables lists the variables the episode template uses.
+func (cfg *FormatConfig) EpisodeVariables() []string {
+	resolver := cfg.resolver
+	if resolver == nil {
+		resolver = NewTemplateResolver()
+	}
+	names := []string{}
+	for _, match := range resolver.variablePattern.FindAllStringSubmatch(escapeTemplateBraces(cfg.Episode), -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
 var (
 	metadataVarOnce  sync.Once
 	metadataVarCache []string
@@ -214,6 +379,97 @@ func gatherMetadataProviders() []provider.Provider {
 	}
 }
 
+// ProviderConfig returns the settings passed to the named provider and
+// whether it should be enabled. Built-in providers read their dedicated
+// fields; any other provider reads its ProviderSettings entry.
+func (cfg *FormatConfig) ProviderConfig(name string) (map[string]interface{}, bool) {
+	switch name {
+	case "tmdb":
+		language := cfg.TMDBLanguage
+		if language == "" {
+			language = "en-US"
+		}
+		return map[string]interface{}{
+			"api_key":               cfg.TMDBAPIKey,
+			"language":              language,
+			"language_priority":     cfg.TitleLanguages,
+			"episode_order":         cfg.EpisodeOrder,
+			"episode_orders":        cfg.EpisodeOrders,
+			"credits":               cfg.UsesVariable("director", "cast"),
+			"certification_country": cfg.certificationCountry(),
+		}, cfg.EnableTMDBLookup && cfg.TMDBAPIKey != ""
+	case "tvdb":
+		return map[string]interface{}{
+			"api_key":               cfg.TVDBAPIKey,
+			"language_priority":     cfg.TitleLanguages,
+			"episode_order":         cfg.EpisodeOrder,
+			"episode_orders":        cfg.EpisodeOrders,
+			"certification_country": cfg.certificationCountry(),
+		}, cfg.EnableTVDBLookup && cfg.TVDBAPIKey != ""
+	case "omdb":
+		return map[string]interface{}{
+			"api_key": cfg.OMDBAPIKey,
+		}, cfg.EnableOMDBLookup && cfg.OMDBAPIKey != ""
+	case "ffprobe":
+		return map[string]interface{}{
+			"binary_path":     cfg.FFProbePath,
+			"timeout_seconds": cfg.FFProbeTimeoutSeconds,
+		}, cfg.EnableFFProbe
+	case "library":
+		return map[string]interface{}{
+			"path": cfg.LibraryPath,
+		}, cfg.LibraryPath != "" && !cfg.DisableLibraryMatch
+	}
+
+	settings := make(map[string]interface{}, len(cfg.ProviderSettings[name]))
+	enabled := false
+	for key, value := range cfg.ProviderSettings[name] {
+		if key == "enabled" {
+			enabled, _ = value.(bool)
+			continue
+		}
+		settings[key] = value
+	}
+	return settings, enabled
+}
+
+// ConfigureProviders configures and enables every provider in reg that the
+// configuration turns on, and applies any RateLimits entry to providers
+// registered with a retry policy. A provider that fails to configure stays
+// disabled and its error is returned alongside the others.
+func (cfg *FormatConfig) ConfigureProviders(reg *provider.Registry) error {
+	var errs []error
+	for _, name := range reg.List() {
+		if limits, ok := cfg.RateLimits[name]; ok {
+			if prov, found := reg.Get(name); found {
+				if resilient, isResilient := prov.(*provider.Resilient); isResilient {
+					resilient.SetPolicy(limits.Apply(resilient.Policy()))
+				}
+			}
+		}
+
+		settings, enabled := cfg.ProviderConfig(name)
+		if !enabled {
+			continue
+		}
+		if err := reg.Configure(name, settings); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := reg.Enable(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyCacheTTLs sets the CacheTTLHours entries on cache.
+func (cfg *FormatConfig) ApplyCacheTTLs(cache *provider.DiskCache) {
+	for name, hours := range cfg.CacheTTLHours {
+		cache.SetTTL(name, time.Duration(hours)*time.Hour)
+	}
+}
+
 // Save writes the configuration to disk
 func (cfg *FormatConfig) Save() error {
 	path, err := ConfigPath()
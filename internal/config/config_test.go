@@ -1,12 +1,15 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -37,6 +40,8 @@ func TestDefaultConfig(t *testing.T) {
 		EnableOMDBLookup:     false,
 		TVDBAPIKey:           "",
 		EnableTVDBLookup:     false,
+		SearchStrategies:     defaultSearchStrategies(),
+		MatchThreshold:       provider.DefaultMatchThreshold,
 	}
 
 	if diff := cmp.Diff(want, cfg, cmpOpts()); diff != "" {
@@ -140,6 +145,8 @@ func TestLoad_ValidFile(t *testing.T) {
 		EnableOMDBLookup:     false,
 		TVDBAPIKey:           "",
 		EnableTVDBLookup:     false,
+		SearchStrategies:     defaultSearchStrategies(),
+		MatchThreshold:       provider.DefaultMatchThreshold,
 	}
 
 	if diff := cmp.Diff(want, cfg, cmpOpts()); diff != "" {
@@ -194,6 +201,30 @@ func TestLoad_PartialConfig(t *testing.T) {
 	if cfg.LogRetentionDays != 60 {
 		t.Errorf("Load() LogRetentionDays = %d, want %d", cfg.LogRetentionDays, 60)
 	}
+	if cfg.MatchThreshold != provider.DefaultMatchThreshold {
+		t.Errorf("Load() MatchThreshold = %v, want default %v", cfg.MatchThreshold, provider.DefaultMatchThreshold)
+	}
+}
+
+func TestLoad_ZeroMatchThreshold(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	configDir := filepath.Join(tempDir, ".title-tidy")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"match_threshold": 0}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want nil", err)
+	}
+	if cfg.MatchThreshold != 0 {
+		t.Errorf("Load() MatchThreshold = %v, want 0 to accept every match", cfg.MatchThreshold)
+	}
 }
 
 func TestLoad_InvalidJSON(t *testing.T) {
@@ -297,11 +328,13 @@ func TestLoad(t *testing.T) {
 		configDir := filepath.Join(tempDir, ".title-tidy")
 		os.MkdirAll(configDir, 0755)
 
+		// match_threshold is always written, and zero is a valid value
 		testConfig := &FormatConfig{
-			ShowFolder:   "{title} - {year}",
-			SeasonFolder: "S{season}",
-			Episode:      "{code} {title}",
-			Movie:        "{title} [{year}]",
+			ShowFolder:     "{title} - {year}",
+			SeasonFolder:   "S{season}",
+			Episode:        "{code} {title}",
+			Movie:          "{title} [{year}]",
+			MatchThreshold: provider.DefaultMatchThreshold,
 		}
 
 		data, _ := json.MarshalIndent(testConfig, "", "  ")
@@ -329,6 +362,8 @@ func TestLoad(t *testing.T) {
 			EnableOMDBLookup: false,
 			TVDBAPIKey:       "",
 			EnableTVDBLookup: false,
+			SearchStrategies: defaultSearchStrategies(),
+			MatchThreshold:   provider.DefaultMatchThreshold,
 		}
 
 		if diff := cmp.Diff(expectedConfig, cfg, cmpOpts()); diff != "" {
@@ -376,6 +411,8 @@ func TestLoad(t *testing.T) {
 			EnableOMDBLookup: false,
 			TVDBAPIKey:       "",
 			EnableTVDBLookup: false,
+			SearchStrategies: defaultSearchStrategies(),
+			MatchThreshold:   provider.DefaultMatchThreshold,
 		}
 
 		if diff := cmp.Diff(want, cfg, cmpOpts()); diff != "" {
@@ -858,6 +895,31 @@ func TestNeedsMetadata(t *testing.T) {
 	}
 }
 
+func TestUsesVariable(t *testing.T) {
+	cfg := &FormatConfig{
+		ShowFolder: "{title} [{certification}]",
+		Episode:    "S{season}E{episode} - {director}",
+		Movie:      "{title}",
+	}
+	if !cfg.UsesVariable("certification") {
+		t.Errorf("UsesVariable(certification) = false, want true")
+	}
+	if !cfg.UsesVariable("cast", "director") {
+		t.Errorf("UsesVariable(cast, director) = false, want true")
+	}
+	if cfg.UsesVariable("cast", "studio") {
+		t.Errorf("UsesVariable(cast, studio) = true, want false")
+	}
+}
+
+func TestEpisodeVariables(t *testing.T) {
+	cfg := &FormatConfig{Episode: `{season_code}{episode_code} - {episode_title} \{draft\} {episode_title}`}
+	want := []string{"season_code", "episode_code", "episode_title"}
+	if diff := cmp.Diff(want, cfg.EpisodeVariables()); diff != "" {
+		t.Errorf("EpisodeVariables() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestApplyTemplateWithMetadata(t *testing.T) {
 	cfg := &FormatConfig{
 		ShowFolder:   "{title} ({year}) [{rating}]",
@@ -1067,3 +1129,160 @@ func TestResolveVariableComprehensive(t *testing.T) {
 		})
 	}
 }
+
+type settingsTestProvider struct {
+	settings map[string]interface{}
+}
+
+func (p *settingsTestProvider) Name() string                                    { return "custom" }
+func (p *settingsTestProvider) Description() string                             { return "custom" }
+func (p *settingsTestProvider) SupportedVariables() []provider.TemplateVariable { return nil }
+func (p *settingsTestProvider) ConfigSchema() provider.ConfigSchema             { return provider.ConfigSchema{} }
+func (p *settingsTestProvider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{MediaTypes: []provider.MediaType{provider.MediaTypeMovie}}
+}
+func (p *settingsTestProvider) Configure(settings map[string]interface{}) error {
+	p.settings = settings
+	return nil
+}
+func (p *settingsTestProvider) Fetch(context.Context, provider.FetchRequest) (*provider.Metadata, error) {
+	return nil, nil
+}
+
+func TestConfigureProviders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EnableFFProbe = true
+	cfg.EnableTMDBLookup = true // no API key, so it stays disabled
+	cfg.ProviderSettings = map[string]map[string]interface{}{
+		"custom": {"enabled": true, "endpoint": "http://localhost"},
+	}
+
+	custom := &settingsTestProvider{}
+	reg := provider.NewRegistry()
+	for name, prov := range map[string]provider.Provider{
+		"custom":  custom,
+		"ffprobe": &settingsTestProvider{},
+		"tmdb":    &settingsTestProvider{},
+		"omdb":    &settingsTestProvider{},
+	} {
+		if err := reg.Register(name, prov, 0); err != nil {
+			t.Fatalf("Register(%s) unexpected error: %v", name, err)
+		}
+	}
+
+	if err := cfg.ConfigureProviders(reg); err != nil {
+		t.Fatalf("ConfigureProviders() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"custom", "ffprobe"}, reg.Enabled()); diff != "" {
+		t.Errorf("Enabled() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"endpoint": "http://localhost"}, custom.settings); diff != "" {
+		t.Errorf("custom settings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProviderConfigLibrary(t *testing.T) {
+	cfg := DefaultConfig()
+	if _, enabled := cfg.ProviderConfig("library"); enabled {
+		t.Errorf("library enabled without a path")
+	}
+
+	cfg.LibraryPath = "/library"
+	settings, enabled := cfg.ProviderConfig("library")
+	if !enabled {
+		t.Errorf("library disabled with a path")
+	}
+	if diff := cmp.Diff(map[string]interface{}{"path": "/library"}, settings); diff != "" {
+		t.Errorf("library settings mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg.DisableLibraryMatch = true
+	if _, enabled := cfg.ProviderConfig("library"); enabled {
+		t.Errorf("library enabled with disable_library_match set")
+	}
+}
+
+func TestProviderConfigCreditsAndCertification(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TMDBAPIKey = "key"
+	settings, _ := cfg.ProviderConfig("tmdb")
+	if settings["credits"] != false || settings["certification_country"] != "" {
+		t.Errorf("credits = %v, certification_country = %q without the variables in a template", settings["credits"], settings["certification_country"])
+	}
+
+	cfg.Movie = "{title} ({year}) [{certification}] - {director}"
+	cfg.TMDBLanguage = "en-GB"
+	settings, _ = cfg.ProviderConfig("tmdb")
+	if settings["credits"] != true {
+		t.Errorf("credits = %v, want true with {director} in a template", settings["credits"])
+	}
+	if got := settings["certification_country"]; got != "GB" {
+		t.Errorf("certification_country = %v, want GB from the TMDB language", got)
+	}
+
+	cfg.CertificationCountry = "de"
+	settings, _ = cfg.ProviderConfig("tvdb")
+	if got := settings["certification_country"]; got != "DE" {
+		t.Errorf("tvdb certification_country = %v, want DE", got)
+	}
+}
+
+func TestConfigureProvidersAppliesRateLimits(t *testing.T) {
+	noRetries := 0
+	cfg := DefaultConfig()
+	cfg.RateLimits = map[string]RateLimitConfig{
+		"tmdb":  {Requests: 5, MaxRetries: &noRetries},
+		"plain": {Requests: 1},
+	}
+
+	base := provider.Policy{Requests: 38, Window: 10 * time.Second, MaxRetries: 2, BaseDelay: time.Second}
+	tmdb := provider.NewResilient(&settingsTestProvider{}, base)
+	omdb := provider.NewResilient(&settingsTestProvider{}, base)
+	reg := provider.NewRegistry()
+	for name, prov := range map[string]provider.Provider{"tmdb": tmdb, "omdb": omdb, "plain": &settingsTestProvider{}} {
+		if err := reg.Register(name, prov, 0); err != nil {
+			t.Fatalf("Register(%s) unexpected error: %v", name, err)
+		}
+	}
+
+	if err := cfg.ConfigureProviders(reg); err != nil {
+		t.Fatalf("ConfigureProviders() unexpected error: %v", err)
+	}
+	want := provider.Policy{Requests: 5, Window: 10 * time.Second, MaxRetries: 0, BaseDelay: time.Second}
+	if diff := cmp.Diff(want, tmdb.Policy()); diff != "" {
+		t.Errorf("tmdb policy mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(base, omdb.Policy()); diff != "" {
+		t.Errorf("omdb policy mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddTitleAlias(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	aliases := []local.Alias{
+		{Name: "Shield", Title: "Agents of S.H.I.E.L.D.", Year: "2013"},
+		{Name: "The Office", Path: "/media/uk", Year: "2001"},
+		{Name: "  shield ", IDs: map[string]string{"tmdb": "1403"}},
+	}
+	for _, alias := range aliases {
+		if err := AddTitleAlias(alias); err != nil {
+			t.Fatalf("AddTitleAlias(%q) unexpected error: %v", alias.Name, err)
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	// The second "Shield" replaces the first rather than adding another
+	want := []local.Alias{
+		{Name: "shield", IDs: map[string]string{"tmdb": "1403"}},
+		{Name: "The Office", Path: "/media/uk", Year: "2001"},
+	}
+	if diff := cmp.Diff(want, cfg.TitleAliases); diff != "" {
+		t.Errorf("TitleAliases mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+// A video under this share of the provider runtime is truncated
+const integrityDurationRatio = 0.6
+
+// IntegrityChecker flags video files that look truncated or corrupt: files
+// ffprobe can't read, that are empty, lack a video or audio stream, log
+// stream errors, or run well short of the provider runtime. Samples are
+// expected to be short and are never flagged.
+type IntegrityChecker struct {
+	Probe    provider.Provider             // reads the files; nil flags only empty files
+	Metadata map[string]*provider.Metadata // provider results holding runtimes
+}
+
+// Check returns why node looks damaged, or "" when it looks whole.
+func (c IntegrityChecker) Check(ctx context.Context, node *treeview.Node[treeview.FileInfo]) string {
+	if node.Data().IsDir() || !local.IsVideo(node.Name()) {
+		return ""
+	}
+	if node.Data().Size() == 0 {
+		return "suspect: empty file"
+	}
+	if c.Probe == nil {
+		return ""
+	}
+	samples := SampleDetector{Probe: c.Probe, Metadata: c.Metadata}
+	if _, confirmed := samples.Classify(ctx, node); confirmed {
+		return ""
+	}
+
+	meta, err := probeFile(ctx, c.Probe, node)
+	if err != nil {
+		var perr *provider.ProviderError
+		if ctx.Err() != nil || (errors.As(err, &perr) && perr.Code == "TIMEOUT") {
+			// Not a verdict on the file
+			return ""
+		}
+		return "suspect: unreadable by ffprobe"
+	}
+
+	switch {
+	case extendedInt(meta, "video_streams") == 0:
+		return "suspect: no video stream"
+	case extendedInt(meta, "audio_streams") == 0:
+		return "suspect: no audio stream"
+	}
+	duration := time.Duration(extendedInt(meta, "duration_seconds")) * time.Second
+	if duration == 0 {
+		return "suspect: zero length"
+	}
+	if problems, _ := meta.Extended["probe_errors"].(string); problems != "" {
+		first, _, _ := strings.Cut(problems, "\n")
+		return "suspect: " + first
+	}
+	runtime := providerRuntime(c.Metadata, node)
+	if runtime > 0 && duration < time.Duration(float64(runtime)*integrityDurationRatio) {
+		return fmt.Sprintf("suspect: %s of a %s runtime", formatSpan(duration), formatSpan(runtime))
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/ffprobe"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+// reportProvider returns a fixed probe report, or err, for every file.
+type reportProvider struct {
+	retryTestProvider
+	extended map[string]interface{}
+	err      error
+}
+
+func (p reportProvider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &provider.Metadata{Extended: p.extended}, nil
+}
+
+func TestIntegrityCheckerCheck(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+	key := provider.GenerateMetadataKey("movie", "Heat", "1995", 0, 0)
+	runtimes := map[string]*provider.Metadata{key: {Extended: map[string]interface{}{"runtime": 170}}}
+	report := func(seconds, video, audio int, problems string) map[string]interface{} {
+		extended := map[string]interface{}{"duration_seconds": seconds, "video_streams": video, "audio_streams": audio}
+		if problems != "" {
+			extended["probe_errors"] = problems
+		}
+		return extended
+	}
+
+	tests := []struct {
+		name    string
+		checker IntegrityChecker
+		file    string
+		size    int64
+		want    string
+	}{
+		{name: "whole file", checker: IntegrityChecker{Probe: reportProvider{extended: report(10140, 1, 1, "")}, Metadata: runtimes}, file: "Heat (1995).mkv", size: 8000 * mb},
+		{name: "truncated", checker: IntegrityChecker{Probe: reportProvider{extended: report(2460, 1, 1, "")}, Metadata: runtimes}, file: "Heat (1995).mkv", size: 2000 * mb, want: "suspect: 41m of a 2h 50m runtime"},
+		{name: "short without runtime", checker: IntegrityChecker{Probe: reportProvider{extended: report(2460, 1, 1, "")}}, file: "Heat (1995).mkv", size: 2000 * mb},
+		{name: "empty", checker: IntegrityChecker{}, file: "Heat (1995).mkv", size: 0, want: "suspect: empty file"},
+		{name: "no probe", checker: IntegrityChecker{}, file: "Heat (1995).mkv", size: 8000 * mb},
+		{name: "no video", checker: IntegrityChecker{Probe: reportProvider{extended: report(10140, 0, 1, "")}}, file: "Heat (1995).mkv", size: 8000 * mb, want: "suspect: no video stream"},
+		{name: "no audio", checker: IntegrityChecker{Probe: reportProvider{extended: report(10140, 1, 0, "")}}, file: "Heat (1995).mkv", size: 8000 * mb, want: "suspect: no audio stream"},
+		{name: "zero length", checker: IntegrityChecker{Probe: reportProvider{extended: report(0, 1, 1, "")}}, file: "Heat (1995).mkv", size: 8000 * mb, want: "suspect: zero length"},
+		{name: "stream errors", checker: IntegrityChecker{Probe: reportProvider{extended: report(10140, 1, 1, "stream 1: partial file\nnext error")}}, file: "Heat (1995).mkv", size: 8000 * mb, want: "suspect: stream 1: partial file"},
+		{name: "unreadable", checker: IntegrityChecker{Probe: reportProvider{err: errors.New("moov atom not found")}}, file: "Heat (1995).mkv", size: 8000 * mb, want: "suspect: unreadable by ffprobe"},
+		{name: "timeout", checker: IntegrityChecker{Probe: reportProvider{err: &provider.ProviderError{Code: "TIMEOUT"}}}, file: "Heat (1995).mkv", size: 8000 * mb},
+		{name: "sample", checker: IntegrityChecker{Probe: reportProvider{extended: report(60, 1, 1, "")}, Metadata: runtimes}, file: "Heat.1995.sample.mkv", size: 50 * mb},
+		{name: "not a video", checker: IntegrityChecker{}, file: "Heat (1995).srt", size: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tc.checker.Check(context.Background(), sizedNode(tc.file, tc.size)); got != tc.want {
+				t.Errorf("Check(%q) = %q, want %q", tc.file, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIntegrityCheckerCheckCachedProbe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the ffprobe binary")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "fake-ffprobe")
+	script := "#!/bin/sh\necho '{\"format\":{\"format_name\":\"matroska,webm\",\"duration\":\"10140.0\"},\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"h264\"},{\"codec_type\":\"audio\",\"codec_name\":\"aac\"}]}'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	video := filepath.Join(dir, "Heat (1995).mkv")
+	if err := os.WriteFile(video, []byte("video"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cache, err := provider.OpenDiskCache(filepath.Join(dir, "metadata.json"))
+	if err != nil {
+		t.Fatalf("OpenDiskCache() unexpected error: %v", err)
+	}
+
+	probe := ffprobe.New()
+	if err := probe.Configure(map[string]interface{}{"binary_path": binary}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	probe.SetCache(cache)
+
+	info := sizedFileInfo{SimpleFileInfo: NewSimpleFileInfo("Heat (1995).mkv", false), size: 5}
+	node := treeview.NewNode(video, "Heat (1995).mkv", treeview.FileInfo{FileInfo: info, Path: video})
+	checker := IntegrityChecker{Probe: probe}
+	if got := checker.Check(context.Background(), node); got != "" {
+		t.Fatalf("Check() on a fresh probe = %q, want \"\"", got)
+	}
+
+	// The second check is answered from the cache
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if got := checker.Check(context.Background(), node); got != "" {
+		t.Errorf("Check() on a cached probe = %q, want \"\"", got)
+	}
+}
@@ -37,6 +37,8 @@ const (
 //   - NeedsDirectory: Signals that a directory must be created before children
 //     are renamed beneath it (typically paired with IsVirtual).
 //   - MarkedForDeletion: True when the file should be deleted during rename operation.
+//   - Suspect: Why the integrity check thinks the file is truncated or corrupt,
+//     or why it may be a sample that --no-sample left in place.
 //
 // The zero value is meaningful: it encodes an untyped, unprocessed node with no rename proposal.
 type MediaMeta struct {
@@ -48,6 +50,8 @@ type MediaMeta struct {
 	IsVirtual         bool
 	NeedsDirectory    bool
 	MarkedForDeletion bool
+	DeletionReason    string // Why the node is marked, shown before deleting
+	Suspect           string
 }
 
 // GetMeta retrieves the existing *MediaMeta attached to n or nil when absent.
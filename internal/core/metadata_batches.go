@@ -0,0 +1,78 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// seasonBatch holds one provider's episodes for a season. It is fetched once,
+// by whichever worker reaches an episode of the season first, and shared by
+// the season's other episodes.
+type seasonBatch struct {
+	once     sync.Once
+	episodes map[int]*provider.Metadata
+}
+
+// coversEpisodes reports whether a season batch from prov fills every
+// episode variable prov supports that the episode template uses. A nil
+// used list means any of them might be.
+func coversEpisodes(prov provider.Provider, used []string) bool {
+	batcher, ok := prov.(provider.SeasonBatcher)
+	if !ok || !provider.BatchesSeasons(prov) {
+		return false
+	}
+	filled := batcher.SeasonEpisodeVariables()
+	for _, v := range prov.SupportedVariables() {
+		if !slices.Contains(v.MediaTypes, provider.MediaTypeEpisode) {
+			continue
+		}
+		if used != nil && !slices.Contains(used, v.Name) {
+			continue
+		}
+		if !slices.Contains(filled, v.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// batchedEpisode returns an episode from the source's batch for its season.
+// ok is false when the source doesn't batch seasons, the batch failed or it
+// lacks the episode; the episode is then fetched on its own, which also
+// reports any error.
+func (e *MetadataEngine) batchedEpisode(ctx context.Context, src metadataSource, item MetadataItem) (meta *provider.Metadata, ok bool) {
+	if !src.batchSeasons || item.Season <= 0 || item.Episode <= 0 {
+		return nil, false
+	}
+
+	key := src.name + "|" + provider.GenerateMetadataKey("season", item.Name, item.Year, item.Season, 0)
+	value, _ := e.seasonBatches.LoadOrStore(key, &seasonBatch{})
+	batch := value.(*seasonBatch)
+	batch.once.Do(func() {
+		episodes, err := provider.FetchSeasonEpisodesWithDependencies(ctx, src.provider, item.Name, item.Year, item.Season, e.showCache(src.name), nil, e.searchStrategies...)
+		if err == nil {
+			batch.episodes = episodes
+		}
+	})
+
+	meta = batch.episodes[item.Episode]
+	return meta, meta != nil
+}
+
+// groupBySeason orders episode items by show and season so the episodes of a
+// season are worked on together.
+func groupBySeason(items []MetadataItem) []MetadataItem {
+	grouped := slices.Clone(items)
+	slices.SortStableFunc(grouped, func(a, b MetadataItem) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Year, b.Year),
+			cmp.Compare(a.Season, b.Season),
+		)
+	})
+	return grouped
+}
@@ -4,16 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
 
+	"github.com/Digital-Shane/title-tidy/internal/overrides"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
-	"github.com/Digital-Shane/title-tidy/internal/provider/ffprobe"
 	"github.com/Digital-Shane/title-tidy/internal/provider/local"
-	"github.com/Digital-Shane/title-tidy/internal/provider/omdb"
-	"github.com/Digital-Shane/title-tidy/internal/provider/tmdb"
-	"github.com/Digital-Shane/title-tidy/internal/provider/tvdb"
 	"github.com/Digital-Shane/treeview/v2"
 	"github.com/mhmtszr/concurrent-swiss-map"
 )
@@ -22,13 +20,24 @@ import (
 // exposing progress snapshots for UI consumption.
 type MetadataEngine struct {
 	workerCount int
+	probeSlots  chan struct{} // limits concurrent file probes
 	localProv   *local.Provider
 	tree        *treeview.Tree[treeview.FileInfo]
 
-	tmdbProvider    provider.Provider
-	tvdbProvider    provider.Provider
-	omdbProvider    provider.Provider
-	ffprobeProvider provider.Provider
+	sources    []metadataSource
+	precedence map[string][]string // field -> providers preferred for it
+
+	searchStrategies []provider.SearchStrategy
+	matchThreshold   float64
+
+	overrides    *overrides.Store
+	writeIDFiles bool
+	idFiles      sync.Map // folder path -> map[string]string read from its ID file
+
+	offline bool
+
+	seasonBatches sync.Map // source and season key -> *seasonBatch
+	showLookups   sync.Map // source and show key -> provider.ShowLookup
 
 	metadata *csmap.CsMap[string, *provider.Metadata]
 
@@ -54,6 +63,8 @@ type MetadataSummary struct {
 	PhaseName       string
 	ActiveProviders []string
 	ErrorCount      int
+	Offline         bool
+	Unenriched      int // Items no provider had data for while offline
 	LastItem        string
 	Done            bool
 	Canceled        bool
@@ -65,69 +76,84 @@ type MetadataEvent struct {
 	Err     error
 }
 
-// MetadataProviderType identifies a metadata provider that can contribute
-// results to the aggregation pipeline. Only providers that support manual
-// search overrides are currently enumerated.
-type MetadataProviderType string
+// metadataSource is an enabled provider the engine queries for each item.
+// Providers that read the media file enrich results but never search.
+type metadataSource struct {
+	name         string
+	provider     provider.Provider
+	requiresFile bool
+	batchSeasons bool // episodes are filled from one fetch per season
+	mediaTypes   []provider.MediaType
+}
 
-const (
-	MetadataProviderTMDB MetadataProviderType = "tmdb"
-	MetadataProviderTVDB MetadataProviderType = "tvdb"
-	MetadataProviderOMDB MetadataProviderType = "omdb"
-)
+func newMetadataSource(name string, prov provider.Provider) metadataSource {
+	caps := prov.Capabilities()
+	return metadataSource{
+		name:         name,
+		provider:     prov,
+		requiresFile: caps.RequiresFile,
+		mediaTypes:   slices.Clone(caps.MediaTypes),
+	}
+}
+
+// supports reports whether the source handles the item's media type.
+func (s metadataSource) supports(item MetadataItem) bool {
+	return item.MediaType == "" || len(s.mediaTypes) == 0 || slices.Contains(s.mediaTypes, item.MediaType)
+}
 
 // MetadataFailure captures a provider-specific failure for a metadata item so
 // callers (e.g., the TUI) can offer manual search overrides before proceeding.
+// Provider is the provider's registry name.
+// SearchAttempts lists every query sent to the provider, including fallbacks.
+// ParsedName keeps the name parsed from disk after retries change Item.Name.
 type MetadataFailure struct {
-	Item     MetadataItem
-	Provider MetadataProviderType
-	Query    string
-	Err      error
-	Attempts int
+	Item           MetadataItem
+	ParsedName     string
+	Provider       string
+	Query          string
+	Err            error
+	Attempts       int
+	SearchAttempts []provider.SearchAttempt
 }
 
 // MetadataEngineConfig configures provider access for the metadata engine.
 type MetadataEngineConfig struct {
 	Tree          *treeview.Tree[treeview.FileInfo]
 	LocalProvider *local.Provider
-	WorkerCount   int
-	Providers     MetadataProvidersConfig
-}
-
-// MetadataProvidersConfig contains per-provider configuration.
-type MetadataProvidersConfig struct {
-	TMDB    TMDBProviderConfig
-	TVDB    TVDBProviderConfig
-	OMDB    OMDBProviderConfig
-	FFProbe FFProbeProviderConfig
-}
-
-// TMDBProviderConfig describes TMDB provider configuration.
-type TMDBProviderConfig struct {
-	Enabled      bool
-	APIKey       string
-	Language     string
-	CacheEnabled *bool
-	Provider     provider.Provider
-}
-
-// OMDBProviderConfig describes OMDb provider configuration.
-type OMDBProviderConfig struct {
-	Enabled  bool
-	APIKey   string
-	Provider provider.Provider
-}
-
-type TVDBProviderConfig struct {
-	Enabled  bool
-	APIKey   string
-	Provider provider.Provider
-}
-
-// FFProbeProviderConfig describes ffprobe provider configuration.
-type FFProbeProviderConfig struct {
-	Enabled  bool
-	Provider provider.Provider
+	// WorkerCount limits how many items are looked up at once. Zero uses 20.
+	WorkerCount int
+	// Registry holds the providers, whose enabled ones are queried in
+	// priority order. Nil uses provider.GlobalRegistry.
+	Registry *provider.Registry
+	// FieldPrecedence names, per merged field (e.g. "rating"), the providers
+	// whose value wins ahead of registry priority.
+	FieldPrecedence map[string][]string
+	// SearchStrategies is the fallback chain tried when a search finds
+	// nothing.
+	SearchStrategies []provider.SearchStrategy
+	// MatchThreshold queues provider matches scoring below it for manual
+	// review instead of applying them. Zero accepts every match.
+	MatchThreshold float64
+	// Overrides remembers the IDs picked during manual resolution and
+	// supplies them to later runs.
+	Overrides *overrides.Store
+	// WriteIDFiles also records picked IDs in an ID file inside the show or
+	// movie folder.
+	WriteIDFiles bool
+	// EpisodeVariables lists the variables the episode template uses.
+	// Episodes are filled from one fetch per season from providers whose
+	// season payload has all of them; nil assumes every variable a provider
+	// supports is used.
+	EpisodeVariables []string
+	// FFProbeWorkers limits how many files are probed at once. Probes run
+	// beside the network lookups instead of taking up their workers. Zero
+	// uses 4.
+	FFProbeWorkers int
+	// Offline answers every lookup from the providers' persistent caches.
+	// Providers that can't be limited to their cache are skipped unless they
+	// read the media file, and cache misses count as unenriched items, not
+	// errors.
+	Offline bool
 }
 
 // NewMetadataEngine constructs an engine with sane defaults applied.
@@ -140,80 +166,85 @@ func NewMetadataEngine(cfg MetadataEngineConfig) *MetadataEngine {
 	if workerCount <= 0 {
 		workerCount = 20
 	}
+	probeWorkers := cfg.FFProbeWorkers
+	if probeWorkers <= 0 {
+		probeWorkers = 4
+	}
 
 	engine := &MetadataEngine{
-		workerCount: workerCount,
-		localProv:   localProv,
-		tree:        cfg.Tree,
-		metadata:    csmap.Create[string, *provider.Metadata](),
+		workerCount:      workerCount,
+		probeSlots:       make(chan struct{}, probeWorkers),
+		localProv:        localProv,
+		tree:             cfg.Tree,
+		searchStrategies: slices.Clone(cfg.SearchStrategies),
+		matchThreshold:   cfg.MatchThreshold,
+		precedence:       maps.Clone(cfg.FieldPrecedence),
+		overrides:        cfg.Overrides,
+		writeIDFiles:     cfg.WriteIDFiles,
+		offline:          cfg.Offline,
+		metadata:         csmap.Create[string, *provider.Metadata](),
 		summary: MetadataSummary{
 			WorkerLimit: workerCount,
+			Offline:     cfg.Offline,
 		},
 	}
 
-	engine.initProviders(cfg.Providers)
+	engine.sources = metadataSources(cfg.Registry)
+	if cfg.Offline {
+		engine.sources = offlineSources(engine.sources)
+	}
+	for i, src := range engine.sources {
+		engine.sources[i].batchSeasons = !src.requiresFile && coversEpisodes(src.provider, cfg.EpisodeVariables)
+	}
+	for _, src := range engine.sources {
+		engine.activeProviders = append(engine.activeProviders, providerNameOrDefault(src.provider, src.name))
+	}
 	engine.summary.ActiveProviders = slices.Clone(engine.activeProviders)
 
 	return engine
 }
 
-func (e *MetadataEngine) initProviders(cfg MetadataProvidersConfig) {
-	if cfg.TMDB.Enabled {
-		prov := cfg.TMDB.Provider
-		if prov == nil {
-			prov = tmdb.New()
-		}
-		if cfg.TMDB.APIKey != "" {
-			cacheEnabled := true
-			if cfg.TMDB.CacheEnabled != nil {
-				cacheEnabled = *cfg.TMDB.CacheEnabled
-			}
-			conf := map[string]interface{}{
-				"api_key":       cfg.TMDB.APIKey,
-				"language":      cfg.TMDB.Language,
-				"cache_enabled": cacheEnabled,
-			}
-			if err := prov.Configure(conf); err == nil {
-				e.tmdbProvider = prov
-				e.activeProviders = append(e.activeProviders, providerNameOrDefault(prov, "TMDB"))
-			}
-		}
+// metadataSources lists the enabled providers in reg, highest priority
+// first. The local parser drives item detection and is not queried.
+func metadataSources(reg *provider.Registry) []metadataSource {
+	if reg == nil {
+		reg = provider.GlobalRegistry
 	}
 
-	if cfg.OMDB.Enabled {
-		prov := cfg.OMDB.Provider
-		if prov == nil {
-			prov = omdb.New()
+	sources := make([]metadataSource, 0)
+	for _, name := range reg.Enabled() {
+		prov, ok := reg.Get(name)
+		if !ok || prov == nil {
+			continue
 		}
-		if cfg.OMDB.APIKey != "" {
-			if err := prov.Configure(map[string]interface{}{"api_key": cfg.OMDB.APIKey}); err == nil {
-				e.omdbProvider = prov
-				e.activeProviders = append(e.activeProviders, providerNameOrDefault(prov, "OMDb"))
-			}
+		if _, isLocal := prov.(*local.Provider); isLocal {
+			continue
 		}
+		sources = append(sources, newMetadataSource(name, prov))
 	}
+	return sources
+}
 
-	if cfg.TVDB.Enabled {
-		prov := cfg.TVDB.Provider
-		if prov == nil {
-			prov = tvdb.New()
-		}
-		if cfg.TVDB.APIKey != "" {
-			if err := prov.Configure(map[string]interface{}{"api_key": cfg.TVDB.APIKey}); err == nil {
-				e.tvdbProvider = prov
-				e.activeProviders = append(e.activeProviders, providerNameOrDefault(prov, "TVDB"))
-			}
+// offlineSources limits sources to their cached responses. Sources that can't
+// be limited to a cache are dropped, except those that only read the media
+// file, which need no network and are kept as they are.
+func offlineSources(sources []metadataSource) []metadataSource {
+	kept := make([]metadataSource, 0, len(sources))
+	for _, src := range sources {
+		if fetcher, ok := src.provider.(provider.OfflineFetcher); ok {
+			fetcher.SetOffline(true)
+		} else if !src.requiresFile {
+			continue
 		}
+		kept = append(kept, src)
 	}
+	return kept
+}
 
-	if cfg.FFProbe.Enabled {
-		prov := cfg.FFProbe.Provider
-		if prov == nil {
-			prov = ffprobe.New()
-		}
-		e.ffprobeProvider = prov
-		e.activeProviders = append(e.activeProviders, providerNameOrDefault(prov, "ffprobe"))
-	}
+// HasMetadataSources reports whether reg has any enabled provider the engine
+// would query.
+func HasMetadataSources(reg *provider.Registry) bool {
+	return len(metadataSources(reg)) > 0
 }
 
 func providerNameOrDefault(prov provider.Provider, fallback string) string {
@@ -274,7 +305,7 @@ func (e *MetadataEngine) run(ctx context.Context, events chan<- MetadataEvent) {
 		return
 	}
 
-	if e.tmdbProvider == nil && e.tvdbProvider == nil && e.omdbProvider == nil && e.ffprobeProvider == nil {
+	if len(e.sources) == 0 {
 		e.summaryMu.Lock()
 		e.summary.Done = true
 		e.summaryMu.Unlock()
@@ -296,6 +327,9 @@ func (e *MetadataEngine) run(ctx context.Context, events chan<- MetadataEvent) {
 		if len(phaseItems) == 0 {
 			continue
 		}
+		if phase == 2 {
+			phaseItems = groupBySeason(phaseItems)
+		}
 
 		e.summaryMu.Lock()
 		e.summary.PhaseIndex = phase
@@ -387,52 +421,71 @@ func (e *MetadataEngine) worker(ctx context.Context, wg *sync.WaitGroup, workCh
 			return
 		}
 
-		tmdbMeta, tmdbErr := e.fetchTMDBMetadata(ctx, item)
-		tvdbMeta, tvdbErr := e.fetchTVDBMetadata(ctx, item)
-		omdbMeta, omdbErr := e.fetchOMDBMetadata(ctx, item)
-		ffprobeMeta, ffprobeErr := e.fetchFFProbeMetadata(ctx, item)
-
-		base := tmdbMeta
-		extra := make([]*provider.Metadata, 0, 3)
-		if base == nil && tvdbMeta != nil {
-			base = tvdbMeta
-		} else if tvdbMeta != nil {
-			extra = append(extra, tvdbMeta)
-		}
-		if base == nil && omdbMeta != nil {
-			base = omdbMeta
-		} else if omdbMeta != nil {
-			extra = append(extra, omdbMeta)
-		}
-		if ffprobeMeta != nil {
-			extra = append(extra, ffprobeMeta)
+		metas := make([]*provider.Metadata, len(e.sources))
+		providerErrs := make(map[string]error, len(e.sources))
+		probes := e.startProbes(ctx, item)
+		known := make(map[string]string)
+		searched := false
+		for i, src := range e.sources {
+			item.KnownIDs = known
+			var meta *provider.Metadata
+			var err error
+			if probe := probes[i]; probe != nil {
+				out := <-probe
+				meta, err = out.meta, out.err
+			} else {
+				meta, err = e.fetchSource(ctx, src, item)
+			}
+			metas[i] = meta
+			providerErrs[src.name] = err
+			if meta != nil {
+				searched = searched || !src.requiresFile
+				if item.Phase == 0 {
+					addKnownIDs(known, meta.IDs)
+				}
+			}
 		}
 
-		combined := MergeMetadata(item, base, extra...)
-
-		errs := make([]error, 0, 4)
-		if tmdbErr != nil {
-			errs = append(errs, tmdbErr)
-		}
-		if tvdbErr != nil {
-			errs = append(errs, tvdbErr)
+		// File sources rank below the name searches, so a file whose name
+		// found nothing is looked up again by the IDs read from its content.
+		if !searched && len(known) > 0 {
+			for i, src := range e.sources {
+				if src.requiresFile {
+					continue
+				}
+				metas[i], providerErrs[src.name] = e.fetchSource(ctx, src, item)
+			}
 		}
-		if omdbErr != nil {
-			errs = append(errs, omdbErr)
+		item.KnownIDs = nil
+
+		results := make([]sourcedMetadata, 0, len(e.sources))
+		errs := make([]error, 0, len(e.sources))
+		for i, src := range e.sources {
+			err := providerErrs[src.name]
+			if e.offline && provider.IsCacheMiss(err) {
+				err = nil
+			}
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				delete(providerErrs, src.name)
+			}
+			if metas[i] != nil {
+				results = append(results, sourcedMetadata{provider: src.name, meta: metas[i]})
+			}
 		}
-		if ffprobeErr != nil {
-			errs = append(errs, ffprobeErr)
+
+		var combined *provider.Metadata
+		if slices.ContainsFunc(results, func(res sourcedMetadata) bool { return HasMetadataValues(res.meta) }) {
+			combined = mergeSourced(item, e.ranker(), results)
 		}
 
 		select {
 		case resultCh <- MetadataResult{
-			Item:       item,
-			Meta:       combined,
-			Errs:       errs,
-			TMDBErr:    tmdbErr,
-			TVDBErr:    tvdbErr,
-			OMDBErr:    omdbErr,
-			FFProbeErr: ffprobeErr,
+			Item:         item,
+			Meta:         combined,
+			Errs:         errs,
+			ProviderErrs: providerErrs,
 		}:
 		case <-ctx.Done():
 			return
@@ -440,6 +493,50 @@ func (e *MetadataEngine) worker(ctx context.Context, wg *sync.WaitGroup, workCh
 	}
 }
 
+// fetchOutcome is the result of a fetch run in the background.
+type fetchOutcome struct {
+	meta *provider.Metadata
+	err  error
+}
+
+// startProbes starts the item's file-reading sources in the background,
+// indexed like e.sources; other sources have a nil entry. Probes hold one of
+// the engine's probe slots while they run, so slow files neither wait behind
+// network lookups nor hold them up.
+func (e *MetadataEngine) startProbes(ctx context.Context, item MetadataItem) []<-chan fetchOutcome {
+	probes := make([]<-chan fetchOutcome, len(e.sources))
+	for i, src := range e.sources {
+		if !src.requiresFile || !src.supports(item) {
+			continue
+		}
+		out := make(chan fetchOutcome, 1)
+		probes[i] = out
+		go func() {
+			select {
+			case e.probeSlots <- struct{}{}:
+			case <-ctx.Done():
+				out <- fetchOutcome{err: ctx.Err()}
+				return
+			}
+			defer func() { <-e.probeSlots }()
+			meta, err := e.fetchSource(ctx, src, item)
+			out <- fetchOutcome{meta: meta, err: err}
+		}()
+	}
+	return probes
+}
+
+// addKnownIDs records the IDs a provider resolved for a movie or show so the
+// providers queried after it can look the same title up exactly. Sources are
+// queried in priority order, so IDs already known are kept.
+func addKnownIDs(known, ids map[string]string) {
+	for key, id := range ids {
+		if _, exists := known[key]; !exists && id != "" {
+			known[key] = id
+		}
+	}
+}
+
 func (e *MetadataEngine) processResult(res MetadataResult) {
 	if res.Meta != nil {
 		e.metadata.Store(res.Item.Key, res.Meta)
@@ -449,6 +546,9 @@ func (e *MetadataEngine) processResult(res MetadataResult) {
 	e.appendErrors(res)
 	e.summaryMu.Lock()
 	e.summary.ProcessedItems++
+	if e.offline && res.Meta == nil {
+		e.summary.Unenriched++
+	}
 	e.summary.ErrorCount = failureCount
 	e.summary.LastItem = FormatMetadataProgressMessage(res.Item)
 	e.summaryMu.Unlock()
@@ -488,9 +588,12 @@ func (e *MetadataEngine) updateProviderFailures(res MetadataResult) int {
 	e.failuresMu.Lock()
 	defer e.failuresMu.Unlock()
 
-	e.updateFailureLocked(res.Item, MetadataProviderTMDB, res.Item.Name, res.TMDBErr)
-	e.updateFailureLocked(res.Item, MetadataProviderTVDB, res.Item.Name, res.TVDBErr)
-	e.updateFailureLocked(res.Item, MetadataProviderOMDB, res.Item.Name, res.OMDBErr)
+	for _, src := range e.sources {
+		if src.requiresFile {
+			continue
+		}
+		e.updateFailureLocked(res.Item, src.name, res.Item.Name, res.ProviderErrs[src.name])
+	}
 
 	return len(e.failures)
 }
@@ -512,15 +615,16 @@ func (e *MetadataEngine) ProviderFailures() []MetadataFailure {
 }
 
 // RetryProvider re-executes a provider lookup using an optional manual search
-// term. It returns nil when the failure has been resolved. If the provider
+// term, or fetches an exact provider ID (such as a picked candidate) when id is
+// set. It returns nil when the failure has been resolved. If the provider
 // still fails, the updated failure (including attempt count and error) is
 // returned for display. A non-nil error indicates an unexpected engine issue.
-func (e *MetadataEngine) RetryProvider(ctx context.Context, key string, providerType MetadataProviderType, nameOverride string) (*MetadataFailure, error) {
+func (e *MetadataEngine) RetryProvider(ctx context.Context, key, providerName, nameOverride, id string) (*MetadataFailure, error) {
 	e.failuresMu.Lock()
-	idx := e.findFailureIndexLocked(key, providerType)
+	idx := e.findFailureIndexLocked(key, providerName)
 	if idx < 0 {
 		e.failuresMu.Unlock()
-		return nil, fmt.Errorf("metadata failure for %s/%s not found", providerType, key)
+		return nil, fmt.Errorf("metadata failure for %s/%s not found", providerName, key)
 	}
 	failure := e.failures[idx]
 	e.failuresMu.Unlock()
@@ -536,43 +640,38 @@ func (e *MetadataEngine) RetryProvider(ctx context.Context, key string, provider
 	attemptItem := failure.Item
 	attemptItem.Name = query
 
+	src, err := e.sourceFor(providerName)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		meta     *provider.Metadata
 		fetchErr error
 	)
 
-	switch providerType {
-	case MetadataProviderTMDB:
-		if e.tmdbProvider == nil {
-			return nil, fmt.Errorf("tmdb provider not configured")
-		}
-		meta, fetchErr = FetchTMDBMetadata(ctx, e.tmdbProvider, e.metadataCache(), attemptItem)
-	case MetadataProviderTVDB:
-		if e.tvdbProvider == nil {
-			return nil, fmt.Errorf("tvdb provider not configured")
-		}
-		meta, fetchErr = FetchTVDBMetadata(ctx, e.tvdbProvider, attemptItem, e.metadataCache())
-	case MetadataProviderOMDB:
-		if e.omdbProvider == nil {
-			return nil, fmt.Errorf("omdb provider not configured")
-		}
-		meta, fetchErr = FetchOMDBMetadata(ctx, e.omdbProvider, attemptItem, e.metadataCache())
-	default:
-		return nil, fmt.Errorf("unsupported metadata provider %q", providerType)
+	id = strings.TrimSpace(id)
+	attempt := provider.SearchAttempt{Strategy: provider.SearchStrategyManual, Name: query, Year: attemptItem.Year}
+	if id != "" {
+		attempt = provider.SearchAttempt{Strategy: provider.SearchStrategyID, Name: id}
+		meta, fetchErr = FetchMetadataByID(ctx, src.provider, attemptItem, id)
+	} else {
+		meta, fetchErr = FetchProviderMetadata(ctx, src.provider, e.showCache(src.name), attemptItem)
 	}
 
 	if fetchErr != nil || meta == nil {
 		e.failuresMu.Lock()
 		defer e.failuresMu.Unlock()
 
-		idx = e.findFailureIndexLocked(key, providerType)
+		idx = e.findFailureIndexLocked(key, providerName)
 		if idx < 0 {
-			return nil, fmt.Errorf("metadata failure for %s/%s not found after retry", providerType, key)
+			return nil, fmt.Errorf("metadata failure for %s/%s not found after retry", providerName, key)
 		}
 
 		updated := e.failures[idx]
 		updated.Attempts++
 		updated.Query = query
+		updated.SearchAttempts = append(slices.Clone(updated.SearchAttempts), attempt)
 		updated.Item.Name = attemptItem.Name
 		if meta == nil && fetchErr == nil {
 			fetchErr = fmt.Errorf("no metadata returned for %q", query)
@@ -588,10 +687,15 @@ func (e *MetadataEngine) RetryProvider(ctx context.Context, key string, provider
 		return &failureCopy, nil
 	}
 
-	e.applyManualMetadata(attemptItem, providerType, meta)
+	e.applyManualMetadata(attemptItem, providerName, meta)
+	if err := e.rememberOverride(failure, src, id, meta); err != nil {
+		e.errorsMu.Lock()
+		e.errors = append(e.errors, fmt.Errorf("%s: failed to save match override: %w", failure.Item.Name, err))
+		e.errorsMu.Unlock()
+	}
 
 	e.failuresMu.Lock()
-	idx = e.findFailureIndexLocked(key, providerType)
+	idx = e.findFailureIndexLocked(key, providerName)
 	if idx >= 0 {
 		e.failures = append(e.failures[:idx], e.failures[idx+1:]...)
 	}
@@ -606,8 +710,59 @@ func (e *MetadataEngine) RetryProvider(ctx context.Context, key string, provider
 	return nil, nil
 }
 
-func (e *MetadataEngine) updateFailureLocked(item MetadataItem, providerType MetadataProviderType, query string, err error) {
-	idx := e.findFailureIndexLocked(item.Key, providerType)
+// SearchCandidates lists up to limit search results from the provider behind a
+// failure so the user can pick the right match. The query defaults to the
+// failure's last search term. Seasons and episodes search for their show.
+func (e *MetadataEngine) SearchCandidates(ctx context.Context, key, providerName, query string, limit int) ([]provider.Candidate, error) {
+	e.failuresMu.Lock()
+	idx := e.findFailureIndexLocked(key, providerName)
+	if idx < 0 {
+		e.failuresMu.Unlock()
+		return nil, fmt.Errorf("metadata failure for %s/%s not found", providerName, key)
+	}
+	failure := e.failures[idx]
+	e.failuresMu.Unlock()
+
+	src, err := e.sourceFor(providerName)
+	if err != nil {
+		return nil, err
+	}
+	searcher, ok := src.provider.(provider.CandidateSearcher)
+	if !ok {
+		return nil, fmt.Errorf("%s provider does not support candidate search", providerName)
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		query = strings.TrimSpace(failure.Query)
+	}
+	if query == "" {
+		query = failure.Item.Name
+	}
+
+	mediaType := provider.MediaTypeShow
+	if failure.Item.IsMovie {
+		mediaType = provider.MediaTypeMovie
+	}
+	return searcher.SearchCandidates(ctx, provider.FetchRequest{
+		MediaType: mediaType,
+		Name:      query,
+		Year:      failure.Item.Year,
+	}, limit)
+}
+
+// sourceFor returns the enabled search provider for a manual retry target.
+func (e *MetadataEngine) sourceFor(providerName string) (metadataSource, error) {
+	for _, src := range e.sources {
+		if src.name == providerName && !src.requiresFile {
+			return src, nil
+		}
+	}
+	return metadataSource{}, fmt.Errorf("%s provider not configured", providerName)
+}
+
+func (e *MetadataEngine) updateFailureLocked(item MetadataItem, providerName, query string, err error) {
+	idx := e.findFailureIndexLocked(item.Key, providerName)
 	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		if idx >= 0 {
 			e.failures = append(e.failures[:idx], e.failures[idx+1:]...)
@@ -621,8 +776,9 @@ func (e *MetadataEngine) updateFailureLocked(item MetadataItem, providerType Met
 
 	var provErr *provider.ProviderError
 	if errors.As(err, &provErr) {
-		// Only treat search-not-found scenarios as retryable in the manual UI.
-		if provErr.Code != "NOT_FOUND" {
+		// Only treat search-not-found and low-confidence matches as retryable
+		// in the manual UI.
+		if provErr.Code != "NOT_FOUND" && provErr.Code != lowConfidenceCode {
 			if idx >= 0 {
 				e.failures = append(e.failures[:idx], e.failures[idx+1:]...)
 			}
@@ -630,11 +786,18 @@ func (e *MetadataEngine) updateFailureLocked(item MetadataItem, providerType Met
 		}
 	}
 
+	searchAttempts := provider.SearchAttemptsFromError(err)
+	if len(searchAttempts) == 0 {
+		searchAttempts = []provider.SearchAttempt{{Strategy: provider.SearchStrategyOriginal, Name: query, Year: item.Year}}
+	}
+
 	if idx >= 0 {
 		existing := e.failures[idx]
 		existing.Err = err
 		existing.Query = query
 		existing.Item = item
+		existing.ParsedName = item.Name
+		existing.SearchAttempts = searchAttempts
 		if existing.Attempts == 0 {
 			existing.Attempts = 1
 		}
@@ -643,78 +806,50 @@ func (e *MetadataEngine) updateFailureLocked(item MetadataItem, providerType Met
 	}
 
 	failure := MetadataFailure{
-		Item:     item,
-		Provider: providerType,
-		Query:    query,
-		Err:      err,
-		Attempts: 1,
+		Item:           item,
+		ParsedName:     item.Name,
+		Provider:       providerName,
+		Query:          query,
+		Err:            err,
+		Attempts:       1,
+		SearchAttempts: searchAttempts,
 	}
 	e.failures = append(e.failures, failure)
 }
 
-func (e *MetadataEngine) findFailureIndexLocked(key string, providerType MetadataProviderType) int {
+func (e *MetadataEngine) findFailureIndexLocked(key, providerName string) int {
 	for idx, failure := range e.failures {
-		if failure.Item.Key == key && failure.Provider == providerType {
+		if failure.Item.Key == key && failure.Provider == providerName {
 			return idx
 		}
 	}
 	return -1
 }
 
-func (e *MetadataEngine) applyManualMetadata(item MetadataItem, providerType MetadataProviderType, meta *provider.Metadata) {
+// applyManualMetadata merges a manually resolved result into the stored
+// metadata. Each field goes to whichever of the new result and the provider
+// already credited with it ranks higher; the new result wins ties.
+func (e *MetadataEngine) applyManualMetadata(item MetadataItem, providerName string, meta *provider.Metadata) {
 	if meta == nil {
 		return
 	}
 
 	existing, _ := e.metadata.Load(item.Key)
+	combined := mergeSourced(item, e.ranker(), []sourcedMetadata{
+		{provider: providerName, meta: meta},
+		{meta: existing},
+	})
+	e.metadata.Store(item.Key, combined)
+}
 
-	switch providerType {
-	case MetadataProviderTMDB:
-		extra := make([]*provider.Metadata, 0, 1)
-		if existing != nil {
-			extra = append(extra, existing)
-		}
-		combined := MergeMetadata(item, meta, extra...)
-		if combined != nil {
-			e.metadata.Store(item.Key, combined)
-		}
-	case MetadataProviderOMDB:
-		if existing != nil {
-			combined := MergeMetadata(item, existing, meta)
-			if combined != nil {
-				e.metadata.Store(item.Key, combined)
-			}
-			return
-		}
-		combined := MergeMetadata(item, meta)
-		if combined != nil {
-			e.metadata.Store(item.Key, combined)
-		}
-	case MetadataProviderTVDB:
-		if existing != nil {
-			combined := MergeMetadata(item, existing, meta)
-			if combined != nil {
-				e.metadata.Store(item.Key, combined)
-			}
-			return
-		}
-		combined := MergeMetadata(item, meta)
-		if combined != nil {
-			e.metadata.Store(item.Key, combined)
-		}
-	default:
-		if existing != nil {
-			combined := MergeMetadata(item, existing, meta)
-			if combined != nil {
-				e.metadata.Store(item.Key, combined)
-			}
-			return
-		}
-		combined := MergeMetadata(item, meta)
-		if combined != nil {
-			e.metadata.Store(item.Key, combined)
-		}
+// ranker orders providers per field: configured precedence first, then
+// registry priority.
+func (e *MetadataEngine) ranker() fieldRanker {
+	priority := make([]string, 0, len(e.sources))
+	for _, src := range e.sources {
+		priority = append(priority, src.name)
 	}
+	return fieldRanker{precedence: e.precedence, priority: priority}
 }
 
 func (e *MetadataEngine) emit(ctx context.Context, events chan<- MetadataEvent, err error) {
@@ -750,52 +885,65 @@ func phaseName(phase int) string {
 	}
 }
 
-func (e *MetadataEngine) fetchTMDBMetadata(ctx context.Context, item MetadataItem) (*provider.Metadata, error) {
-	if e.tmdbProvider == nil {
+// fetchSource queries one provider for an item. Pinned IDs from the override
+// store skip the search, episodes come from their season's batch when the
+// provider has one, and weak search matches become failures.
+func (e *MetadataEngine) fetchSource(ctx context.Context, src metadataSource, item MetadataItem) (*provider.Metadata, error) {
+	if !src.supports(item) {
 		return nil, nil
 	}
-	return FetchTMDBMetadata(ctx, e.tmdbProvider, e.metadataCache(), item)
-}
-
-func (e *MetadataEngine) fetchOMDBMetadata(ctx context.Context, item MetadataItem) (*provider.Metadata, error) {
-	if e.omdbProvider == nil {
-		return nil, nil
+	if src.requiresFile {
+		return FetchFFProbeMetadata(ctx, src.provider, item)
 	}
-	return FetchOMDBMetadata(ctx, e.omdbProvider, item, e.metadataCache())
-}
-
-func (e *MetadataEngine) fetchTVDBMetadata(ctx context.Context, item MetadataItem) (*provider.Metadata, error) {
-	if e.tvdbProvider == nil {
-		return nil, nil
+	if id := e.overrideID(item, src.name); id != "" {
+		return FetchMetadataByID(ctx, src.provider, item, id)
 	}
-	return FetchTVDBMetadata(ctx, e.tvdbProvider, item, e.metadataCache())
+	if meta, ok := e.batchedEpisode(ctx, src, item); ok {
+		return e.checkConfidence(src.provider, meta, nil)
+	}
+	meta, err := FetchProviderMetadata(ctx, src.provider, e.showCache(src.name), item, e.searchStrategies...)
+	return e.checkConfidence(src.provider, meta, err)
 }
 
-func (e *MetadataEngine) fetchFFProbeMetadata(ctx context.Context, item MetadataItem) (*provider.Metadata, error) {
-	if e.ffprobeProvider == nil {
-		return nil, nil
+// lowConfidenceCode marks provider matches that scored below the engine's
+// match threshold.
+const lowConfidenceCode = "LOW_CONFIDENCE"
+
+// checkConfidence turns a match scoring below the configured threshold into a
+// failure so it is reviewed manually rather than applied silently. Manual
+// retries skip this check because the user chose the query.
+func (e *MetadataEngine) checkConfidence(prov provider.Provider, meta *provider.Metadata, err error) (*provider.Metadata, error) {
+	if err != nil || meta == nil || e.matchThreshold <= 0 || meta.Confidence >= e.matchThreshold {
+		return meta, err
+	}
+	return nil, &provider.ProviderError{
+		Provider: prov.Name(),
+		Code:     lowConfidenceCode,
+		Message:  fmt.Sprintf("best match %q scored %.2f, below the %.2f threshold", meta.Core.Title, meta.Confidence, e.matchThreshold),
+		Retry:    false,
 	}
-	return FetchFFProbeMetadata(ctx, e.ffprobeProvider, item)
 }
 
-func (e *MetadataEngine) metadataCache() provider.MetadataCache {
-	return metadataCacheAdapter{engine: e}
+// showCache returns the cache of show lookups made through the named
+// source. It is kept apart from the merged metadata, so a show looked up for
+// its seasons never lands in the results without its own confidence check.
+func (e *MetadataEngine) showCache(source string) provider.MetadataCache {
+	return showLookupCache{engine: e, source: source}
 }
 
-type metadataCacheAdapter struct {
+type showLookupCache struct {
 	engine *MetadataEngine
+	source string
 }
 
-func (c metadataCacheAdapter) Get(key string) (*provider.Metadata, bool) {
-	if c.engine == nil || c.engine.metadata == nil {
-		return nil, false
+func (c showLookupCache) Get(key string) (provider.ShowLookup, bool) {
+	value, ok := c.engine.showLookups.Load(c.source + "|" + key)
+	if !ok {
+		return provider.ShowLookup{}, false
 	}
-	return c.engine.metadata.Load(key)
+	return value.(provider.ShowLookup), true
 }
 
-func (c metadataCacheAdapter) Set(key string, meta *provider.Metadata) {
-	if c.engine == nil || c.engine.metadata == nil || meta == nil {
-		return
-	}
-	c.engine.metadata.Store(key, meta)
+func (c showLookupCache) Set(key string, lookup provider.ShowLookup) {
+	c.engine.showLookups.Store(c.source+"|"+key, lookup)
 }
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
@@ -33,6 +35,11 @@ func (retryTestProvider) Fetch(ctx context.Context, req provider.FetchRequest) (
 	return nil, &provider.ProviderError{Provider: "retry-test", Code: "NOT_FOUND", Message: "not found", Retry: false}
 }
 
+// sourcesFor wraps a single provider as the engine's only metadata source.
+func sourcesFor(name string, prov provider.Provider) []metadataSource {
+	return []metadataSource{newMetadataSource(name, prov)}
+}
+
 func TestMetadataEngineRetryProviderClearsFailures(t *testing.T) {
 	t.Parallel()
 
@@ -46,25 +53,25 @@ func TestMetadataEngineRetryProviderClearsFailures(t *testing.T) {
 	notFound := &provider.ProviderError{Provider: "retry-test", Code: "NOT_FOUND", Message: "missing", Retry: false}
 
 	engine := &MetadataEngine{
-		tmdbProvider: retryTestProvider{},
-		metadata:     csmap.Create[string, *provider.Metadata](),
+		sources:  sourcesFor("tmdb", retryTestProvider{}),
+		metadata: csmap.Create[string, *provider.Metadata](),
 	}
 
 	engine.processResult(MetadataResult{
-		Item:    item,
-		Errs:    []error{notFound},
-		TMDBErr: notFound,
+		Item:         item,
+		Errs:         []error{notFound},
+		ProviderErrs: map[string]error{"tmdb": notFound},
 	})
 
 	failures := engine.ProviderFailures()
 	if diff := cmp.Diff(1, len(failures)); diff != "" {
 		t.Fatalf("ProviderFailures length mismatch (-want +got):\n%s", diff)
 	}
-	if failures[0].Provider != MetadataProviderTMDB {
+	if failures[0].Provider != "tmdb" {
 		t.Fatalf("failure provider = %s, want TMDB", failures[0].Provider)
 	}
 
-	result, err := engine.RetryProvider(context.Background(), item.Key, MetadataProviderTMDB, "Manual Success")
+	result, err := engine.RetryProvider(context.Background(), item.Key, "tmdb", "Manual Success", "")
 	if err != nil {
 		t.Fatalf("RetryProvider() unexpected error: %v", err)
 	}
@@ -101,13 +108,14 @@ func TestMetadataEngineNonRetryableErrorsNotTracked(t *testing.T) {
 	authErr := &provider.ProviderError{Provider: "retry-test", Code: "AUTH_FAILED", Message: "bad key", Retry: false}
 
 	engine := &MetadataEngine{
+		sources:  sourcesFor("tmdb", retryTestProvider{}),
 		metadata: csmap.Create[string, *provider.Metadata](),
 	}
 
 	engine.processResult(MetadataResult{
-		Item:    item,
-		Errs:    []error{authErr},
-		TMDBErr: authErr,
+		Item:         item,
+		Errs:         []error{authErr},
+		ProviderErrs: map[string]error{"tmdb": authErr},
 	})
 
 	if diff := cmp.Diff(0, len(engine.ProviderFailures())); diff != "" {
@@ -118,3 +126,239 @@ func TestMetadataEngineNonRetryableErrorsNotTracked(t *testing.T) {
 		t.Errorf("summary.ErrorCount mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestFetchProviderMetadataReturnsRateLimits(t *testing.T) {
+	t.Parallel()
+
+	calls := &atomic.Int32{}
+	rateLimited := fakeProvider{fetch: func(provider.FetchRequest) (*provider.Metadata, error) {
+		calls.Add(1)
+		return nil, &provider.ProviderError{Provider: "fake", Code: "RATE_LIMITED", Message: "slow down", Retry: true}
+	}}
+	item := MetadataItem{Name: "Manual Movie", Year: "2022", IsMovie: true, MediaType: provider.MediaTypeMovie}
+
+	// The provider's Resilient wrapper already waited, so the engine doesn't
+	// wait again
+	_, err := FetchProviderMetadata(context.Background(), rateLimited, nil, item)
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "RATE_LIMITED" {
+		t.Fatalf("FetchProviderMetadata() error = %v, want RATE_LIMITED", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Fetch called %d times, want 1", got)
+	}
+}
+
+func TestMetadataEngineFailureRecordsSearchAttempts(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{
+		Name:      "The Manual Movie",
+		Year:      "2022",
+		IsMovie:   true,
+		MediaType: provider.MediaTypeMovie,
+		Key:       provider.GenerateMetadataKey("movie", "The Manual Movie", "2022", 0, 0),
+	}
+
+	engine := &MetadataEngine{
+		sources:          sourcesFor("tmdb", retryTestProvider{}),
+		searchStrategies: []provider.SearchStrategy{provider.SearchStrategyDropYear, provider.SearchStrategyStripArticle},
+		metadata:         csmap.Create[string, *provider.Metadata](),
+	}
+
+	_, err := engine.fetchSource(context.Background(), engine.sources[0], item)
+	engine.processResult(MetadataResult{
+		Item:         item,
+		Errs:         []error{err},
+		ProviderErrs: map[string]error{"tmdb": err},
+	})
+
+	failures := engine.ProviderFailures()
+	if diff := cmp.Diff(1, len(failures)); diff != "" {
+		t.Fatalf("ProviderFailures length mismatch (-want +got):\n%s", diff)
+	}
+	want := []provider.SearchAttempt{
+		{Strategy: provider.SearchStrategyOriginal, Name: "The Manual Movie", Year: "2022"},
+		{Strategy: provider.SearchStrategyDropYear, Name: "The Manual Movie"},
+		{Strategy: provider.SearchStrategyStripArticle, Name: "Manual Movie"},
+	}
+	if diff := cmp.Diff(want, failures[0].SearchAttempts); diff != "" {
+		t.Errorf("SearchAttempts mismatch (-want +got):\n%s", diff)
+	}
+
+	retried, err := engine.RetryProvider(context.Background(), item.Key, "tmdb", "Still Missing", "")
+	if err != nil {
+		t.Fatalf("RetryProvider() unexpected error: %v", err)
+	}
+	last := retried.SearchAttempts[len(retried.SearchAttempts)-1]
+	wantLast := provider.SearchAttempt{Strategy: provider.SearchStrategyManual, Name: "Still Missing", Year: "2022"}
+	if diff := cmp.Diff(wantLast, last); diff != "" {
+		t.Errorf("manual attempt mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type confidenceTestProvider struct {
+	retryTestProvider
+	confidence float64
+}
+
+func (p confidenceTestProvider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{MediaTypes: []provider.MediaType{
+		provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode,
+	}}
+}
+
+func (p confidenceTestProvider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	_ = ctx
+	confidence := p.confidence
+	if req.MediaType == provider.MediaTypeEpisode {
+		confidence = 1
+	}
+	return &provider.Metadata{Core: provider.CoreMetadata{Title: "Heathers", MediaType: req.MediaType}, Confidence: confidence}, nil
+}
+
+func TestMetadataEngineQueuesLowConfidenceMatches(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{
+		Name:      "Heat",
+		Year:      "1995",
+		IsMovie:   true,
+		MediaType: provider.MediaTypeMovie,
+		Key:       provider.GenerateMetadataKey("movie", "Heat", "1995", 0, 0),
+	}
+
+	engine := &MetadataEngine{
+		sources:        sourcesFor("tmdb", confidenceTestProvider{confidence: 0.45}),
+		matchThreshold: provider.DefaultMatchThreshold,
+		metadata:       csmap.Create[string, *provider.Metadata](),
+	}
+
+	meta, err := engine.fetchSource(context.Background(), engine.sources[0], item)
+	if meta != nil {
+		t.Fatalf("fetchSource() returned %v, want nil below threshold", meta)
+	}
+	engine.processResult(MetadataResult{
+		Item:         item,
+		Errs:         []error{err},
+		ProviderErrs: map[string]error{"tmdb": err},
+	})
+
+	failures := engine.ProviderFailures()
+	if diff := cmp.Diff(1, len(failures)); diff != "" {
+		t.Fatalf("ProviderFailures length mismatch (-want +got):\n%s", diff)
+	}
+	if _, stored := engine.Metadata()[item.Key]; stored {
+		t.Errorf("Metadata()[%q] stored, want low confidence match withheld", item.Key)
+	}
+
+	engine.sources = sourcesFor("tmdb", confidenceTestProvider{confidence: 0.9})
+	if meta, err := engine.fetchSource(context.Background(), engine.sources[0], item); err != nil || meta == nil {
+		t.Errorf("fetchSource() = (%v, %v), want metadata above threshold", meta, err)
+	}
+}
+
+func TestMetadataEngineWithholdsLowConfidenceShowOfEpisode(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{
+		Name:      "Lost",
+		Year:      "2004",
+		Season:    1,
+		Episode:   2,
+		MediaType: provider.MediaTypeEpisode,
+		Key:       provider.GenerateMetadataKey("episode", "Lost", "2004", 1, 2),
+	}
+	engine := &MetadataEngine{
+		sources:        sourcesFor("tmdb", confidenceTestProvider{confidence: 0.3}),
+		matchThreshold: 0.6,
+		metadata:       csmap.Create[string, *provider.Metadata](),
+	}
+
+	// The episode inherits the weak show match and is queued for review
+	if meta, err := engine.fetchSource(context.Background(), engine.sources[0], item); meta != nil || err == nil {
+		t.Errorf("fetchSource() = (%v, %v), want a low confidence failure", meta, err)
+	}
+	showKey := provider.GenerateMetadataKey("show", "Lost", "2004", 0, 0)
+	if _, stored := engine.Metadata()[showKey]; stored {
+		t.Errorf("Metadata()[%q] stored, want the show looked up for the episode withheld", showKey)
+	}
+}
+
+type candidateTestProvider struct {
+	retryTestProvider
+	requests []provider.FetchRequest
+}
+
+func (p *candidateTestProvider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	p.requests = append(p.requests, req)
+	if req.ID == "603" {
+		return &provider.Metadata{Core: provider.CoreMetadata{Title: "The Matrix", Year: "1999", MediaType: req.MediaType}, Confidence: 1.0}, nil
+	}
+	return retryTestProvider{}.Fetch(ctx, req)
+}
+
+func (p *candidateTestProvider) SearchCandidates(_ context.Context, req provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	candidates := []provider.Candidate{
+		{ID: "604", Title: "The Matrix Reloaded", Year: "2003", MediaType: req.MediaType},
+		{ID: "603", Title: "The Matrix", Year: "1999", MediaType: req.MediaType},
+	}
+	return provider.RankCandidates(req.Name, req.Year, req.MediaType, candidates, limit), nil
+}
+
+func TestMetadataEngineRetryProviderWithCandidateID(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{
+		Name:      "Matrix",
+		Year:      "1999",
+		IsMovie:   true,
+		MediaType: provider.MediaTypeMovie,
+		Key:       provider.GenerateMetadataKey("movie", "Matrix", "1999", 0, 0),
+	}
+	notFound := &provider.ProviderError{Provider: "retry-test", Code: "NOT_FOUND", Message: "missing", Retry: false}
+	prov := &candidateTestProvider{}
+
+	engine := &MetadataEngine{
+		sources:  sourcesFor("tmdb", prov),
+		metadata: csmap.Create[string, *provider.Metadata](),
+	}
+	engine.processResult(MetadataResult{Item: item, Errs: []error{notFound}, ProviderErrs: map[string]error{"tmdb": notFound}})
+
+	candidates, err := engine.SearchCandidates(context.Background(), item.Key, "tmdb", "The Matrix", 1)
+	if err != nil {
+		t.Fatalf("SearchCandidates() unexpected error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "603" {
+		t.Fatalf("SearchCandidates() = %+v, want only the best match 603", candidates)
+	}
+
+	result, err := engine.RetryProvider(context.Background(), item.Key, "tmdb", "The Matrix", candidates[0].ID)
+	if err != nil || result != nil {
+		t.Fatalf("RetryProvider() = (%v, %v), want resolved", result, err)
+	}
+
+	want := provider.FetchRequest{MediaType: provider.MediaTypeMovie, ID: "603", Name: "The Matrix", Year: "1999"}
+	if diff := cmp.Diff(want, prov.requests[len(prov.requests)-1]); diff != "" {
+		t.Errorf("exact ID request mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("The Matrix", engine.Metadata()[item.Key].Core.Title); diff != "" {
+		t.Errorf("metadata title mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetadataEngineSearchCandidatesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{Name: "Show", MediaType: provider.MediaTypeShow, Key: provider.GenerateMetadataKey("show", "Show", "", 0, 0)}
+	notFound := &provider.ProviderError{Provider: "retry-test", Code: "NOT_FOUND", Message: "missing", Retry: false}
+	engine := &MetadataEngine{
+		sources:  sourcesFor("tmdb", retryTestProvider{}),
+		metadata: csmap.Create[string, *provider.Metadata](),
+	}
+	engine.processResult(MetadataResult{Item: item, Errs: []error{notFound}, ProviderErrs: map[string]error{"tmdb": notFound}})
+
+	if _, err := engine.SearchCandidates(context.Background(), item.Key, "tmdb", "", 5); err == nil {
+		t.Error("SearchCandidates() error = nil, want unsupported provider error")
+	}
+}
@@ -0,0 +1,471 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+	"github.com/Digital-Shane/treeview/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeProvider is the configurable provider of the engine tests. Fetch
+// answers through fetch, or with NOT_FOUND when fetch is nil, and season
+// batches come from seasons. caps and variables replace the defaults when
+// set.
+type fakeProvider struct {
+	retryTestProvider
+	caps       *provider.ProviderCapabilities
+	variables  []provider.TemplateVariable
+	seasonVars []string
+	fetch      func(provider.FetchRequest) (*provider.Metadata, error)
+	seasons    func(provider.FetchRequest) (map[int]*provider.Metadata, error)
+}
+
+func (p fakeProvider) Capabilities() provider.ProviderCapabilities {
+	if p.caps != nil {
+		return *p.caps
+	}
+	return p.retryTestProvider.Capabilities()
+}
+
+func (p fakeProvider) SupportedVariables() []provider.TemplateVariable { return p.variables }
+
+func (p fakeProvider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	if p.fetch == nil {
+		return nil, &provider.ProviderError{Provider: "fake", Code: "NOT_FOUND", Message: "not found", Retry: false}
+	}
+	return p.fetch(req)
+}
+
+func (p fakeProvider) FetchSeasonEpisodes(ctx context.Context, req provider.FetchRequest) (map[int]*provider.Metadata, error) {
+	if p.seasons == nil {
+		return nil, nil
+	}
+	return p.seasons(req)
+}
+
+func (p fakeProvider) SeasonEpisodeVariables() []string { return p.seasonVars }
+
+// titled answers every lookup with title.
+func titled(title string) func(provider.FetchRequest) (*provider.Metadata, error) {
+	return func(req provider.FetchRequest) (*provider.Metadata, error) {
+		return &provider.Metadata{Core: provider.CoreMetadata{Title: title, MediaType: req.MediaType}, Confidence: 1.0}, nil
+	}
+}
+
+// fileCaps are the capabilities of a provider that reads movie files.
+var fileCaps = &provider.ProviderCapabilities{MediaTypes: []provider.MediaType{provider.MediaTypeMovie}, RequiresFile: true}
+
+// registryEntry is a provider for newTestRegistry.
+type registryEntry struct {
+	name     string
+	prov     provider.Provider
+	priority int
+	disabled bool
+}
+
+// newTestRegistry registers and enables the entries, along with the local
+// parser, in a new registry.
+func newTestRegistry(t *testing.T, entries ...registryEntry) *provider.Registry {
+	t.Helper()
+	reg := provider.NewRegistry()
+	for _, entry := range append([]registryEntry{{name: "local", prov: local.New()}}, entries...) {
+		if err := reg.Register(entry.name, entry.prov, entry.priority); err != nil {
+			t.Fatalf("Register(%s) unexpected error: %v", entry.name, err)
+		}
+		if entry.disabled {
+			continue
+		}
+		if err := reg.Enable(entry.name); err != nil {
+			t.Fatalf("Enable(%s) unexpected error: %v", entry.name, err)
+		}
+	}
+	return reg
+}
+
+// movieTree builds a library of movie folders, each holding a video named
+// like the folder.
+func movieTree(names ...string) *treeview.Tree[treeview.FileInfo] {
+	var nodes []*treeview.Node[treeview.FileInfo]
+	for _, name := range names {
+		dirPath := filepath.Join("/library", name)
+		filePath := filepath.Join(dirPath, name+".mkv")
+		dir := treeview.NewNode(dirPath, name, treeview.FileInfo{FileInfo: NewSimpleFileInfo(name, true), Path: dirPath})
+		dir.AddChild(treeview.NewNode(filePath, name+".mkv", treeview.FileInfo{FileInfo: NewSimpleFileInfo(name+".mkv", false), Path: filePath}))
+		nodes = append(nodes, dir)
+	}
+	tree := &treeview.Tree[treeview.FileInfo]{}
+	tree.SetNodes(nodes)
+	return tree
+}
+
+// heatTree is a library holding Heat (1995).
+func heatTree() *treeview.Tree[treeview.FileInfo] {
+	return movieTree("Heat (1995)")
+}
+
+func TestMetadataEngineUsesRegistryPriority(t *testing.T) {
+	t.Parallel()
+
+	reg := newTestRegistry(t,
+		registryEntry{name: "secondary", prov: fakeProvider{fetch: titled("Heat (secondary)")}, priority: 50},
+		registryEntry{name: "primary", prov: fakeProvider{}, priority: 100},
+		registryEntry{name: "disabled", prov: fakeProvider{fetch: titled("Heat (disabled)")}, priority: 200, disabled: true},
+	)
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: heatTree(), WorkerCount: 1, Registry: reg})
+	var names []string
+	for _, src := range engine.sources {
+		names = append(names, src.name)
+	}
+	if diff := cmp.Diff([]string{"primary", "secondary"}, names); diff != "" {
+		t.Fatalf("sources mismatch (-want +got):\n%s", diff)
+	}
+
+	for range engine.Start(context.Background()) {
+	}
+
+	// The failing higher priority provider leaves the next one as the base.
+	metadata := engine.Metadata()
+	if len(metadata) == 0 {
+		t.Fatalf("Metadata() is empty, want a result from secondary")
+	}
+	for key, meta := range metadata {
+		if meta.Core.Title != "Heat (secondary)" {
+			t.Errorf("Metadata()[%q].Title = %q, want Heat (secondary)", key, meta.Core.Title)
+		}
+	}
+
+	failures := engine.ProviderFailures()
+	if len(failures) == 0 {
+		t.Fatalf("ProviderFailures() is empty, want a primary failure")
+	}
+	for _, failure := range failures {
+		if failure.Provider != "primary" {
+			t.Errorf("failure.Provider = %q, want primary", failure.Provider)
+		}
+	}
+}
+
+func TestMetadataEngineOffline(t *testing.T) {
+	t.Parallel()
+
+	cache, err := provider.OpenDiskCache(filepath.Join(t.TempDir(), "metadata.json"))
+	if err != nil {
+		t.Fatalf("OpenDiskCache() unexpected error: %v", err)
+	}
+	heat := provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Heat", Year: "1995"}
+	cache.Set("retry-test", provider.CacheKey(heat), &provider.Metadata{
+		Core:       provider.CoreMetadata{Title: "Heat (cached)", MediaType: provider.MediaTypeMovie},
+		Confidence: 1.0,
+	})
+
+	// The cached provider would answer every lookup if it were asked; the
+	// plain one has no cache and can't be used offline.
+	cached := provider.NewResilient(fakeProvider{fetch: titled("Heat (network)")}, provider.Policy{})
+	cached.SetCache(cache)
+
+	reg := newTestRegistry(t,
+		registryEntry{name: "cached", prov: cached, priority: 100},
+		registryEntry{name: "online", prov: fakeProvider{fetch: titled("Heat (online)")}, priority: 90},
+	)
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: movieTree("Heat (1995)", "Ronin (1998)"), WorkerCount: 1, Registry: reg, Offline: true})
+	if diff := cmp.Diff([]string{"retry-test"}, engine.SummarySnapshot().ActiveProviders); diff != "" {
+		t.Fatalf("active providers mismatch (-want +got):\n%s", diff)
+	}
+
+	for range engine.Start(context.Background()) {
+	}
+
+	var titles []string
+	for _, meta := range engine.Metadata() {
+		titles = append(titles, meta.Core.Title)
+	}
+	if diff := cmp.Diff([]string{"Heat (cached)"}, titles); diff != "" {
+		t.Errorf("Metadata() titles mismatch (-want +got):\n%s", diff)
+	}
+
+	summary := engine.SummarySnapshot()
+	if !summary.Offline || summary.Unenriched != 1 {
+		t.Errorf("summary Offline = %v, Unenriched = %d, want true and 1", summary.Offline, summary.Unenriched)
+	}
+	if errs := engine.Errors(); len(errs) != 0 {
+		t.Errorf("Errors() = %v, want cache misses ignored", errs)
+	}
+	if failures := engine.ProviderFailures(); len(failures) != 0 {
+		t.Errorf("ProviderFailures() = %d, want none offline", len(failures))
+	}
+}
+
+// recordingIDs answers with ids and sends the IDs other providers passed on
+// to seen.
+func recordingIDs(ids map[string]string, seen chan<- map[string]string) func(provider.FetchRequest) (*provider.Metadata, error) {
+	return func(req provider.FetchRequest) (*provider.Metadata, error) {
+		seen <- req.KnownIDs()
+		return &provider.Metadata{Core: provider.CoreMetadata{Title: req.Name, MediaType: req.MediaType}, IDs: ids, Confidence: 1.0}, nil
+	}
+}
+
+func TestMetadataEnginePropagatesIDs(t *testing.T) {
+	t.Parallel()
+
+	primary, secondary, last := make(chan map[string]string, 4), make(chan map[string]string, 4), make(chan map[string]string, 4)
+	reg := newTestRegistry(t,
+		registryEntry{name: "primary", prov: fakeProvider{fetch: recordingIDs(map[string]string{"imdb_id": "tt0113277", "tmdb_id": "949"}, primary)}, priority: 100},
+		registryEntry{name: "secondary", prov: fakeProvider{fetch: recordingIDs(map[string]string{"imdb_id": "tt0000000", "tvdb_id": "1"}, secondary)}, priority: 90},
+		registryEntry{name: "last", prov: fakeProvider{fetch: recordingIDs(nil, last)}, priority: 80},
+	)
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: heatTree(), WorkerCount: 1, Registry: reg})
+	for range engine.Start(context.Background()) {
+	}
+
+	if got := <-primary; len(got) != 0 {
+		t.Errorf("primary got known IDs %v, want none", got)
+	}
+	if diff := cmp.Diff(map[string]string{"imdb_id": "tt0113277", "tmdb_id": "949"}, <-secondary); diff != "" {
+		t.Errorf("secondary known IDs mismatch (-want +got):\n%s", diff)
+	}
+	// Higher priority IDs win over conflicting ones found later.
+	if diff := cmp.Diff(map[string]string{"imdb_id": "tt0113277", "tmdb_id": "949", "tvdb_id": "1"}, <-last); diff != "" {
+		t.Errorf("last known IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// seasonBatchProvider fetches seasons whole and counts the requests it gets.
+// Its season payload has episode titles but no directors.
+func seasonBatchProvider(batches, episodes *atomic.Int32) fakeProvider {
+	episode := []provider.MediaType{provider.MediaTypeEpisode}
+	return fakeProvider{
+		caps:       &provider.ProviderCapabilities{MediaTypes: []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeEpisode}},
+		variables:  []provider.TemplateVariable{{Name: "episode_title", MediaTypes: episode}, {Name: "director", MediaTypes: episode}},
+		seasonVars: []string{"episode_title"},
+		fetch: func(req provider.FetchRequest) (*provider.Metadata, error) {
+			if req.MediaType == provider.MediaTypeEpisode {
+				episodes.Add(1)
+				return &provider.Metadata{Core: provider.CoreMetadata{Title: req.Name, EpisodeName: "Single", MediaType: req.MediaType}}, nil
+			}
+			return &provider.Metadata{Core: provider.CoreMetadata{Title: req.Name, MediaType: req.MediaType}, IDs: map[string]string{"tmdb_id": "4607"}}, nil
+		},
+		seasons: func(req provider.FetchRequest) (map[int]*provider.Metadata, error) {
+			batches.Add(1)
+			return map[int]*provider.Metadata{
+				1: {Core: provider.CoreMetadata{Title: req.Name, EpisodeName: "Pilot (1)", MediaType: provider.MediaTypeEpisode}},
+				2: {Core: provider.CoreMetadata{Title: req.Name, EpisodeName: "Pilot (2)", MediaType: provider.MediaTypeEpisode}},
+			}, nil
+		},
+	}
+}
+
+func TestMetadataEngineBatchesSeasons(t *testing.T) {
+	t.Parallel()
+
+	showPath := filepath.Join("/library", "Lost (2004)")
+	seasonPath := filepath.Join(showPath, "Season 01")
+	showNode := treeview.NewNode(showPath, "Lost (2004)", treeview.FileInfo{FileInfo: NewSimpleFileInfo("Lost (2004)", true), Path: showPath})
+	seasonNode := treeview.NewNode(seasonPath, "Season 01", treeview.FileInfo{FileInfo: NewSimpleFileInfo("Season 01", true), Path: seasonPath})
+	showNode.AddChild(seasonNode)
+	for _, name := range []string{"Lost S01E01.mkv", "Lost S01E02.mkv", "Lost S01E03.mkv"} {
+		path := filepath.Join(seasonPath, name)
+		seasonNode.AddChild(treeview.NewNode(path, name, treeview.FileInfo{FileInfo: NewSimpleFileInfo(name, false), Path: path}))
+	}
+	tree := &treeview.Tree[treeview.FileInfo]{}
+	tree.SetNodes([]*treeview.Node[treeview.FileInfo]{showNode})
+
+	tests := map[string]struct {
+		episodeVariables []string
+		wantBatches      int32
+		wantEpisodes     int32
+		wantTitles       []string
+	}{
+		"season payload has every variable used": {
+			episodeVariables: []string{"show", "episode_title"},
+			wantBatches:      1,
+			wantEpisodes:     1, // episode 3 is missing from the season payload
+			wantTitles:       []string{"Pilot (1)", "Pilot (2)", "Single"},
+		},
+		"template uses a variable the payload lacks": {
+			episodeVariables: nil,
+			wantEpisodes:     3,
+			wantTitles:       []string{"Single", "Single", "Single"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			batches, episodes := new(atomic.Int32), new(atomic.Int32)
+			reg := newTestRegistry(t, registryEntry{name: "batch", prov: seasonBatchProvider(batches, episodes), priority: 100})
+
+			engine := NewMetadataEngine(MetadataEngineConfig{Tree: tree, WorkerCount: 3, Registry: reg, EpisodeVariables: tc.episodeVariables})
+			for range engine.Start(context.Background()) {
+			}
+
+			if got := batches.Load(); got != tc.wantBatches {
+				t.Errorf("season batches = %d, want %d", got, tc.wantBatches)
+			}
+			if got := episodes.Load(); got != tc.wantEpisodes {
+				t.Errorf("single episode fetches = %d, want %d", got, tc.wantEpisodes)
+			}
+
+			var titles []string
+			for num := 1; num <= 3; num++ {
+				if meta := engine.Metadata()[provider.GenerateMetadataKey("episode", "Lost", "2004", 1, num)]; meta != nil {
+					titles = append(titles, meta.Core.EpisodeName)
+				}
+			}
+			if diff := cmp.Diff(tc.wantTitles, titles); diff != "" {
+				t.Errorf("episode titles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMetadataEngineLimitsProbes(t *testing.T) {
+	t.Parallel()
+
+	// The probe reads files like ffprobe and records how many probes overlap
+	running, peak := new(atomic.Int32), new(atomic.Int32)
+	probe := fakeProvider{caps: fileCaps, fetch: func(req provider.FetchRequest) (*provider.Metadata, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for top := peak.Load(); now > top && !peak.CompareAndSwap(top, now); top = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		return &provider.Metadata{
+			Core:     provider.CoreMetadata{Title: req.Name, MediaType: req.MediaType},
+			Extended: map[string]interface{}{"video_codec": "hevc"},
+		}, nil
+	}}
+	reg := newTestRegistry(t,
+		registryEntry{name: "network", prov: fakeProvider{fetch: titled("Network")}, priority: 100},
+		registryEntry{name: "probe", prov: probe, priority: 50},
+	)
+
+	var nodes []*treeview.Node[treeview.FileInfo]
+	for _, name := range []string{"Heat (1995)", "Ronin (1998)", "Thief (1981)", "Collateral (2004)"} {
+		filePath := filepath.Join("/library", name+".mkv")
+		nodes = append(nodes, treeview.NewNode(filePath, name+".mkv", treeview.FileInfo{FileInfo: NewSimpleFileInfo(name+".mkv", false), Path: filePath}))
+	}
+	tree := &treeview.Tree[treeview.FileInfo]{}
+	tree.SetNodes(nodes)
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: tree, WorkerCount: 4, Registry: reg, FFProbeWorkers: 1})
+	for range engine.Start(context.Background()) {
+	}
+
+	if got := peak.Load(); got != 1 {
+		t.Errorf("peak concurrent probes = %d, want 1", got)
+	}
+	metadata := engine.Metadata()
+	if len(metadata) != len(nodes) {
+		t.Fatalf("Metadata() has %d entries, want %d", len(metadata), len(nodes))
+	}
+	for key, meta := range metadata {
+		if meta.Core.Title != "Network" || meta.Extended["video_codec"] != "hevc" {
+			t.Errorf("Metadata()[%q] = %q with codec %v, want network title and probed codec", key, meta.Core.Title, meta.Extended["video_codec"])
+		}
+	}
+}
+
+// hashProvider identifies every file like a hash lookup, whatever its name,
+// under the capitalized title a subtitle database might list. It records the
+// path it read for each item name in paths.
+func hashProvider(mu *sync.Mutex, paths map[string]string) fakeProvider {
+	return fakeProvider{caps: fileCaps, fetch: func(req provider.FetchRequest) (*provider.Metadata, error) {
+		mu.Lock()
+		paths[req.Name], _ = req.Extra["path"].(string)
+		mu.Unlock()
+		return &provider.Metadata{
+			Core:       provider.CoreMetadata{Title: "HEAT", Year: "1995", MediaType: req.MediaType},
+			IDs:        map[string]string{"tmdb_id": "949"},
+			Confidence: 1.0,
+		}, nil
+	}}
+}
+
+// heatSearchProvider finds Heat by its name or a known TMDB ID and nothing
+// else.
+var heatSearchProvider = fakeProvider{fetch: func(req provider.FetchRequest) (*provider.Metadata, error) {
+	if req.Name != "Heat" && req.KnownID("tmdb_id") != "949" {
+		return nil, &provider.ProviderError{Provider: "fake", Code: "NOT_FOUND", Message: "not found", Retry: false}
+	}
+	return &provider.Metadata{
+		Core:       provider.CoreMetadata{Title: "Heat", Overview: "A heist crew.", MediaType: req.MediaType},
+		Confidence: 1.0,
+	}, nil
+}}
+
+func TestMetadataEngineFileSourceIdentifiesTitle(t *testing.T) {
+	t.Parallel()
+
+	reg := newTestRegistry(t,
+		registryEntry{name: "hash", prov: hashProvider(&sync.Mutex{}, map[string]string{}), priority: 75},
+		registryEntry{name: "search", prov: heatSearchProvider, priority: 100},
+	)
+
+	filePath := filepath.Join("/library", "DSC0001.mp4")
+	tree := &treeview.Tree[treeview.FileInfo]{}
+	tree.SetNodes([]*treeview.Node[treeview.FileInfo]{
+		treeview.NewNode(filePath, "DSC0001.mp4", treeview.FileInfo{FileInfo: NewSimpleFileInfo("DSC0001.mp4", false), Path: filePath}),
+	})
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: tree, WorkerCount: 1, Registry: reg})
+	for range engine.Start(context.Background()) {
+	}
+
+	// The search finds nothing by name, so it runs again with the hash
+	// result's ID and its title outranks the hash's
+	metadata := engine.Metadata()
+	if len(metadata) != 1 {
+		t.Fatalf("Metadata() has %d entries, want 1", len(metadata))
+	}
+	for _, meta := range metadata {
+		if meta.Core.Title != "Heat" || meta.Core.Overview != "A heist crew." {
+			t.Errorf("metadata = %q / %q, want Heat from both providers", meta.Core.Title, meta.Core.Overview)
+		}
+	}
+	if failures := engine.ProviderFailures(); len(failures) != 0 {
+		t.Errorf("ProviderFailures() = %v, want none", failures)
+	}
+}
+
+func TestMetadataEngineFileSourceRanksBelowSearch(t *testing.T) {
+	t.Parallel()
+
+	paths := map[string]string{}
+	reg := newTestRegistry(t,
+		registryEntry{name: "hash", prov: hashProvider(&sync.Mutex{}, paths), priority: 75},
+		registryEntry{name: "search", prov: heatSearchProvider, priority: 100},
+	)
+
+	tree := &treeview.Tree[treeview.FileInfo]{}
+	tree.SetNodes([]*treeview.Node[treeview.FileInfo]{
+		dirNode("Heat (1995)", sizedNode("trailer.mkv", 50<<20), sizedNode("DSC0001.mkv", 4<<30)),
+	})
+
+	engine := NewMetadataEngine(MetadataEngineConfig{Tree: tree, WorkerCount: 1, Registry: reg})
+	for range engine.Start(context.Background()) {
+	}
+
+	// The movie folder is hashed through its main video, and the title the
+	// folder's name found wins over the hash's
+	if got := paths["Heat"]; got != "/library/DSC0001.mkv" {
+		t.Errorf("hashed path for the folder = %q, want /library/DSC0001.mkv", got)
+	}
+	meta, ok := engine.Metadata()[provider.GenerateMetadataKey("movie", "Heat", "1995", 0, 0)]
+	if !ok {
+		t.Fatalf("Metadata() has no entry for the folder: %v", engine.Metadata())
+	}
+	if meta.Core.Title != "Heat" || meta.Core.Overview != "A heist crew." {
+		t.Errorf("metadata = %q / %q, want the search provider's Heat", meta.Core.Title, meta.Core.Overview)
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"slices"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// sourcedMetadata is a provider result tagged with the registry name of the
+// provider that returned it. Results without a provider name, such as
+// previously merged metadata, credit each field to its Sources entry.
+type sourcedMetadata struct {
+	provider string
+	meta     *provider.Metadata
+}
+
+// source returns the provider credited with field in this result.
+func (s sourcedMetadata) source(field string) string {
+	if s.provider != "" {
+		return s.provider
+	}
+	return s.meta.Sources[field]
+}
+
+// fieldRanker orders the providers competing for a metadata field. Providers
+// listed in precedence for the field come first, then the rest in priority
+// order. Unknown providers rank last; ties keep the order results were given.
+type fieldRanker struct {
+	precedence map[string][]string
+	priority   []string
+}
+
+func (r fieldRanker) rank(field, providerName string) int {
+	preferred := r.precedence[field]
+	if idx := slices.Index(preferred, providerName); idx >= 0 {
+		return idx
+	}
+	if idx := slices.Index(r.priority, providerName); idx >= 0 {
+		return len(preferred) + idx
+	}
+	return len(preferred) + len(r.priority)
+}
+
+// coreMergeField describes a CoreMetadata field merged by name. Names match
+// the template variables and the keys providers use in Sources.
+type coreMergeField struct {
+	name string
+	has  func(*provider.CoreMetadata) bool
+	copy func(dst, src *provider.CoreMetadata)
+}
+
+var coreMergeFields = []coreMergeField{
+	{"title", func(c *provider.CoreMetadata) bool { return c.Title != "" }, func(d, s *provider.CoreMetadata) { d.Title = s.Title }},
+	{"year", func(c *provider.CoreMetadata) bool { return c.Year != "" }, func(d, s *provider.CoreMetadata) { d.Year = s.Year }},
+	{"season", func(c *provider.CoreMetadata) bool { return c.SeasonNum > 0 }, func(d, s *provider.CoreMetadata) { d.SeasonNum = s.SeasonNum }},
+	{"episode", func(c *provider.CoreMetadata) bool { return c.EpisodeNum > 0 }, func(d, s *provider.CoreMetadata) { d.EpisodeNum = s.EpisodeNum }},
+	{"episode_title", func(c *provider.CoreMetadata) bool { return c.EpisodeName != "" }, func(d, s *provider.CoreMetadata) { d.EpisodeName = s.EpisodeName }},
+	{"overview", func(c *provider.CoreMetadata) bool { return c.Overview != "" }, func(d, s *provider.CoreMetadata) { d.Overview = s.Overview }},
+	{"rating", func(c *provider.CoreMetadata) bool { return c.Rating > 0 }, func(d, s *provider.CoreMetadata) { d.Rating = s.Rating }},
+	{"genres", func(c *provider.CoreMetadata) bool { return len(c.Genres) > 0 }, func(d, s *provider.CoreMetadata) { d.Genres = slices.Clone(s.Genres) }},
+	{"language", func(c *provider.CoreMetadata) bool { return c.Language != "" }, func(d, s *provider.CoreMetadata) { d.Language = s.Language }},
+	{"country", func(c *provider.CoreMetadata) bool { return c.Country != "" }, func(d, s *provider.CoreMetadata) { d.Country = s.Country }},
+}
+
+// mergeSourced combines provider results field by field. Each core field,
+// extended value, and ID is taken from the best ranked result that has it,
+// and the winning provider is recorded in Sources under the field name.
+// Fields no provider supplied fall back to the values parsed from disk.
+func mergeSourced(item MetadataItem, ranker fieldRanker, results []sourcedMetadata) *provider.Metadata {
+	present := make([]sourcedMetadata, 0, len(results))
+	for _, res := range results {
+		if res.meta != nil {
+			ensureMetadataMaps(res.meta)
+			present = append(present, res)
+		}
+	}
+
+	merged := &provider.Metadata{
+		Extended: make(map[string]interface{}),
+		Sources:  make(map[string]string),
+		IDs:      make(map[string]string),
+	}
+
+	// winner picks the best ranked result satisfying has and credits its
+	// provider with field.
+	winner := func(field string, has func(*provider.Metadata) bool) *provider.Metadata {
+		best, bestRank := -1, 0
+		for idx, res := range present {
+			if !has(res.meta) {
+				continue
+			}
+			if rank := ranker.rank(field, res.source(field)); best < 0 || rank < bestRank {
+				best, bestRank = idx, rank
+			}
+		}
+		if best < 0 {
+			return nil
+		}
+		if name := present[best].source(field); name != "" {
+			merged.Sources[field] = name
+		}
+		return present[best].meta
+	}
+
+	for _, field := range coreMergeFields {
+		if src := winner(field.name, func(meta *provider.Metadata) bool { return field.has(&meta.Core) }); src != nil {
+			field.copy(&merged.Core, &src.Core)
+		}
+	}
+
+	extendedKeys := make(map[string]struct{})
+	idKeys := make(map[string]struct{})
+	for _, res := range present {
+		for key := range res.meta.Extended {
+			extendedKeys[key] = struct{}{}
+		}
+		for key := range res.meta.IDs {
+			idKeys[key] = struct{}{}
+		}
+		if merged.Core.MediaType == "" {
+			merged.Core.MediaType = res.meta.Core.MediaType
+		}
+		merged.Confidence = max(merged.Confidence, res.meta.Confidence)
+	}
+	for key := range extendedKeys {
+		if src := winner(key, func(meta *provider.Metadata) bool { _, ok := meta.Extended[key]; return ok }); src != nil {
+			merged.Extended[key] = src.Extended[key]
+		}
+	}
+	for key := range idKeys {
+		if src := winner(key, func(meta *provider.Metadata) bool { return meta.IDs[key] != "" }); src != nil {
+			merged.IDs[key] = src.IDs[key]
+		}
+	}
+
+	if merged.Core.MediaType == "" {
+		merged.Core.MediaType = item.MediaType
+	}
+	if merged.Core.MediaType == "" {
+		if item.IsMovie {
+			merged.Core.MediaType = provider.MediaTypeMovie
+		} else if item.Episode > 0 {
+			merged.Core.MediaType = provider.MediaTypeEpisode
+		}
+	}
+	if merged.Core.Title == "" {
+		merged.Core.Title = item.Name
+	}
+	if merged.Core.Year == "" {
+		merged.Core.Year = item.Year
+	}
+	if merged.Core.SeasonNum == 0 && item.Season > 0 {
+		merged.Core.SeasonNum = item.Season
+	}
+	if merged.Core.EpisodeNum == 0 && item.Episode > 0 {
+		merged.Core.EpisodeNum = item.Episode
+	}
+
+	return merged
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mhmtszr/concurrent-swiss-map"
+)
+
+func episodeResults() []sourcedMetadata {
+	return []sourcedMetadata{
+		{provider: "tmdb", meta: &provider.Metadata{
+			Core:     provider.CoreMetadata{Title: "Lost", EpisodeName: "Pilot (1)", Rating: 7.9, Genres: []string{"Drama", "Mystery"}},
+			Extended: map[string]interface{}{"runtime": 42},
+			IDs:      map[string]string{"tmdb_id": "4607"},
+		}},
+		{provider: "tvdb", meta: &provider.Metadata{
+			Core:     provider.CoreMetadata{Title: "LOST", EpisodeName: "Pilot, Part 1", Rating: 8.1},
+			Extended: map[string]interface{}{"runtime": 43, "networks": "ABC"},
+		}},
+		{provider: "omdb", meta: &provider.Metadata{
+			Core: provider.CoreMetadata{Title: "Lost", Rating: 9.1, Genres: []string{"Adventure"}},
+			IDs:  map[string]string{"imdb_id": "tt0636289"},
+		}},
+	}
+}
+
+func TestMergeSourcedFollowsPriority(t *testing.T) {
+	t.Parallel()
+
+	ranker := fieldRanker{priority: []string{"tmdb", "tvdb", "omdb"}}
+	merged := mergeSourced(MetadataItem{Name: "Lost", Season: 1, Episode: 1}, ranker, episodeResults())
+
+	want := provider.CoreMetadata{Title: "Lost", MediaType: provider.MediaTypeEpisode, SeasonNum: 1, EpisodeNum: 1, EpisodeName: "Pilot (1)", Rating: 7.9, Genres: []string{"Drama", "Mystery"}}
+	if diff := cmp.Diff(want, merged.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"runtime": 42, "networks": "ABC"}, merged.Extended); diff != "" {
+		t.Errorf("Extended mismatch (-want +got):\n%s", diff)
+	}
+	wantSources := map[string]string{
+		"title":         "tmdb",
+		"episode_title": "tmdb",
+		"rating":        "tmdb",
+		"genres":        "tmdb",
+		"runtime":       "tmdb",
+		"networks":      "tvdb",
+		"tmdb_id":       "tmdb",
+		"imdb_id":       "omdb",
+	}
+	if diff := cmp.Diff(wantSources, merged.Sources); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeSourcedFieldPrecedence(t *testing.T) {
+	t.Parallel()
+
+	ranker := fieldRanker{
+		precedence: map[string][]string{
+			"rating":        {"omdb"},
+			"episode_title": {"tvdb"},
+			"runtime":       {"tvdb", "tmdb"},
+			"genres":        {"anidb", "tmdb"},
+		},
+		priority: []string{"tmdb", "tvdb", "omdb"},
+	}
+	merged := mergeSourced(MetadataItem{Name: "Lost"}, ranker, episodeResults())
+
+	got := map[string]interface{}{
+		"rating":        merged.Core.Rating,
+		"episode_title": merged.Core.EpisodeName,
+		"runtime":       merged.Extended["runtime"],
+		"genres":        merged.Core.Genres,
+		"title":         merged.Core.Title,
+	}
+	want := map[string]interface{}{
+		"rating":        float32(9.1),
+		"episode_title": "Pilot, Part 1",
+		"runtime":       43,
+		"genres":        []string{"Drama", "Mystery"},
+		"title":         "Lost",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("merged fields mismatch (-want +got):\n%s", diff)
+	}
+	for field, wantSource := range map[string]string{"rating": "omdb", "episode_title": "tvdb", "runtime": "tvdb", "genres": "tmdb", "title": "tmdb"} {
+		if got := merged.Sources[field]; got != wantSource {
+			t.Errorf("Sources[%q] = %q, want %q", field, got, wantSource)
+		}
+	}
+}
+
+func TestApplyManualMetadataKeepsBetterRankedFields(t *testing.T) {
+	t.Parallel()
+
+	item := MetadataItem{Name: "Lost", MediaType: provider.MediaTypeShow, Key: "show:lost"}
+	engine := &MetadataEngine{
+		sources: []metadataSource{
+			newMetadataSource("tmdb", retryTestProvider{}),
+			newMetadataSource("omdb", retryTestProvider{}),
+		},
+		precedence: map[string][]string{"rating": {"omdb"}},
+		metadata:   csmap.Create[string, *provider.Metadata](),
+	}
+	engine.metadata.Store(item.Key, &provider.Metadata{
+		Core:    provider.CoreMetadata{Title: "Lost", Rating: 7.9, Overview: "Survivors"},
+		Sources: map[string]string{"title": "tmdb", "rating": "tmdb", "overview": "tmdb"},
+	})
+
+	engine.applyManualMetadata(item, "omdb", &provider.Metadata{
+		Core: provider.CoreMetadata{Title: "LOST", Rating: 8.3, Overview: "Plane crash"},
+	})
+
+	merged := engine.Metadata()[item.Key]
+	want := provider.CoreMetadata{Title: "Lost", Rating: 8.3, Overview: "Survivors", MediaType: provider.MediaTypeShow}
+	if diff := cmp.Diff(want, merged.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"title": "tmdb", "rating": "omdb", "overview": "tmdb"}, merged.Sources); diff != "" {
+		t.Errorf("Sources mismatch (-want +got):\n%s", diff)
+	}
+}
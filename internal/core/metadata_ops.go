@@ -2,16 +2,16 @@ package core
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
 	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/Digital-Shane/treeview/v2"
 )
 
-// MetadataItem represents an item that needs metadata.
+// MetadataItem represents an item that needs metadata. KnownIDs holds the
+// IDs higher priority providers resolved for a movie or show during the
+// current lookup, so lower priority ones can fetch the same title exactly.
 type MetadataItem struct {
 	Name      string
 	Year      string
@@ -22,17 +22,16 @@ type MetadataItem struct {
 	Phase     int
 	MediaType provider.MediaType
 	Node      *treeview.Node[treeview.FileInfo]
+	KnownIDs  map[string]string
 }
 
 // MetadataResult represents the result of fetching metadata for an item.
+// ProviderErrs holds each failing provider's error keyed by registry name.
 type MetadataResult struct {
-	Item       MetadataItem
-	Meta       *provider.Metadata
-	Errs       []error
-	TMDBErr    error
-	TVDBErr    error
-	OMDBErr    error
-	FFProbeErr error
+	Item         MetadataItem
+	Meta         *provider.Metadata
+	Errs         []error
+	ProviderErrs map[string]error
 }
 
 type localNodeInfo struct {
@@ -200,60 +199,10 @@ func ShouldReplaceItemNode(existing MetadataItem, candidate MetadataItem) bool {
 	return false
 }
 
-// FetchTMDBMetadata retrieves metadata from TMDB with retry/backoff handling.
-func FetchTMDBMetadata(ctx context.Context, prov provider.Provider, cache provider.MetadataCache, item MetadataItem) (*provider.Metadata, error) {
-	if prov == nil {
-		return nil, nil
-	}
-
-	retryCount := 0
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
-		meta, err := provider.FetchMetadataWithDependencies(
-			ctx,
-			prov,
-			item.Name,
-			item.Year,
-			item.Season,
-			item.Episode,
-			item.IsMovie,
-			cache,
-		)
-
-		if meta != nil {
-			return meta, nil
-		}
-
-		if err == nil {
-			return nil, nil
-		}
-
-		var provErr *provider.ProviderError
-		if errors.As(err, &provErr) && provErr.Code == "RATE_LIMITED" {
-			waitTime := time.Duration(2<<uint(min(retryCount, 4))) * time.Second
-			timer := time.NewTimer(waitTime)
-			select {
-			case <-timer.C:
-			case <-ctx.Done():
-				timer.Stop()
-				return nil, ctx.Err()
-			}
-			retryCount++
-			continue
-		}
-
-		return nil, err
-	}
-}
-
-// FetchOMDBMetadata retrieves metadata from OMDb.
-func FetchOMDBMetadata(ctx context.Context, prov provider.Provider, item MetadataItem, cache provider.MetadataCache) (*provider.Metadata, error) {
+// FetchProviderMetadata searches a provider for the item. Search strategies
+// are tried in order when the initial search finds nothing. Rate limits are
+// waited out by the provider's Resilient wrapper, so they are returned as is.
+func FetchProviderMetadata(ctx context.Context, prov provider.Provider, cache provider.MetadataCache, item MetadataItem, strategies ...provider.SearchStrategy) (*provider.Metadata, error) {
 	if prov == nil {
 		return nil, nil
 	}
@@ -273,12 +222,18 @@ func FetchOMDBMetadata(ctx context.Context, prov provider.Provider, item Metadat
 		item.Episode,
 		item.IsMovie,
 		cache,
+		item.KnownIDs,
+		strategies...,
 	)
-
-	return meta, err
+	if meta != nil {
+		return meta, nil
+	}
+	return nil, err
 }
 
-func FetchTVDBMetadata(ctx context.Context, prov provider.Provider, item MetadataItem, cache provider.MetadataCache) (*provider.Metadata, error) {
+// FetchMetadataByID fetches an item using an exact provider ID, skipping the
+// search. For seasons and episodes the ID identifies the show.
+func FetchMetadataByID(ctx context.Context, prov provider.Provider, item MetadataItem, id string) (*provider.Metadata, error) {
 	if prov == nil {
 		return nil, nil
 	}
@@ -289,18 +244,25 @@ func FetchTVDBMetadata(ctx context.Context, prov provider.Provider, item Metadat
 	default:
 	}
 
-	meta, err := provider.FetchMetadataWithDependencies(
-		ctx,
-		prov,
-		item.Name,
-		item.Year,
-		item.Season,
-		item.Episode,
-		item.IsMovie,
-		cache,
-	)
+	req := provider.FetchRequest{
+		MediaType: provider.MediaTypeShow,
+		ID:        id,
+		Name:      item.Name,
+		Year:      item.Year,
+	}
+	switch {
+	case item.IsMovie:
+		req.MediaType = provider.MediaTypeMovie
+	case item.Season > 0 && item.Episode > 0:
+		req.MediaType = provider.MediaTypeEpisode
+		req.Season = item.Season
+		req.Episode = item.Episode
+	case item.Season > 0:
+		req.MediaType = provider.MediaTypeSeason
+		req.Season = item.Season
+	}
 
-	return meta, err
+	return prov.Fetch(ctx, req)
 }
 
 // FetchFFProbeMetadata retrieves technical metadata using ffprobe when applicable.
@@ -309,17 +271,15 @@ func FetchFFProbeMetadata(ctx context.Context, prov provider.Provider, item Meta
 		return nil, nil
 	}
 
-	if !ShouldRunFFProbe(item) {
+	file := mediaFile(item)
+	if file == nil {
 		return nil, nil
 	}
 
-	path := ""
-	if item.Node != nil {
-		path = item.Node.Data().Path
-	}
+	path := file.Data().Path
 	if path == "" {
 		return nil, &provider.ProviderError{
-			Provider: "ffprobe",
+			Provider: providerNameOrDefault(prov, "ffprobe"),
 			Code:     "MISSING_PATH",
 			Message:  "ffprobe requires a valid file path",
 			Retry:    false,
@@ -357,20 +317,42 @@ func FetchFFProbeMetadata(ctx context.Context, prov provider.Provider, item Meta
 
 // ShouldRunFFProbe determines whether ffprobe should run for the item.
 func ShouldRunFFProbe(item MetadataItem) bool {
+	return mediaFile(item) != nil
+}
+
+// mediaFile returns the video file the item's file-reading providers should
+// read. Movie folders use their largest video, the main feature.
+func mediaFile(item MetadataItem) *treeview.Node[treeview.FileInfo] {
 	if item.Node == nil {
-		return false
+		return nil
 	}
 	if item.MediaType != provider.MediaTypeMovie && item.MediaType != provider.MediaTypeEpisode {
-		return false
+		return nil
 	}
-	data := item.Node.Data()
-	if data.IsDir() {
-		return false
+	if !item.Node.Data().IsDir() {
+		if local.IsVideo(item.Node.Name()) {
+			return item.Node
+		}
+		return nil
 	}
-	return local.IsVideo(item.Node.Name())
+	if item.MediaType != provider.MediaTypeMovie {
+		return nil
+	}
+	var main *treeview.Node[treeview.FileInfo]
+	for _, child := range item.Node.Children() {
+		if child.Data().IsDir() || !local.IsVideo(child.Name()) {
+			continue
+		}
+		if main == nil || child.Data().Size() > main.Data().Size() {
+			main = child
+		}
+	}
+	return main
 }
 
 // MergeMetadata combines base metadata with additional provider results.
+// Each field takes the first value found, base first, and Sources records the
+// provider credited with it.
 func MergeMetadata(item MetadataItem, base *provider.Metadata, extras ...*provider.Metadata) *provider.Metadata {
 	hasAdditional := false
 	for _, extra := range extras {
@@ -384,82 +366,12 @@ func MergeMetadata(item MetadataItem, base *provider.Metadata, extras ...*provid
 		return nil
 	}
 
-	var merged *provider.Metadata
-	if base != nil {
-		merged = cloneMetadata(base)
-	} else {
-		merged = &provider.Metadata{
-			Core: provider.CoreMetadata{
-				MediaType:  item.MediaType,
-				Title:      item.Name,
-				Year:       item.Year,
-				SeasonNum:  item.Season,
-				EpisodeNum: item.Episode,
-			},
-			Extended:   make(map[string]interface{}),
-			Sources:    make(map[string]string),
-			IDs:        make(map[string]string),
-			Confidence: 0,
-		}
-	}
-
-	ensureMetadataMaps(merged)
-
-	if merged.Core.MediaType == "" {
-		merged.Core.MediaType = item.MediaType
-	}
-	if merged.Core.MediaType == "" {
-		if item.IsMovie {
-			merged.Core.MediaType = provider.MediaTypeMovie
-		} else if item.Episode > 0 {
-			merged.Core.MediaType = provider.MediaTypeEpisode
-		}
-	}
-	if merged.Core.Title == "" {
-		merged.Core.Title = item.Name
-	}
-	if merged.Core.Year == "" {
-		merged.Core.Year = item.Year
-	}
-	if merged.Core.SeasonNum == 0 && item.Season > 0 {
-		merged.Core.SeasonNum = item.Season
-	}
-	if merged.Core.EpisodeNum == 0 && item.Episode > 0 {
-		merged.Core.EpisodeNum = item.Episode
-	}
-
+	results := make([]sourcedMetadata, 0, len(extras)+1)
+	results = append(results, sourcedMetadata{meta: base})
 	for _, extra := range extras {
-		if extra == nil {
-			continue
-		}
-		ensureMetadataMaps(extra)
-		for k, v := range extra.Extended {
-			merged.Extended[k] = v
-		}
-		for k, v := range extra.Sources {
-			merged.Sources[k] = v
-		}
-		for k, v := range extra.IDs {
-			merged.IDs[k] = v
-		}
-		if merged.Core.Title == "" && extra.Core.Title != "" {
-			merged.Core.Title = extra.Core.Title
-		}
-		if merged.Core.Year == "" && extra.Core.Year != "" {
-			merged.Core.Year = extra.Core.Year
-		}
-		if merged.Core.SeasonNum == 0 && extra.Core.SeasonNum > 0 {
-			merged.Core.SeasonNum = extra.Core.SeasonNum
-		}
-		if merged.Core.EpisodeNum == 0 && extra.Core.EpisodeNum > 0 {
-			merged.Core.EpisodeNum = extra.Core.EpisodeNum
-		}
-		if extra.Confidence > merged.Confidence {
-			merged.Confidence = extra.Confidence
-		}
+		results = append(results, sourcedMetadata{meta: extra})
 	}
-
-	return merged
+	return mergeSourced(item, fieldRanker{}, results)
 }
 
 func ensureMetadataMaps(meta *provider.Metadata) {
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/Digital-Shane/title-tidy/internal/overrides"
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// overrideID returns the provider ID pinned for the item's show or movie. An
+// ID file in the media folder wins over the override store, and matches
+// remembered from manual resolution win over title aliases.
+func (e *MetadataEngine) overrideID(item MetadataItem, providerName string) string {
+	if dir := e.mediaFolder(item); dir != "" {
+		if id := e.folderIDs(dir)[providerName]; id != "" {
+			return id
+		}
+	}
+	if e.overrides != nil {
+		if id, ok := e.overrides.Lookup(overrideMediaType(item), item.Name, providerName); ok {
+			return id
+		}
+	}
+	if e.localProv == nil {
+		return ""
+	}
+	path := ""
+	if item.Node != nil && item.Node.Data() != nil {
+		path = item.Node.Data().Path
+	}
+	return e.localProv.PinnedID(item.Name, item.Year, path, providerName)
+}
+
+// rememberOverride records the ID behind a manual resolution so later runs
+// fetch the same match. When id is blank it is asked of the provider.
+func (e *MetadataEngine) rememberOverride(failure MetadataFailure, src metadataSource, id string, meta *provider.Metadata) error {
+	if ider, ok := src.provider.(provider.ExactIDer); ok && id == "" {
+		id = ider.ExactID(meta)
+	}
+	if id == "" {
+		return nil
+	}
+
+	name := failure.ParsedName
+	if name == "" {
+		name = failure.Item.Name
+	}
+
+	var errs []error
+	if e.overrides != nil {
+		errs = append(errs, e.overrides.Set(overrideMediaType(failure.Item), name, failure.Item.Year, src.name, id))
+	}
+	if e.writeIDFiles {
+		if dir := e.mediaFolder(failure.Item); dir != "" {
+			errs = append(errs, overrides.WriteIDFile(dir, src.name, id))
+			e.idFiles.Delete(dir)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// folderIDs reads and caches the ID file in dir. Missing or unreadable files
+// yield no IDs.
+func (e *MetadataEngine) folderIDs(dir string) map[string]string {
+	if cached, ok := e.idFiles.Load(dir); ok {
+		return cached.(map[string]string)
+	}
+	ids, err := overrides.ReadIDFile(dir)
+	if err != nil {
+		ids = nil
+	}
+	e.idFiles.Store(dir, ids)
+	return ids
+}
+
+// mediaFolder finds the show or movie folder holding the item by walking up
+// from its node. Loose movie files have no folder of their own.
+func (e *MetadataEngine) mediaFolder(item MetadataItem) string {
+	if e.localProv == nil {
+		return ""
+	}
+	want := overrideMediaType(item)
+	for node := item.Node; node != nil; node = node.Parent() {
+		data := node.Data()
+		if data == nil || !data.IsDir() || data.Path == "" {
+			continue
+		}
+		if mediaType, _, err := e.localProv.Detect(node); err == nil && mediaType == want {
+			return data.Path
+		}
+	}
+	return ""
+}
+
+func overrideMediaType(item MetadataItem) provider.MediaType {
+	if item.IsMovie {
+		return provider.MediaTypeMovie
+	}
+	return provider.MediaTypeShow
+}
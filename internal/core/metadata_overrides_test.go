@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/overrides"
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+	"github.com/Digital-Shane/treeview/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/mhmtszr/concurrent-swiss-map"
+)
+
+func TestMetadataEngineRemembersManualMatches(t *testing.T) {
+	t.Parallel()
+
+	store, err := overrides.Open(filepath.Join(t.TempDir(), "overrides.json"))
+	if err != nil {
+		t.Fatalf("overrides.Open() unexpected error: %v", err)
+	}
+
+	item := MetadataItem{
+		Name:      "Matrix",
+		Year:      "1999",
+		IsMovie:   true,
+		MediaType: provider.MediaTypeMovie,
+		Key:       provider.GenerateMetadataKey("movie", "Matrix", "1999", 0, 0),
+	}
+	notFound := &provider.ProviderError{Provider: "retry-test", Code: "NOT_FOUND", Message: "missing", Retry: false}
+
+	engine := &MetadataEngine{
+		sources:   sourcesFor("tmdb", &candidateTestProvider{}),
+		overrides: store,
+		metadata:  csmap.Create[string, *provider.Metadata](),
+	}
+	engine.processResult(MetadataResult{Item: item, Errs: []error{notFound}, ProviderErrs: map[string]error{"tmdb": notFound}})
+
+	// A failed manual search must not change the name the override is saved under.
+	if _, err := engine.RetryProvider(context.Background(), item.Key, "tmdb", "Matrx", ""); err != nil {
+		t.Fatalf("RetryProvider() unexpected error: %v", err)
+	}
+	if result, err := engine.RetryProvider(context.Background(), item.Key, "tmdb", "The Matrix", "603"); err != nil || result != nil {
+		t.Fatalf("RetryProvider() = (%v, %v), want resolved", result, err)
+	}
+
+	if id, ok := store.Lookup(provider.MediaTypeMovie, "Matrix", "tmdb"); !ok || id != "603" {
+		t.Fatalf("store.Lookup() = (%q, %v), want (603, true)", id, ok)
+	}
+
+	// A later run fetches the stored ID instead of searching.
+	prov := &candidateTestProvider{}
+	next := &MetadataEngine{
+		sources:   sourcesFor("tmdb", prov),
+		overrides: store,
+		metadata:  csmap.Create[string, *provider.Metadata](),
+	}
+	meta, err := next.fetchSource(context.Background(), next.sources[0], item)
+	if err != nil || meta == nil {
+		t.Fatalf("fetchSource() = (%v, %v), want metadata", meta, err)
+	}
+	want := provider.FetchRequest{MediaType: provider.MediaTypeMovie, ID: "603", Name: "Matrix", Year: "1999"}
+	if diff := cmp.Diff([]provider.FetchRequest{want}, prov.requests); diff != "" {
+		t.Errorf("override request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetadataEngineUsesFolderIDFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	movieDir := filepath.Join(root, "The Matrix (1999)")
+	if err := os.Mkdir(movieDir, 0755); err != nil {
+		t.Fatalf("Mkdir() unexpected error: %v", err)
+	}
+	if err := overrides.WriteIDFile(movieDir, "tmdb", "603"); err != nil {
+		t.Fatalf("WriteIDFile() unexpected error: %v", err)
+	}
+
+	dirNode := treeview.NewNode(movieDir, "The Matrix (1999)", treeview.FileInfo{FileInfo: NewSimpleFileInfo("The Matrix (1999)", true), Path: movieDir})
+	fileName := "The.Matrix.1999.1080p.mkv"
+	fileNode := treeview.NewNode(fileName, fileName, treeview.FileInfo{FileInfo: NewSimpleFileInfo(fileName, false), Path: filepath.Join(movieDir, fileName)})
+	dirNode.AddChild(fileNode)
+
+	item := MetadataItem{
+		Name:      "The Matrix",
+		Year:      "1999",
+		IsMovie:   true,
+		MediaType: provider.MediaTypeMovie,
+		Node:      fileNode,
+	}
+
+	prov := &candidateTestProvider{}
+	engine := &MetadataEngine{
+		localProv: local.New(),
+		sources:   sourcesFor("tmdb", prov),
+		metadata:  csmap.Create[string, *provider.Metadata](),
+	}
+	if _, err := engine.fetchSource(context.Background(), engine.sources[0], item); err != nil {
+		t.Fatalf("fetchSource() unexpected error: %v", err)
+	}
+	if len(prov.requests) != 1 || prov.requests[0].ID != "603" {
+		t.Errorf("requests = %+v, want one exact lookup of 603", prov.requests)
+	}
+}
+
+func TestMetadataEngineAppliesTitleAliases(t *testing.T) {
+	t.Parallel()
+
+	fileName := "House.of.Cards.S01E01.mkv"
+	fileNode := treeview.NewNode(fileName, fileName, treeview.FileInfo{FileInfo: NewSimpleFileInfo(fileName, false), Path: fileName})
+	tree := treeview.NewTree([]*treeview.Node[treeview.FileInfo]{fileNode})
+
+	localProv := local.New()
+	localProv.SetAliases([]local.Alias{{Name: "House of Cards", Year: "2013", IDs: map[string]string{"tmdb": "603"}}})
+
+	prov := &candidateTestProvider{}
+	engine := &MetadataEngine{
+		localProv: localProv,
+		tree:      tree,
+		sources:   sourcesFor("tmdb", prov),
+		metadata:  csmap.Create[string, *provider.Metadata](),
+	}
+	items := engine.collectMetadataItems()
+	var show MetadataItem
+	for _, item := range items {
+		if item.MediaType == provider.MediaTypeShow {
+			show = item
+		}
+	}
+	if show.Name != "House of Cards" || show.Year != "2013" {
+		t.Fatalf("show item = %q (%q), want House of Cards (2013)", show.Name, show.Year)
+	}
+
+	if _, err := engine.fetchSource(context.Background(), engine.sources[0], show); err != nil {
+		t.Fatalf("fetchSource() unexpected error: %v", err)
+	}
+	if len(prov.requests) != 1 || prov.requests[0].ID != "603" {
+		t.Errorf("requests = %+v, want one exact lookup of 603", prov.requests)
+	}
+}
@@ -179,7 +179,16 @@ func LinkVirtualDir(node *treeview.Node[treeview.FileInfo], mm *MediaMeta, linkP
 		mm.NewName = dirName
 	}
 
+	// A destination set on the folder, such as a collection folder inside
+	// the link path, wins over the link path itself
 	dirPath := filepath.Join(linkPath, dirName)
+	if mm.DestinationPath != "" {
+		if dirPath, err = sanitizePath(mm.DestinationPath); err != nil {
+			log.LogCreateDir(mm.DestinationPath, false, err)
+			errs = append(errs, mm.Fail(err))
+			return successes, errs
+		}
+	}
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		log.LogCreateDir(dirPath, false, err)
 		errs = append(errs, fmt.Errorf("create %s: %w", mm.NewName, mm.Fail(err)))
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+const (
+	// A hinted video under this share of its largest sibling is a sample
+	sampleSizeRatio = 0.25
+	// Without siblings to compare, a hinted video under this size is reported
+	// as a possible sample
+	sampleMaxSize = 200 << 20
+	// A hinted video under this share of the provider runtime is a sample
+	sampleDurationRatio = 0.25
+	// Without a runtime, a hinted video shorter than this is a sample
+	sampleMaxDuration = 5 * time.Minute
+)
+
+// SampleDetector decides which files and folders are release samples. A
+// file needs a sample hint in its name and a short duration or small size
+// to count, so "The.Sample.Family.S01E01.mkv" is kept. Folders named Sample
+// are samples along with everything inside them.
+type SampleDetector struct {
+	Probe    provider.Provider             // reads durations from files; nil judges by size alone
+	Metadata map[string]*provider.Metadata // provider results holding runtimes
+}
+
+// Classify returns why node is a sample, or "" when it isn't. confirmed is
+// false when the only evidence is a sample hint and a small size, with no
+// duration or sibling to compare against; such files are reported rather
+// than deleted.
+func (d SampleDetector) Classify(ctx context.Context, node *treeview.Node[treeview.FileInfo]) (reason string, confirmed bool) {
+	name := node.Name()
+	if node.Data().IsDir() {
+		if local.IsSampleFolder(name) {
+			return "sample folder", true
+		}
+		return "", false
+	}
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Data().IsDir() && local.IsSampleFolder(parent.Name()) {
+			return "inside sample folder", true
+		}
+	}
+	if !local.IsVideo(name) || !local.IsSample(name) {
+		return "", false
+	}
+
+	// A known duration settles it either way
+	if duration := d.duration(ctx, node); duration > 0 {
+		runtime := d.runtime(node)
+		switch {
+		case runtime > 0 && duration < time.Duration(float64(runtime)*sampleDurationRatio):
+			return fmt.Sprintf("sample: %s of a %s runtime", formatSpan(duration), formatSpan(runtime)), true
+		case runtime == 0 && duration < sampleMaxDuration:
+			return fmt.Sprintf("sample: only %s long", formatSpan(duration)), true
+		}
+		return "", false
+	}
+
+	size := node.Data().Size()
+	if largest := largestSiblingVideo(node); largest > 0 {
+		if float64(size) < float64(largest)*sampleSizeRatio {
+			return fmt.Sprintf("sample: %s beside a %s video", formatSize(size), formatSize(largest)), true
+		}
+		return "", false
+	}
+	if size < sampleMaxSize {
+		return fmt.Sprintf("possible sample: only %s", formatSize(size)), false
+	}
+	return "", false
+}
+
+// duration probes the file for its running time, or returns 0 when there is
+// no probe or it fails.
+func (d SampleDetector) duration(ctx context.Context, node *treeview.Node[treeview.FileInfo]) time.Duration {
+	meta, err := probeFile(ctx, d.Probe, node)
+	if err != nil || meta == nil {
+		return 0
+	}
+	return time.Duration(extendedInt(meta, "duration_seconds")) * time.Second
+}
+
+// runtime looks up the provider runtime of the movie or episode the file
+// belongs to, or returns 0 when none is known.
+func (d SampleDetector) runtime(node *treeview.Node[treeview.FileInfo]) time.Duration {
+	return providerRuntime(d.Metadata, node)
+}
+
+// probeFile reads the technical metadata of the file behind node. It returns
+// nil without an error when there is no probe.
+func probeFile(ctx context.Context, probe provider.Provider, node *treeview.Node[treeview.FileInfo]) (*provider.Metadata, error) {
+	if probe == nil {
+		return nil, nil
+	}
+	return probe.Fetch(ctx, provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Name:      node.Name(),
+		Extra:     map[string]interface{}{"path": node.Data().Path},
+	})
+}
+
+// providerRuntime looks up the provider runtime of the movie or episode the
+// file belongs to, or returns 0 when none is known.
+func providerRuntime(metadata map[string]*provider.Metadata, node *treeview.Node[treeview.FileInfo]) time.Duration {
+	if len(metadata) == 0 {
+		return 0
+	}
+	mediaType, meta, err := local.New().Detect(node)
+	if err != nil || meta == nil {
+		return 0
+	}
+	key := provider.GenerateMetadataKey(string(mediaType), meta.Core.Title, meta.Core.Year, meta.Core.SeasonNum, meta.Core.EpisodeNum)
+	return time.Duration(extendedInt(metadata[key], "runtime")) * time.Minute
+}
+
+// extendedInt reads a whole number from meta.Extended. Values read back from
+// the disk cache are json.Number rather than int.
+func extendedInt(meta *provider.Metadata, key string) int {
+	if meta == nil {
+		return 0
+	}
+	switch v := meta.Extended[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return int(f)
+		}
+	}
+	return 0
+}
+
+// largestSiblingVideo returns the size of the largest video beside node
+// without a sample hint of its own.
+func largestSiblingVideo(node *treeview.Node[treeview.FileInfo]) int64 {
+	parent := node.Parent()
+	if parent == nil {
+		return 0
+	}
+	var largest int64
+	for _, sibling := range parent.Children() {
+		name := sibling.Name()
+		if sibling == node || sibling.Data().IsDir() || !local.IsVideo(name) || local.IsSample(name) {
+			continue
+		}
+		largest = max(largest, sibling.Data().Size())
+	}
+	return largest
+}
+
+func formatSpan(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+func formatSize(bytes int64) string {
+	const mb = 1 << 20
+	if bytes >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", (bytes+mb/2)/mb)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+// sizedFileInfo is a SimpleFileInfo with a size.
+type sizedFileInfo struct {
+	*SimpleFileInfo
+	size int64
+}
+
+func (f sizedFileInfo) Size() int64 { return f.size }
+
+func sizedNode(name string, size int64) *treeview.Node[treeview.FileInfo] {
+	info := sizedFileInfo{SimpleFileInfo: NewSimpleFileInfo(name, false), size: size}
+	return treeview.NewNode(name, name, treeview.FileInfo{FileInfo: info, Path: "/library/" + name})
+}
+
+func dirNode(name string, children ...*treeview.Node[treeview.FileInfo]) *treeview.Node[treeview.FileInfo] {
+	dir := treeview.NewNode(name, name, treeview.FileInfo{FileInfo: NewSimpleFileInfo(name, true), Path: "/library/" + name})
+	for _, child := range children {
+		dir.AddChild(child)
+	}
+	return dir
+}
+
+// durationProvider reports a fixed duration for every file, as ffprobe would.
+type durationProvider struct {
+	retryTestProvider
+	seconds int
+}
+
+func (p durationProvider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	return &provider.Metadata{Extended: map[string]interface{}{"duration_seconds": p.seconds}}, nil
+}
+
+func TestSampleDetectorClassify(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+	movie := sizedNode("Heat (1995).mkv", 8000*mb)
+	hinted := sizedNode("Heat (1995)-sample.mkv", 60*mb)
+	dirNode("Heat (1995)", movie, hinted)
+
+	episode := sizedNode("The.Sample.Family.S01E01.mkv", 900*mb)
+	episode2 := sizedNode("The.Sample.Family.S01E02.mkv", 950*mb)
+	dirNode("The Sample Family", episode, episode2)
+
+	nested := sizedNode("heat.mkv", 60*mb)
+	sampleDir := dirNode("Sample", nested)
+	dirNode("Heat Extras", sampleDir)
+
+	lone := sizedNode("sample.mkv", 40*mb)
+	loneEpisode := sizedNode("The.Sample.Family.S01E01.mkv", 150*mb)
+	loneLarge := sizedNode("Free.Sample.2012.mkv", 1400*mb)
+	samples := dirNode("Free Samples (2012)")
+	samplesFile := sizedNode("Free Samples (2012).mkv", 50*mb)
+
+	tests := []struct {
+		name     string
+		detector SampleDetector
+		node     *treeview.Node[treeview.FileInfo]
+		want     string
+	}{
+		{name: "small beside movie", node: hinted, want: "sample: 60 MB beside a 7.8 GB video"},
+		{name: "movie itself", node: movie, want: ""},
+		{name: "title with sample word", node: episode, want: ""},
+		{name: "sample folder", node: sampleDir, want: "sample folder"},
+		{name: "inside sample folder", node: nested, want: "inside sample folder"},
+		{name: "small lone sample", node: lone, want: "possible sample: only 40 MB"},
+		{name: "small lone episode", node: loneEpisode, want: "possible sample: only 150 MB"},
+		{name: "large lone hinted", node: loneLarge, want: ""},
+		{name: "plural folder title", node: samples, want: ""},
+		{name: "plural file title", node: samplesFile, want: ""},
+		{name: "short probe", detector: SampleDetector{Probe: durationProvider{seconds: 90}}, node: loneLarge, want: "sample: only 2m long"},
+		{name: "full length probe", detector: SampleDetector{Probe: durationProvider{seconds: 6600}}, node: lone, want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, confirmed := tc.detector.Classify(context.Background(), tc.node)
+			if got != tc.want {
+				t.Errorf("Classify(%s) = %q, want %q", tc.node.Name(), got, tc.want)
+			}
+			if wantConfirmed := tc.want != "" && !strings.HasPrefix(tc.want, "possible"); confirmed != wantConfirmed {
+				t.Errorf("Classify(%s) confirmed = %v, want %v", tc.node.Name(), confirmed, wantConfirmed)
+			}
+		})
+	}
+}
+
+func TestSampleDetectorUsesRuntime(t *testing.T) {
+	t.Parallel()
+
+	node := sizedNode("Heat.1995.sample.mkv", 900<<20)
+	key := provider.GenerateMetadataKey("movie", "Heat", "1995", 0, 0)
+	detector := SampleDetector{
+		Probe:    durationProvider{seconds: int((20 * time.Minute).Seconds())},
+		Metadata: map[string]*provider.Metadata{key: {Extended: map[string]interface{}{"runtime": 170}}},
+	}
+
+	// Twenty minutes is long for a sample on its own, but short beside the
+	// movie's runtime
+	if got, _ := detector.Classify(context.Background(), node); got != "sample: 20m of a 2h 50m runtime" {
+		t.Errorf("Classify() = %q, want %q", got, "sample: 20m of a 2h 50m runtime")
+	}
+
+	// Runtimes read back from the disk cache are json.Number
+	detector.Metadata[key].Extended["runtime"] = json.Number("170")
+	if got, confirmed := detector.Classify(context.Background(), node); got != "sample: 20m of a 2h 50m runtime" || !confirmed {
+		t.Errorf("Classify() with a cached runtime = %q, %v, want the runtime verdict", got, confirmed)
+	}
+}
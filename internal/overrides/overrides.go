@@ -0,0 +1,261 @@
+// Package overrides persists the provider IDs a user picked while resolving
+// metadata manually so later runs can fetch the same match without searching.
+package overrides
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// IDFileName is the optional per-folder file holding provider IDs for the
+// show or movie in that folder.
+const IDFileName = ".title-tidy-id"
+
+// Entry records the provider IDs chosen for one show or movie.
+type Entry struct {
+	MediaType provider.MediaType `json:"media_type"`
+	Name      string             `json:"name"`
+	Year      string             `json:"year,omitempty"`
+	IDs       map[string]string  `json:"ids"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// Store is a JSON backed set of overrides keyed by media type and normalized
+// name. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// DefaultPath returns the location of the override store.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".title-tidy", "overrides.json"), nil
+}
+
+// Open loads the store at path. A missing file yields an empty store.
+func Open(path string) (*Store, error) {
+	store := &Store{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return store, nil
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides: %w", err)
+	}
+	for _, entry := range entries {
+		if len(entry.IDs) == 0 {
+			continue
+		}
+		store.entries[Key(entry.MediaType, entry.Name)] = entry
+	}
+	return store, nil
+}
+
+// Key builds the store key for a name. Seasons and episodes share the key of
+// their show.
+func Key(mediaType provider.MediaType, name string) string {
+	kind := provider.MediaTypeShow
+	if mediaType == provider.MediaTypeMovie {
+		kind = provider.MediaTypeMovie
+	}
+	return string(kind) + ":" + provider.NormalizeTitle(name)
+}
+
+// Lookup returns the ID stored for the named show or movie and provider.
+func (s *Store) Lookup(mediaType provider.MediaType, name, providerName string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[Key(mediaType, name)]
+	if !ok {
+		return "", false
+	}
+	id, ok := entry.IDs[providerName]
+	return id, ok && id != ""
+}
+
+// Set records the provider ID for a show or movie and saves the store.
+func (s *Store) Set(mediaType provider.MediaType, name, year, providerName, id string) error {
+	name = strings.TrimSpace(name)
+	id = strings.TrimSpace(id)
+	if name == "" || providerName == "" || id == "" {
+		return fmt.Errorf("override requires a name, provider and ID")
+	}
+	if mediaType != provider.MediaTypeMovie {
+		mediaType = provider.MediaTypeShow
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := Key(mediaType, name)
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = Entry{MediaType: mediaType, Name: name, IDs: make(map[string]string)}
+	}
+	if year != "" {
+		entry.Year = year
+	}
+	entry.IDs[providerName] = id
+	entry.UpdatedAt = time.Now()
+	s.entries[key] = entry
+
+	return s.saveLocked()
+}
+
+// Entries returns every override sorted by name.
+func (s *Store) Entries() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedLocked()
+}
+
+// Remove deletes the overrides matching name for any media type and returns
+// how many were removed.
+func (s *Store) Remove(name string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, mediaType := range []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeMovie} {
+		key := Key(mediaType, name)
+		if _, ok := s.entries[key]; ok {
+			delete(s.entries, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.saveLocked()
+}
+
+// Clear deletes every override and returns how many were removed.
+func (s *Store) Clear() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := len(s.entries)
+	s.entries = make(map[string]Entry)
+	return removed, s.saveLocked()
+}
+
+func (s *Store) sortedLocked() []Entry {
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+		}
+		return entries[i].MediaType < entries[j].MediaType
+	})
+	return entries
+}
+
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create overrides directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.sortedLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated store.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write overrides: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write overrides: %w", err)
+	}
+	return nil
+}
+
+// ReadIDFile reads the provider IDs stored in dir's ID file. The file holds
+// one "provider=id" pair per line; blank lines and # comments are ignored.
+func ReadIDFile(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IDFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, id, ok := strings.Cut(line, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		id = strings.TrimSpace(id)
+		if !ok || name == "" || id == "" {
+			continue
+		}
+		ids[name] = id
+	}
+	return ids, scanner.Err()
+}
+
+// WriteIDFile adds or replaces the provider ID in dir's ID file, keeping any
+// IDs already recorded for other providers.
+func WriteIDFile(dir, providerName, id string) error {
+	ids, err := ReadIDFile(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if ids == nil {
+		ids = make(map[string]string)
+	}
+	ids[strings.ToLower(providerName)] = strings.TrimSpace(id)
+
+	names := make([]string, 0, len(ids))
+	for name := range ids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	buf.WriteString("# Provider IDs used by title-tidy for this folder\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s=%s\n", name, ids[name])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, IDFileName), []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", IDFileName, err)
+	}
+	return nil
+}
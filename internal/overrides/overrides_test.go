@@ -0,0 +1,112 @@
+package overrides
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	if err := store.Set(provider.MediaTypeShow, "The Office", "2005", "tmdb", "2316"); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+	if err := store.Set(provider.MediaTypeEpisode, "Office", "", "tvdb", "series-73244"); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+	if err := store.Set(provider.MediaTypeMovie, "Heat", "1995", "tmdb", "949"); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() reload unexpected error: %v", err)
+	}
+
+	// Seasons and episodes resolve to their show's entry.
+	if id, ok := reopened.Lookup(provider.MediaTypeSeason, "office", "tmdb"); !ok || id != "2316" {
+		t.Errorf("Lookup(season, office, tmdb) = (%q, %v), want (2316, true)", id, ok)
+	}
+	if id, ok := reopened.Lookup(provider.MediaTypeShow, "The Office", "tvdb"); !ok || id != "series-73244" {
+		t.Errorf("Lookup(show, The Office, tvdb) = (%q, %v), want (series-73244, true)", id, ok)
+	}
+	if _, ok := reopened.Lookup(provider.MediaTypeShow, "Heat", "tmdb"); ok {
+		t.Errorf("Lookup(show, Heat) found a movie override")
+	}
+
+	entries := reopened.Entries()
+	if diff := cmp.Diff([]string{"Heat", "The Office"}, []string{entries[0].Name, entries[1].Name}); diff != "" {
+		t.Errorf("Entries() order mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"tmdb": "2316", "tvdb": "series-73244"}, entries[1].IDs); diff != "" {
+		t.Errorf("Entries() IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreRemoveAndClear(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	_ = store.Set(provider.MediaTypeShow, "Fargo", "", "tmdb", "60622")
+	_ = store.Set(provider.MediaTypeMovie, "Fargo", "1996", "tmdb", "275")
+	_ = store.Set(provider.MediaTypeMovie, "Heat", "1995", "tmdb", "949")
+
+	removed, err := store.Remove("fargo")
+	if err != nil || removed != 2 {
+		t.Fatalf("Remove(fargo) = (%d, %v), want (2, nil)", removed, err)
+	}
+	if removed, _ := store.Remove("Missing"); removed != 0 {
+		t.Errorf("Remove(Missing) = %d, want 0", removed)
+	}
+
+	removed, err = store.Clear()
+	if err != nil || removed != 1 {
+		t.Fatalf("Clear() = (%d, %v), want (1, nil)", removed, err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() reload unexpected error: %v", err)
+	}
+	if got := len(reopened.Entries()); got != 0 {
+		t.Errorf("Entries() after Clear = %d, want 0", got)
+	}
+}
+
+func TestIDFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := ReadIDFile(dir); !os.IsNotExist(err) {
+		t.Fatalf("ReadIDFile() on empty dir error = %v, want not exist", err)
+	}
+
+	if err := WriteIDFile(dir, "tmdb", "1399"); err != nil {
+		t.Fatalf("WriteIDFile() unexpected error: %v", err)
+	}
+	if err := WriteIDFile(dir, "TVDB", " series-121361 "); err != nil {
+		t.Fatalf("WriteIDFile() unexpected error: %v", err)
+	}
+
+	ids, err := ReadIDFile(dir)
+	if err != nil {
+		t.Fatalf("ReadIDFile() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"tmdb": "1399", "tvdb": "series-121361"}, ids); diff != "" {
+		t.Errorf("ReadIDFile() mismatch (-want +got):\n%s", diff)
+	}
+}
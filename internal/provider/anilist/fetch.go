@@ -0,0 +1,462 @@
+package anilist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// maxChain bounds how many related entries are followed when walking a
+// series, so a cycle in the relation graph can't loop forever.
+const maxChain = 30
+
+const mediaFields = `id idMal format episodes seasonYear startDate { year }
+title { romaji english native } synonyms genres averageScore description
+relations { edges { relationType node { id format } } }`
+
+const mediaQuery = `query ($id: Int) { Media(id: $id, type: ANIME) { ` + mediaFields + ` } }`
+
+const searchQuery = `query ($search: String, $formats: [MediaFormat]) {
+Page(perPage: 10) { media(search: $search, type: ANIME, format_in: $formats, sort: SEARCH_MATCH) { ` + mediaFields + ` } } }`
+
+var (
+	movieFormats  = []string{"MOVIE"}
+	seriesFormats = []string{"TV", "TV_SHORT", "ONA", "OVA"}
+)
+
+type media struct {
+	ID         int    `json:"id"`
+	IDMal      int    `json:"idMal"`
+	Format     string `json:"format"`
+	Episodes   int    `json:"episodes"`
+	SeasonYear int    `json:"seasonYear"`
+	StartDate  struct {
+		Year int `json:"year"`
+	} `json:"startDate"`
+	Title struct {
+		Romaji  string `json:"romaji"`
+		English string `json:"english"`
+		Native  string `json:"native"`
+	} `json:"title"`
+	Synonyms     []string `json:"synonyms"`
+	Genres       []string `json:"genres"`
+	AverageScore int      `json:"averageScore"`
+	Description  string   `json:"description"`
+	Relations    struct {
+		Edges []struct {
+			RelationType string `json:"relationType"`
+			Node         struct {
+				ID     int    `json:"id"`
+				Format string `json:"format"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"relations"`
+}
+
+// position locates an episode within a series of AniList entries.
+type position struct {
+	entry    *media
+	cour     int  // 1-based index of entry in the sequel chain
+	episode  int  // episode number within entry
+	absolute int  // episode number counted from the start of the series
+	counted  bool // false when an earlier entry has no episode count yet
+}
+
+func (p *Provider) fetchMovie(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	m, score, err := p.resolve(ctx, request, movieFormats, provider.MediaTypeMovie)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := p.newMetadata(m, m, provider.MediaTypeMovie, score)
+	credit(meta)
+	return meta, nil
+}
+
+func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	root, score, err := p.resolveSeries(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := p.newMetadata(root, root, provider.MediaTypeShow, score)
+	if root.Episodes > 0 {
+		meta.Extended["total_episodes"] = root.Episodes
+	}
+	credit(meta)
+	return meta, nil
+}
+
+func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if request.Season <= 0 {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "season fetch requires a valid season number", Retry: false}
+	}
+
+	root, score, err := p.resolveSeries(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := p.locate(ctx, root, request.Season, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := p.newMetadata(root, pos.entry, provider.MediaTypeSeason, score)
+	meta.Core.SeasonNum = pos.cour
+	meta.Extended["cour"] = pos.cour
+	if pos.entry.Episodes > 0 {
+		meta.Extended["total_episodes"] = pos.entry.Episodes
+	}
+	credit(meta)
+	return meta, nil
+}
+
+func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if request.Season <= 0 || request.Episode <= 0 {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "episode fetch requires valid season and episode numbers", Retry: false}
+	}
+
+	root, score, err := p.resolveSeries(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := p.locate(ctx, root, request.Season, request.Episode)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := p.newMetadata(root, pos.entry, provider.MediaTypeEpisode, score)
+	meta.Core.SeasonNum = pos.cour
+	meta.Core.EpisodeNum = pos.episode
+	meta.Extended["cour"] = pos.cour
+	if pos.counted {
+		meta.Extended["absolute_episode"] = pos.absolute
+	}
+	credit(meta)
+	return meta, nil
+}
+
+// SearchCandidates lists the anime search results for a movie or show
+// request, best match first. Candidate IDs are AniList IDs.
+func (p *Provider) SearchCandidates(ctx context.Context, request provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	formats := seriesFormats
+	switch request.MediaType {
+	case provider.MediaTypeMovie:
+		formats = movieFormats
+	case provider.MediaTypeShow:
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", request.MediaType)
+	}
+
+	results, err := p.search(ctx, request, formats)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]provider.Candidate, 0, len(results))
+	for _, m := range results {
+		candidates = append(candidates, provider.Candidate{
+			ID:        strconv.Itoa(m.ID),
+			Title:     p.title(&m),
+			Year:      yearOf(&m),
+			MediaType: request.MediaType,
+			Overview:  stripHTML(m.Description),
+		})
+	}
+	return provider.RankCandidates(request.Name, request.Year, request.MediaType, candidates, limit), nil
+}
+
+// resolveSeries finds the series behind a request and walks back through
+// its prequels to the first entry, so season numbers count from there.
+func (p *Provider) resolveSeries(ctx context.Context, request provider.FetchRequest) (*media, float64, error) {
+	m, score, err := p.resolve(ctx, request, seriesFormats, provider.MediaTypeShow)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := 0; i < maxChain; i++ {
+		prev := related(m, "PREQUEL")
+		if prev == 0 {
+			break
+		}
+		if m, err = p.media(ctx, prev); err != nil {
+			return nil, 0, err
+		}
+	}
+	return m, score, nil
+}
+
+// resolve fetches the entry named by request.ID, or searches by title and
+// returns the best match with its confidence.
+func (p *Provider) resolve(ctx context.Context, request provider.FetchRequest, formats []string, mediaType provider.MediaType) (*media, float64, error) {
+	if id, err := strconv.Atoi(strings.TrimSpace(request.ID)); err == nil {
+		m, err := p.media(ctx, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return m, 1.0, nil
+	}
+
+	results, err := p.search(ctx, request, formats)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 {
+		return nil, 0, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("no results found for anime: %s", request.Name), Retry: false}
+	}
+
+	candidates := make([]provider.MatchCandidate, 0, len(results))
+	for _, m := range results {
+		titles := append([]string{m.Title.English, m.Title.Native}, m.Synonyms...)
+		candidates = append(candidates, provider.MatchCandidate{
+			Title:     m.Title.Romaji,
+			Titles:    titles,
+			Year:      yearOf(&m),
+			MediaType: mediaType,
+		})
+	}
+	best, score := provider.BestMatch(request.Name, request.Year, mediaType, candidates)
+	return &results[best], score, nil
+}
+
+// locate maps a season and episode onto the sequel chain starting at root.
+// Season N is the Nth entry, and an episode past the end of an entry rolls
+// over into the next one, so absolute numbering under season 1 lands on
+// the right cour. An episode of 0 only resolves the season.
+func (p *Provider) locate(ctx context.Context, root *media, season, episode int) (*position, error) {
+	pos := &position{entry: root, cour: 1, episode: episode, counted: true}
+	offset := 0
+
+	advance := func() (bool, error) {
+		next := related(pos.entry, "SEQUEL")
+		if next == 0 || pos.cour >= maxChain {
+			return false, nil
+		}
+		m, err := p.media(ctx, next)
+		if err != nil {
+			return false, err
+		}
+		if pos.entry.Episodes > 0 {
+			offset += pos.entry.Episodes
+		} else {
+			pos.counted = false
+		}
+		pos.entry = m
+		pos.cour++
+		return true, nil
+	}
+
+	for pos.cour < season {
+		ok, err := advance()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("season %d not found for %s", season, p.title(root)), Retry: false}
+		}
+	}
+
+	for pos.episode > 0 && pos.entry.Episodes > 0 && pos.episode > pos.entry.Episodes {
+		length := pos.entry.Episodes
+		ok, err := advance()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		pos.episode -= length
+	}
+
+	pos.absolute = offset + pos.episode
+	return pos, nil
+}
+
+// related returns the ID of the first series entry linked to m by
+// relationType, or 0. Movies and specials are skipped.
+func related(m *media, relationType string) int {
+	for _, edge := range m.Relations.Edges {
+		if edge.RelationType != relationType {
+			continue
+		}
+		switch edge.Node.Format {
+		case "TV", "TV_SHORT", "ONA":
+			return edge.Node.ID
+		}
+	}
+	return 0
+}
+
+func (p *Provider) media(ctx context.Context, id int) (*media, error) {
+	var data struct {
+		Media *media `json:"Media"`
+	}
+	if err := p.query(ctx, mediaQuery, map[string]interface{}{"id": id}, &data); err != nil {
+		return nil, p.mapError(err)
+	}
+	if data.Media == nil {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("anime %d not found", id), Retry: false}
+	}
+	return data.Media, nil
+}
+
+func (p *Provider) search(ctx context.Context, request provider.FetchRequest, formats []string) ([]media, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "anime search requires a title", Retry: false}
+	}
+
+	var data struct {
+		Page struct {
+			Media []media `json:"media"`
+		} `json:"Page"`
+	}
+	if err := p.query(ctx, searchQuery, map[string]interface{}{"search": name, "formats": formats}, &data); err != nil {
+		return nil, p.mapError(err)
+	}
+	return data.Page.Media, nil
+}
+
+// query posts a GraphQL query and decodes its data into out. GraphQL errors
+// are returned as provider.StatusError with the status AniList attached to
+// them.
+func (p *Provider) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+			Status  int    `json:"status"`
+		} `json:"errors"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&envelope)
+
+	if len(envelope.Errors) > 0 {
+		statusErr := provider.NewStatusError(resp, envelope.Errors[0].Message)
+		if status := envelope.Errors[0].Status; status != 0 {
+			statusErr.Status = status
+		}
+		return statusErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return provider.NewStatusError(resp, "")
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("invalid response: %w", decodeErr)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// newMetadata builds metadata for entry, taking the titles from series so
+// every cour of a show shares one name.
+func (p *Provider) newMetadata(series, entry *media, mediaType provider.MediaType, score float64) *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:     p.title(series),
+			Year:      yearOf(series),
+			Overview:  stripHTML(entry.Description),
+			Rating:    float32(entry.AverageScore) / 10,
+			Genres:    entry.Genres,
+			MediaType: mediaType,
+		},
+		Extended:   make(map[string]interface{}),
+		Sources:    make(map[string]string),
+		IDs:        map[string]string{"anilist_id": strconv.Itoa(entry.ID)},
+		Confidence: score,
+	}
+	if entry.IDMal > 0 {
+		meta.IDs["mal_id"] = strconv.Itoa(entry.IDMal)
+	}
+	for key, value := range map[string]string{
+		"romaji_title":  series.Title.Romaji,
+		"english_title": series.Title.English,
+		"native_title":  series.Title.Native,
+	} {
+		if value != "" {
+			meta.Extended[key] = value
+		}
+	}
+	return meta
+}
+
+// title returns the configured title language, falling back to romaji.
+func (p *Provider) title(m *media) string {
+	var preferred string
+	switch p.titleLanguage {
+	case TitleEnglish:
+		preferred = m.Title.English
+	case TitleNative:
+		preferred = m.Title.Native
+	}
+	if preferred = strings.TrimSpace(preferred); preferred != "" {
+		return preferred
+	}
+	return strings.TrimSpace(m.Title.Romaji)
+}
+
+// credit records this provider as the source of every populated field.
+func credit(meta *provider.Metadata) {
+	populated := map[string]bool{
+		"title":    meta.Core.Title != "",
+		"year":     meta.Core.Year != "",
+		"overview": meta.Core.Overview != "",
+		"rating":   meta.Core.Rating > 0,
+		"genres":   len(meta.Core.Genres) > 0,
+	}
+	for field, ok := range populated {
+		if ok {
+			meta.Sources[field] = providerName
+		}
+	}
+	for key := range meta.Extended {
+		meta.Sources[key] = providerName
+	}
+	for key := range meta.IDs {
+		meta.Sources[key] = providerName
+	}
+}
+
+func yearOf(m *media) string {
+	if m.StartDate.Year > 0 {
+		return strconv.Itoa(m.StartDate.Year)
+	}
+	if m.SeasonYear > 0 {
+		return strconv.Itoa(m.SeasonYear)
+	}
+	return ""
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML removes the markup AniList leaves in descriptions.
+func stripHTML(value string) string {
+	return strings.TrimSpace(htmlTag.ReplaceAllString(value, ""))
+}
@@ -0,0 +1,262 @@
+// Package anilist provides anime metadata from the AniList GraphQL API.
+// AniList keeps every cour of a series as its own entry linked by sequel
+// relations, which lets fansub style season and absolute episode numbers be
+// mapped onto the right entry.
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+const (
+	providerName = "anilist"
+
+	// DefaultURL is the public AniList GraphQL endpoint. It needs no API key.
+	DefaultURL = "https://graphql.anilist.co"
+
+	// Title languages accepted by the title_language setting.
+	TitleRomaji  = "romaji"
+	TitleEnglish = "english"
+	TitleNative  = "native"
+)
+
+// Provider implements the provider.Provider interface for AniList.
+type Provider struct {
+	httpClient    *http.Client
+	endpoint      string
+	titleLanguage string
+	config        map[string]interface{}
+}
+
+// New creates a new AniList provider instance.
+func New() *Provider {
+	return &Provider{
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		endpoint:      DefaultURL,
+		titleLanguage: TitleRomaji,
+		config:        make(map[string]interface{}),
+	}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// Description returns a human readable description of the provider.
+func (p *Provider) Description() string {
+	return "AniList anime metadata with romaji titles and absolute episode numbers"
+}
+
+// Capabilities returns what this provider can handle.
+func (p *Provider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{
+		MediaTypes: []provider.MediaType{
+			provider.MediaTypeMovie,
+			provider.MediaTypeShow,
+			provider.MediaTypeSeason,
+			provider.MediaTypeEpisode,
+		},
+		RequiresAuth: false,
+		Priority:     80,
+	}
+}
+
+// SupportedVariables returns the template variables supported by AniList.
+func (p *Provider) SupportedVariables() []provider.TemplateVariable {
+	allTypes := []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode}
+	seriesTypes := []provider.MediaType{provider.MediaTypeSeason, provider.MediaTypeEpisode}
+
+	return []provider.TemplateVariable{
+		{
+			Name:        "romaji_title",
+			DisplayName: "Romaji Title",
+			Description: "Japanese title in romaji",
+			MediaTypes:  allTypes,
+			Example:     "Shingeki no Kyojin",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "english_title",
+			DisplayName: "English Title",
+			Description: "Official English title",
+			MediaTypes:  allTypes,
+			Example:     "Attack on Titan",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "native_title",
+			DisplayName: "Native Title",
+			Description: "Title in its original script",
+			MediaTypes:  allTypes,
+			Example:     "進撃の巨人",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "absolute_episode",
+			DisplayName: "Absolute Episode",
+			Description: "Episode number counted from the first episode of the series",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeEpisode},
+			Example:     "28",
+			Category:    "basic",
+			Format:      "number",
+			Provider:    providerName,
+		},
+		{
+			Name:        "cour",
+			DisplayName: "Cour",
+			Description: "Position of the AniList entry in the series, starting at 1",
+			MediaTypes:  seriesTypes,
+			Example:     "2",
+			Category:    "basic",
+			Format:      "number",
+			Provider:    providerName,
+		},
+		{
+			Name:        "total_episodes",
+			DisplayName: "Total Episodes",
+			Description: "Episode count of the AniList entry",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason},
+			Example:     "25",
+			Category:    "basic",
+			Format:      "number",
+			Provider:    providerName,
+		},
+		{
+			Name:        "anilist_id",
+			DisplayName: "AniList ID",
+			Description: "AniList ID of the entry",
+			MediaTypes:  allTypes,
+			Example:     "16498",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "mal_id",
+			DisplayName: "MyAnimeList ID",
+			Description: "MyAnimeList ID of the entry",
+			MediaTypes:  allTypes,
+			Example:     "16498",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+	}
+}
+
+// ConfigSchema returns the configuration schema for this provider.
+func (p *Provider) ConfigSchema() provider.ConfigSchema {
+	return provider.ConfigSchema{
+		Fields: []provider.ConfigField{
+			{
+				Name:        "base_url",
+				DisplayName: "Endpoint",
+				Type:        provider.ConfigFieldTypeString,
+				Required:    false,
+				Default:     DefaultURL,
+				Description: "AniList GraphQL endpoint; point it at a local stand-in for testing",
+			},
+			{
+				Name:        "title_language",
+				DisplayName: "Title Language",
+				Type:        provider.ConfigFieldTypeSelect,
+				Required:    false,
+				Default:     TitleRomaji,
+				Description: "Which AniList title is used for {title}",
+				Validation: &provider.ConfigFieldValidation{
+					Options: []provider.ConfigFieldOption{
+						{Value: TitleRomaji, Label: "Romaji", Description: ""},
+						{Value: TitleEnglish, Label: "English", Description: ""},
+						{Value: TitleNative, Label: "Native", Description: ""},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure applies configuration to the provider. AniList needs no
+// credentials, so every setting is optional.
+func (p *Provider) Configure(config map[string]interface{}) error {
+	endpoint := DefaultURL
+	if raw, ok := config["base_url"]; ok {
+		value, isString := raw.(string)
+		if !isString {
+			return fmt.Errorf("base_url must be a string")
+		}
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			endpoint = trimmed
+		}
+	}
+
+	titleLanguage := TitleRomaji
+	if raw, ok := config["title_language"]; ok {
+		value, isString := raw.(string)
+		if !isString {
+			return fmt.Errorf("title_language must be a string")
+		}
+		switch value = strings.ToLower(strings.TrimSpace(value)); value {
+		case "":
+		case TitleRomaji, TitleEnglish, TitleNative:
+			titleLanguage = value
+		default:
+			return fmt.Errorf("title_language must be %s, %s or %s", TitleRomaji, TitleEnglish, TitleNative)
+		}
+	}
+
+	if p.httpClient == nil {
+		p.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	p.endpoint = endpoint
+	p.titleLanguage = titleLanguage
+	p.config = config
+	return nil
+}
+
+// Fetch retrieves metadata for the given request.
+func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch request.MediaType {
+	case provider.MediaTypeMovie:
+		return p.fetchMovie(ctx, request)
+	case provider.MediaTypeShow:
+		return p.fetchShow(ctx, request)
+	case provider.MediaTypeSeason:
+		return p.fetchSeason(ctx, request)
+	case provider.MediaTypeEpisode:
+		return p.fetchEpisode(ctx, request)
+	default:
+		return nil, &provider.ProviderError{Provider: providerName, Code: "UNSUPPORTED_MEDIA_TYPE", Message: fmt.Sprintf("unsupported media type: %s", request.MediaType), Retry: false}
+	}
+}
+
+// ExactID returns the AniList ID behind metadata from this provider.
+func (p *Provider) ExactID(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	return meta.IDs["anilist_id"]
+}
+
+// mapError maps AniList HTTP and GraphQL errors to provider errors by their
+// status.
+func (p *Provider) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if provErr := provider.MapStatusError(providerName, "AniList", err); provErr != nil {
+		return provErr
+	}
+	return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: "AniList error: " + err.Error(), Retry: false}
+}
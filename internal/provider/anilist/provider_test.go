@@ -0,0 +1,251 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Three cours of one show linked by sequel relations, plus a movie related to
+// the second cour that the chain has to skip. The last cour is still airing
+// so it has no episode count.
+var standInMedia = map[int]string{
+	1: `{"id": 1, "idMal": 101, "format": "TV", "episodes": 25, "startDate": {"year": 2013},
+		"title": {"romaji": "Shingeki no Kyojin", "english": "Attack on Titan", "native": "進撃の巨人"},
+		"genres": ["Action", "Drama"], "averageScore": 85, "description": "Humanity <i>fights</i> back.",
+		"relations": {"edges": [{"relationType": "SEQUEL", "node": {"id": 2, "format": "TV"}}]}}`,
+	2: `{"id": 2, "idMal": 102, "format": "TV", "episodes": 12, "startDate": {"year": 2017},
+		"title": {"romaji": "Shingeki no Kyojin 2", "english": "Attack on Titan Season 2", "native": "進撃の巨人2"},
+		"genres": ["Action"], "averageScore": 84,
+		"relations": {"edges": [
+			{"relationType": "PREQUEL", "node": {"id": 1, "format": "TV"}},
+			{"relationType": "SEQUEL", "node": {"id": 9, "format": "MOVIE"}},
+			{"relationType": "SEQUEL", "node": {"id": 3, "format": "TV"}}]}}`,
+	3: `{"id": 3, "idMal": 0, "format": "TV", "episodes": null, "startDate": {"year": 2018},
+		"title": {"romaji": "Shingeki no Kyojin 3"},
+		"relations": {"edges": [{"relationType": "PREQUEL", "node": {"id": 2, "format": "TV"}}]}}`,
+}
+
+// newStandIn starts a local GraphQL server answering Media lookups by ID and
+// searches for the first cour, and returns a provider pointed at it.
+func newStandIn(t *testing.T, config map[string]interface{}) *Provider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				ID     int    `json:"id"`
+				Search string `json:"search"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case body.Variables.Search == "Attack on Titan":
+			fmt.Fprintf(w, `{"data": {"Page": {"media": [%s, %s]}}}`, standInMedia[2], standInMedia[1])
+		case body.Variables.Search != "":
+			fmt.Fprint(w, `{"data": {"Page": {"media": []}}}`)
+		case standInMedia[body.Variables.ID] != "":
+			fmt.Fprintf(w, `{"data": {"Media": %s}}`, standInMedia[body.Variables.ID])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"data": {"Media": null}, "errors": [{"message": "Not Found.", "status": 404}]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	config["base_url"] = srv.URL
+	prov := New()
+	if err := prov.Configure(config); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	return prov
+}
+
+func TestConfigure(t *testing.T) {
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	if prov.endpoint != DefaultURL || prov.titleLanguage != TitleRomaji {
+		t.Errorf("defaults = (%q, %q), want (%q, %q)", prov.endpoint, prov.titleLanguage, DefaultURL, TitleRomaji)
+	}
+	if err := prov.Configure(map[string]interface{}{"title_language": "klingon"}); err == nil {
+		t.Errorf("Configure(title_language: klingon) succeeded, want error")
+	}
+	if err := prov.Configure(map[string]interface{}{"base_url": 1}); err == nil {
+		t.Errorf("Configure(base_url: 1) succeeded, want error")
+	}
+}
+
+func TestFetchShow(t *testing.T) {
+	prov := newStandIn(t, nil)
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeShow,
+		Name:      "Attack on Titan",
+		Year:      "2013",
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+
+	wantCore := provider.CoreMetadata{
+		Title:     "Shingeki no Kyojin",
+		Year:      "2013",
+		Overview:  "Humanity fights back.",
+		Rating:    8.5,
+		Genres:    []string{"Action", "Drama"},
+		MediaType: provider.MediaTypeShow,
+	}
+	if diff := cmp.Diff(wantCore, meta.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	wantExtended := map[string]interface{}{
+		"romaji_title":   "Shingeki no Kyojin",
+		"english_title":  "Attack on Titan",
+		"native_title":   "進撃の巨人",
+		"total_episodes": 25,
+	}
+	if diff := cmp.Diff(wantExtended, meta.Extended); diff != "" {
+		t.Errorf("Extended mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"anilist_id": "1", "mal_id": "101"}, meta.IDs); diff != "" {
+		t.Errorf("IDs mismatch (-want +got):\n%s", diff)
+	}
+	if meta.Sources["romaji_title"] != providerName {
+		t.Errorf("Sources = %v, want fields credited to %s", meta.Sources, providerName)
+	}
+}
+
+func TestFetchShowTitleLanguage(t *testing.T) {
+	prov := newStandIn(t, map[string]interface{}{"title_language": "english"})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, ID: "3"})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Core.Title != "Attack on Titan" || meta.IDs["anilist_id"] != "1" {
+		t.Errorf("Fetch(ID 3) = %q (anilist_id %s), want the first cour's English title", meta.Core.Title, meta.IDs["anilist_id"])
+	}
+}
+
+func TestFetchEpisodeMapsCours(t *testing.T) {
+	prov := newStandIn(t, nil)
+
+	tests := []struct {
+		name         string
+		season       int
+		episode      int
+		wantSeason   int
+		wantEpisode  int
+		wantID       string
+		wantAbsolute interface{}
+	}{
+		{"first cour", 1, 3, 1, 3, "1", 3},
+		{"season maps to cour", 2, 4, 2, 4, "2", 29},
+		{"absolute rolls over", 1, 30, 2, 5, "2", 30},
+		{"absolute skips movies", 1, 40, 3, 3, "3", 40},
+		{"past the end of an airing cour", 3, 80, 3, 80, "3", 117},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+				MediaType: provider.MediaTypeEpisode,
+				Name:      "Attack on Titan",
+				Season:    tt.season,
+				Episode:   tt.episode,
+			})
+			if err != nil {
+				t.Fatalf("Fetch() unexpected error: %v", err)
+			}
+			if meta.Core.SeasonNum != tt.wantSeason || meta.Core.EpisodeNum != tt.wantEpisode {
+				t.Errorf("S%dE%d mapped to S%dE%d, want S%dE%d", tt.season, tt.episode, meta.Core.SeasonNum, meta.Core.EpisodeNum, tt.wantSeason, tt.wantEpisode)
+			}
+			if meta.IDs["anilist_id"] != tt.wantID {
+				t.Errorf("anilist_id = %q, want %q", meta.IDs["anilist_id"], tt.wantID)
+			}
+			if meta.Extended["absolute_episode"] != tt.wantAbsolute {
+				t.Errorf("absolute_episode = %v, want %v", meta.Extended["absolute_episode"], tt.wantAbsolute)
+			}
+			if meta.Core.Title != "Shingeki no Kyojin" {
+				t.Errorf("Title = %q, want the series title", meta.Core.Title)
+			}
+		})
+	}
+}
+
+func TestFetchSeason(t *testing.T) {
+	prov := newStandIn(t, nil)
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeSeason, ID: "1", Season: 2})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Extended["cour"] != 2 || meta.Extended["total_episodes"] != 12 || meta.IDs["mal_id"] != "102" {
+		t.Errorf("Fetch(season 2) = %v %v, want the second cour", meta.Extended, meta.IDs)
+	}
+
+	_, err = prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeSeason, ID: "1", Season: 4})
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "NOT_FOUND" {
+		t.Errorf("Fetch(season 4) error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	prov := newStandIn(t, nil)
+
+	tests := []struct {
+		name    string
+		request provider.FetchRequest
+		code    string
+	}{
+		{"unknown id", provider.FetchRequest{MediaType: provider.MediaTypeShow, ID: "404"}, "NOT_FOUND"},
+		{"no search results", provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Nothing"}, "NOT_FOUND"},
+		{"missing title", provider.FetchRequest{MediaType: provider.MediaTypeShow}, "INVALID_REQUEST"},
+		{"invalid episode", provider.FetchRequest{MediaType: provider.MediaTypeEpisode, ID: "1", Season: 1}, "INVALID_REQUEST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := prov.Fetch(context.Background(), tt.request)
+			var provErr *provider.ProviderError
+			if !errors.As(err, &provErr) || provErr.Code != tt.code {
+				t.Errorf("Fetch() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestFetchRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"data": null, "errors": [{"message": "Too Many Requests.", "status": 429}]}`)
+	}))
+	defer srv.Close()
+
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"base_url": srv.URL}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+
+	_, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "Attack on Titan"})
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "RATE_LIMITED" || !provErr.Retry || provErr.RetryAfter != 60 {
+		t.Errorf("Fetch() error = %#v, want RATE_LIMITED retried after the 60s Retry-After", err)
+	}
+}
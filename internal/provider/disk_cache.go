@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached metadata stays fresh for providers
+// without a TTL of their own.
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+const (
+	cacheLockRetry = 50 * time.Millisecond
+	cacheLockWait  = 10 * time.Second
+	// cacheLockStale is the age after which a lock left behind by a crashed
+	// run is removed.
+	cacheLockStale = time.Minute
+)
+
+// CacheEntry is one cached provider response.
+type CacheEntry struct {
+	Provider string          `json:"provider"`
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+// CacheStats summarizes the cached entries of one provider.
+type CacheStats struct {
+	Provider string
+	Entries  int
+	Expired  int
+	Oldest   time.Time
+	Newest   time.Time
+}
+
+// DiskCache is a JSON file of provider responses keyed by provider and
+// request. Entries older than their provider's TTL are ignored. Changes are
+// kept in memory until Flush, which merges them with whatever other runs
+// wrote in the meantime. It is safe for concurrent use.
+type DiskCache struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]CacheEntry
+	ttls    map[string]time.Duration
+	changed map[string]bool // keys set or removed since the last flush
+	now     func() time.Time
+}
+
+// DefaultCachePath returns the location of the metadata cache.
+func DefaultCachePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".title-tidy", "cache", "metadata.json"), nil
+}
+
+// OpenDiskCache loads the cache at path. A missing file yields an empty
+// cache.
+func OpenDiskCache(path string) (*DiskCache, error) {
+	c := &DiskCache{
+		path:    path,
+		ttls:    make(map[string]time.Duration),
+		changed: make(map[string]bool),
+		now:     time.Now,
+	}
+	entries, err := readCacheFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.entries = entries
+	return c, nil
+}
+
+// CacheKey builds the key a request is cached under. Names are compared
+// case-insensitively. Known IDs are part of the key because they can turn a
+// search into an exact lookup with a different result.
+func CacheKey(request FetchRequest) string {
+	fields := []string{
+		string(request.MediaType),
+		strings.ToLower(strings.TrimSpace(request.Name)),
+		request.Year,
+		fmt.Sprint(request.Season),
+		fmt.Sprint(request.Episode),
+		strings.TrimSpace(request.ID),
+		request.Language,
+	}
+	if ids := request.KnownIDs(); len(ids) > 0 {
+		known := make([]string, 0, len(ids))
+		for key, id := range ids {
+			known = append(known, key+"="+id)
+		}
+		sort.Strings(known)
+		fields = append(fields, strings.Join(known, ","))
+	}
+	if show := request.ShowName(); show != "" {
+		// Per-show settings follow the parsed title, so it can change the result
+		fields = append(fields, "show="+NormalizeTitle(show))
+	}
+	return strings.Join(fields, "|")
+}
+
+// SetTTL sets how long entries from providerName stay fresh. A TTL of zero
+// restores DefaultCacheTTL.
+func (c *DiskCache) SetTTL(providerName string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ttl <= 0 {
+		delete(c.ttls, providerName)
+		return
+	}
+	c.ttls[providerName] = ttl
+}
+
+// TTL returns how long entries from providerName stay fresh.
+func (c *DiskCache) TTL(providerName string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ttlLocked(providerName)
+}
+
+// Get returns a copy of the fresh metadata cached under key.
+func (c *DiskCache) Get(providerName, key string) (*Metadata, bool) {
+	return c.get(providerName, key, false)
+}
+
+// GetStale returns a copy of the metadata cached under key even when it is
+// past its TTL.
+func (c *DiskCache) GetStale(providerName, key string) (*Metadata, bool) {
+	return c.get(providerName, key, true)
+}
+
+func (c *DiskCache) get(providerName, key string, allowExpired bool) (*Metadata, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[entryKey(providerName, key)]
+	expired := ok && !allowExpired && c.expiredLocked(entry)
+	c.mu.RUnlock()
+	if !ok || expired {
+		return nil, false
+	}
+
+	meta, err := decodeMetadata(entry.Metadata)
+	if err != nil {
+		return nil, false
+	}
+	return meta, true
+}
+
+// Set caches meta under key.
+func (c *DiskCache) Set(providerName, key string, meta *Metadata) {
+	if meta == nil {
+		return
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := entryKey(providerName, key)
+	c.entries[id] = CacheEntry{Provider: providerName, Key: key, StoredAt: c.now(), Metadata: data}
+	c.changed[id] = true
+}
+
+// Entries returns every cached entry, expired or not, sorted by provider
+// and key.
+func (c *DiskCache) Entries() []CacheEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Provider != entries[j].Provider {
+			return entries[i].Provider < entries[j].Provider
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Expired reports whether entry is past its provider's TTL.
+func (c *DiskCache) Expired(entry CacheEntry) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.expiredLocked(entry)
+}
+
+// Stats summarizes the cache per provider, sorted by provider name.
+func (c *DiskCache) Stats() []CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	byProvider := make(map[string]*CacheStats)
+	for _, entry := range c.entries {
+		stats, ok := byProvider[entry.Provider]
+		if !ok {
+			stats = &CacheStats{Provider: entry.Provider, Oldest: entry.StoredAt, Newest: entry.StoredAt}
+			byProvider[entry.Provider] = stats
+		}
+		stats.Entries++
+		if c.expiredLocked(entry) {
+			stats.Expired++
+		}
+		if entry.StoredAt.Before(stats.Oldest) {
+			stats.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(stats.Newest) {
+			stats.Newest = entry.StoredAt
+		}
+	}
+
+	result := make([]CacheStats, 0, len(byProvider))
+	for _, stats := range byProvider {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Provider < result[j].Provider })
+	return result
+}
+
+// Purge removes the entries match selects and returns how many were
+// removed. Call Flush to write the removal to disk.
+func (c *DiskCache) Purge(match func(CacheEntry) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, entry := range c.entries {
+		if match(entry) {
+			delete(c.entries, key)
+			c.changed[key] = true
+			removed++
+		}
+	}
+	return removed
+}
+
+// Flush writes pending changes to disk. The file is locked, re-read, and
+// merged so entries written by another run since this cache was opened are
+// kept, then replaced atomically.
+func (c *DiskCache) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.changed) == 0 || c.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	unlock, err := lockCacheFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	onDisk, err := readCacheFile(c.path)
+	if err != nil {
+		return err
+	}
+	for key := range c.changed {
+		if entry, ok := c.entries[key]; ok {
+			onDisk[key] = entry
+		} else {
+			delete(onDisk, key)
+		}
+	}
+
+	if err := writeCacheFile(c.path, onDisk); err != nil {
+		return err
+	}
+	c.entries = onDisk
+	c.changed = make(map[string]bool)
+	return nil
+}
+
+func (c *DiskCache) ttlLocked(providerName string) time.Duration {
+	if ttl, ok := c.ttls[providerName]; ok {
+		return ttl
+	}
+	return DefaultCacheTTL
+}
+
+func (c *DiskCache) expiredLocked(entry CacheEntry) bool {
+	return c.now().Sub(entry.StoredAt) > c.ttlLocked(entry.Provider)
+}
+
+func entryKey(providerName, key string) string {
+	return providerName + "\x00" + key
+}
+
+// decodeMetadata keeps numbers as json.Number so integers in Extended still
+// print without a decimal point or exponent.
+func decodeMetadata(data json.RawMessage) (*Metadata, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var meta Metadata
+	if err := decoder.Decode(&meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func readCacheFile(path string) (map[string]CacheEntry, error) {
+	entries := make(map[string]CacheEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to read metadata cache: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return entries, nil
+	}
+
+	var list []CacheEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata cache: %w", err)
+	}
+	for _, entry := range list {
+		entries[entryKey(entry.Provider, entry.Key)] = entry
+	}
+	return entries, nil
+}
+
+func writeCacheFile(path string, entries map[string]CacheEntry) error {
+	list := make([]CacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Provider != list[j].Provider {
+			return list[i].Provider < list[j].Provider
+		}
+		return list[i].Key < list[j].Key
+	})
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata cache: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated cache.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	return nil
+}
+
+// lockCacheFile takes an exclusive lock next to path so two runs never
+// merge into the file at the same time. The returned func releases it.
+func lockCacheFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(cacheLockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock metadata cache: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > cacheLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("metadata cache is locked by another run; remove %s if no other run is active", lockPath)
+		}
+		time.Sleep(cacheLockRetry)
+	}
+}
@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestDiskCache(t *testing.T, path string, now time.Time) *DiskCache {
+	t.Helper()
+	cache, err := OpenDiskCache(path)
+	if err != nil {
+		t.Fatalf("OpenDiskCache() unexpected error: %v", err)
+	}
+	cache.now = func() time.Time { return now }
+	return cache
+}
+
+func TestDiskCacheGetSet(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestDiskCache(t, filepath.Join(t.TempDir(), "metadata.json"), now)
+
+	key := CacheKey(FetchRequest{MediaType: MediaTypeShow, Name: "Breaking Bad", Year: "2008"})
+	meta := &Metadata{
+		Core:     CoreMetadata{Title: "Breaking Bad", Year: "2008"},
+		Extended: map[string]interface{}{"episode_count": 62},
+		IDs:      map[string]string{"tmdb_id": "1396"},
+	}
+	cache.Set("tmdb", key, meta)
+
+	got, ok := cache.Get("tmdb", key)
+	if !ok {
+		t.Fatalf("Get() missed an entry that was just set")
+	}
+	if got.Core.Title != "Breaking Bad" || got.IDs["tmdb_id"] != "1396" {
+		t.Errorf("Get() = %+v, want the cached metadata", got)
+	}
+	if s := fmt.Sprint(got.Extended["episode_count"]); s != "62" {
+		t.Errorf("Extended[episode_count] prints as %q, want 62", s)
+	}
+
+	if _, ok := cache.Get("omdb", key); ok {
+		t.Errorf("Get() for another provider hit, want miss")
+	}
+	if other := CacheKey(FetchRequest{MediaType: MediaTypeShow, Name: "breaking bad ", Year: "2008"}); other != key {
+		t.Errorf("CacheKey() differs by case and spacing: %q vs %q", other, key)
+	}
+}
+
+func TestDiskCacheTTL(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestDiskCache(t, filepath.Join(t.TempDir(), "metadata.json"), now)
+	cache.SetTTL("tvmaze", 24*time.Hour)
+	cache.Set("tvmaze", "a", &Metadata{})
+	cache.Set("tmdb", "a", &Metadata{})
+
+	cache.now = func() time.Time { return now.Add(48 * time.Hour) }
+	if _, ok := cache.Get("tvmaze", "a"); ok {
+		t.Errorf("Get() returned an entry past its 24h TTL")
+	}
+	if _, ok := cache.Get("tmdb", "a"); !ok {
+		t.Errorf("Get() missed an entry within the default TTL")
+	}
+
+	cache.SetTTL("tvmaze", 0)
+	if got := cache.TTL("tvmaze"); got != DefaultCacheTTL {
+		t.Errorf("TTL() after reset = %v, want %v", got, DefaultCacheTTL)
+	}
+
+	stats := cache.Stats()
+	want := []CacheStats{
+		{Provider: "tmdb", Entries: 1, Oldest: now, Newest: now},
+		{Provider: "tvmaze", Entries: 1, Oldest: now, Newest: now},
+	}
+	if diff := cmp.Diff(want, stats); diff != "" {
+		t.Errorf("Stats() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiskCachePurge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestDiskCache(t, path, now.Add(-30*24*time.Hour))
+	cache.Set("tvdb", "old", &Metadata{})
+	cache.now = func() time.Time { return now }
+	cache.Set("tvdb", "new", &Metadata{})
+	cache.Set("tmdb", "new", &Metadata{})
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	removed := cache.Purge(func(e CacheEntry) bool { return e.StoredAt.Before(now.Add(-24 * time.Hour)) })
+	if removed != 1 {
+		t.Errorf("Purge(older than a day) removed %d, want 1", removed)
+	}
+	removed = cache.Purge(func(e CacheEntry) bool { return e.Provider == "tvdb" })
+	if removed != 1 {
+		t.Errorf("Purge(tvdb) removed %d, want 1", removed)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	reopened := newTestDiskCache(t, path, now)
+	var keys []string
+	for _, entry := range reopened.Entries() {
+		keys = append(keys, entry.Provider+"/"+entry.Key)
+	}
+	if diff := cmp.Diff([]string{"tmdb/new"}, keys); diff != "" {
+		t.Errorf("entries after purge mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiskCacheFlushMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "metadata.json")
+	now := time.Now()
+
+	// Two runs open the same empty cache and each writes its own lookups.
+	first := newTestDiskCache(t, path, now)
+	second := newTestDiskCache(t, path, now)
+	first.Set("tmdb", "a", &Metadata{Core: CoreMetadata{Title: "A"}})
+	second.Set("tvdb", "b", &Metadata{Core: CoreMetadata{Title: "B"}})
+
+	if err := first.Flush(); err != nil {
+		t.Fatalf("first Flush() unexpected error: %v", err)
+	}
+	if err := second.Flush(); err != nil {
+		t.Fatalf("second Flush() unexpected error: %v", err)
+	}
+
+	reopened := newTestDiskCache(t, path, now)
+	if _, ok := reopened.Get("tmdb", "a"); !ok {
+		t.Errorf("entry from the first run was lost")
+	}
+	if _, ok := reopened.Get("tvdb", "b"); !ok {
+		t.Errorf("entry from the second run was lost")
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestDiskCacheFlushWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(path+".lock", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.Remove(path + ".lock")
+	}()
+
+	cache := newTestDiskCache(t, path, time.Now())
+	cache.Set("tmdb", "a", &Metadata{})
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("cache file not written: %v", err)
+	}
+}
+
+func TestOpenDiskCacheRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDiskCache(path); err == nil {
+		t.Errorf("OpenDiskCache() on a corrupt file succeeded, want error")
+	}
+}
+
+func TestResilientUsesCache(t *testing.T) {
+	r, calls, _ := newTestResilient(DefaultPolicy())
+	cache := newTestDiskCache(t, filepath.Join(t.TempDir(), "metadata.json"), time.Now())
+	r.SetCache(cache)
+
+	req := FetchRequest{MediaType: MediaTypeMovie, Name: "Heat", Year: "1995"}
+	for i := 0; i < 2; i++ {
+		meta, err := r.Fetch(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Fetch() unexpected error: %v", err)
+		}
+		if meta.Core.Title != "Found" {
+			t.Errorf("Fetch() title = %q, want Found", meta.Core.Title)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("provider called %d times, want 1 with the second fetch cached", *calls)
+	}
+
+	// A different configuration must not be served the old results.
+	if err := r.Configure(map[string]interface{}{"language": "de-DE"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	if _, err := r.Fetch(context.Background(), req); err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("provider called %d times after reconfiguring, want 2", *calls)
+	}
+}
+
+func TestResilientCachesSeasonEpisodes(t *testing.T) {
+	stub := &batchStubProvider{}
+	r := NewResilient(stub, DefaultPolicy())
+	cache := newTestDiskCache(t, filepath.Join(t.TempDir(), "metadata.json"), time.Now())
+	r.SetCache(cache)
+
+	req := FetchRequest{MediaType: MediaTypeSeason, ID: "42", Name: "Lost", Season: 1}
+	for i := 0; i < 2; i++ {
+		episodes, err := r.FetchSeasonEpisodes(context.Background(), req)
+		if err != nil {
+			t.Fatalf("FetchSeasonEpisodes() unexpected error: %v", err)
+		}
+		if len(episodes) != 2 || episodes[1].Core.EpisodeName != "Pilot" {
+			t.Fatalf("FetchSeasonEpisodes() = %+v, want episodes 1 and 2", episodes)
+		}
+	}
+	if len(stub.batches) != 1 {
+		t.Errorf("provider fetched %d seasons, want 1 with the second cached", len(stub.batches))
+	}
+
+	// Batched episodes are not served to single episode fetches, whose
+	// payload may have more fields.
+	if _, ok := cache.Get(stub.Name(), CacheKey(FetchRequest{MediaType: MediaTypeEpisode, ID: "42", Name: "Lost", Season: 1, Episode: 1})); ok {
+		t.Errorf("batched episode cached under the single episode key")
+	}
+
+	r.SetOffline(true)
+	if _, err := r.FetchSeasonEpisodes(context.Background(), FetchRequest{MediaType: MediaTypeSeason, ID: "42", Name: "Lost", Season: 2}); !IsCacheMiss(err) {
+		t.Errorf("offline FetchSeasonEpisodes() of an uncached season error = %v, want a cache miss", err)
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EpisodeOrder names the order a show's seasons and episodes are numbered in.
+type EpisodeOrder string
+
+const (
+	EpisodeOrderAired    EpisodeOrder = "aired"    // Broadcast order, the providers' default
+	EpisodeOrderDVD      EpisodeOrder = "dvd"      // Order of the home video release
+	EpisodeOrderAbsolute EpisodeOrder = "absolute" // One running episode count, ignoring seasons
+	EpisodeOrderStory    EpisodeOrder = "story"    // Chronological story order
+)
+
+// EpisodeOrders picks the episode order of each show: the entry in Shows
+// for the show's title when there is one, otherwise Default.
+type EpisodeOrders struct {
+	Default EpisodeOrder
+	Shows   map[string]EpisodeOrder // keyed by NormalizeTitle of the show title
+}
+
+// ParseEpisodeOrder validates a configured episode order. Blank means aired.
+func ParseEpisodeOrder(value string) (EpisodeOrder, error) {
+	switch order := EpisodeOrder(strings.ToLower(strings.TrimSpace(value))); order {
+	case "":
+		return EpisodeOrderAired, nil
+	case EpisodeOrderAired, EpisodeOrderDVD, EpisodeOrderAbsolute, EpisodeOrderStory:
+		return order, nil
+	default:
+		return "", fmt.Errorf("unknown episode order %q: use aired, dvd, absolute or story", value)
+	}
+}
+
+// ParseEpisodeOrders reads the "episode_order" and "episode_orders" provider
+// settings. episode_orders maps show titles to their order, as a
+// map[string]string or, decoded from JSON, a map[string]interface{}.
+func ParseEpisodeOrders(config map[string]interface{}) (EpisodeOrders, error) {
+	value, _ := config["episode_order"].(string)
+	def, err := ParseEpisodeOrder(value)
+	if err != nil {
+		return EpisodeOrders{}, err
+	}
+	orders := EpisodeOrders{Default: def}
+
+	shows := make(map[string]string)
+	switch v := config["episode_orders"].(type) {
+	case map[string]string:
+		shows = v
+	case map[string]interface{}:
+		for show, order := range v {
+			s, ok := order.(string)
+			if !ok {
+				return EpisodeOrders{}, fmt.Errorf("episode order for %q must be a string", show)
+			}
+			shows[show] = s
+		}
+	}
+	for show, value := range shows {
+		order, err := ParseEpisodeOrder(value)
+		if err != nil {
+			return EpisodeOrders{}, fmt.Errorf("%s: %w", show, err)
+		}
+		if orders.Shows == nil {
+			orders.Shows = make(map[string]EpisodeOrder, len(shows))
+		}
+		orders.Shows[NormalizeTitle(show)] = order
+	}
+	return orders, nil
+}
+
+// For returns the episode order of the show known by the given titles. The
+// first title with an entry in Shows wins; blank titles are skipped.
+func (o EpisodeOrders) For(titles ...string) EpisodeOrder {
+	for _, title := range titles {
+		if title == "" {
+			continue
+		}
+		if order, ok := o.Shows[NormalizeTitle(title)]; ok {
+			return order
+		}
+	}
+	if o.Default == "" {
+		return EpisodeOrderAired
+	}
+	return o.Default
+}
+
+// EpisodeOrderField is the config field for the default episode order of
+// providers that support alternate orders.
+func EpisodeOrderField() ConfigField {
+	return ConfigField{
+		Name:        "episode_order",
+		DisplayName: "Episode Order",
+		Type:        ConfigFieldTypeSelect,
+		Required:    false,
+		Default:     string(EpisodeOrderAired),
+		Description: "Order episodes are numbered in; episode_orders overrides it per show title",
+		Validation: &ConfigFieldValidation{
+			Options: []ConfigFieldOption{
+				{Value: string(EpisodeOrderAired), Label: "Aired"},
+				{Value: string(EpisodeOrderDVD), Label: "DVD"},
+				{Value: string(EpisodeOrderAbsolute), Label: "Absolute"},
+				{Value: string(EpisodeOrderStory), Label: "Story"},
+			},
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseEpisodeOrders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    EpisodeOrders
+		wantErr bool
+	}{
+		{name: "empty", config: map[string]interface{}{}, want: EpisodeOrders{Default: EpisodeOrderAired}},
+		{
+			name:   "default and overrides",
+			config: map[string]interface{}{"episode_order": "DVD", "episode_orders": map[string]string{"Firefly": "story"}},
+			want:   EpisodeOrders{Default: EpisodeOrderDVD, Shows: map[string]EpisodeOrder{NormalizeTitle("Firefly"): EpisodeOrderStory}},
+		},
+		{
+			name:   "decoded json",
+			config: map[string]interface{}{"episode_orders": map[string]interface{}{"One Piece": "absolute"}},
+			want:   EpisodeOrders{Default: EpisodeOrderAired, Shows: map[string]EpisodeOrder{NormalizeTitle("One Piece"): EpisodeOrderAbsolute}},
+		},
+		{name: "unknown default", config: map[string]interface{}{"episode_order": "broadcast"}, wantErr: true},
+		{name: "unknown override", config: map[string]interface{}{"episode_orders": map[string]string{"Firefly": "random"}}, wantErr: true},
+		{name: "non-string override", config: map[string]interface{}{"episode_orders": map[string]interface{}{"Firefly": 2}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseEpisodeOrders(tc.config)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseEpisodeOrders() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseEpisodeOrders() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEpisodeOrdersFor(t *testing.T) {
+	t.Parallel()
+
+	orders, err := ParseEpisodeOrders(map[string]interface{}{
+		"episode_order":  "dvd",
+		"episode_orders": map[string]string{"Firefly": "story"},
+	})
+	if err != nil {
+		t.Fatalf("ParseEpisodeOrders() error = %v", err)
+	}
+	if got := orders.For("firefly"); got != EpisodeOrderStory {
+		t.Errorf("For(firefly) = %q, want %q", got, EpisodeOrderStory)
+	}
+	if got := orders.For("Futurama"); got != EpisodeOrderDVD {
+		t.Errorf("For(Futurama) = %q, want %q", got, EpisodeOrderDVD)
+	}
+	if got := orders.For("Serenity", "Firefly"); got != EpisodeOrderStory {
+		t.Errorf("For(Serenity, Firefly) = %q, want %q", got, EpisodeOrderStory)
+	}
+	if got := orders.For("", "Futurama"); got != EpisodeOrderDVD {
+		t.Errorf("For(\"\", Futurama) = %q, want %q", got, EpisodeOrderDVD)
+	}
+	if got := (EpisodeOrders{}).For("Futurama"); got != EpisodeOrderAired {
+		t.Errorf("zero EpisodeOrders For() = %q, want %q", got, EpisodeOrderAired)
+	}
+}
@@ -0,0 +1,88 @@
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"gopkg.in/vansante/go-ffprobe.v2"
+)
+
+// cacheVersion is bumped when the variables built from a probe change, so
+// entries cached by older releases are probed again.
+const cacheVersion = "4"
+
+// runProbe executes the configured ffprobe binary and decodes its JSON
+// report. Errors ffprobe logs while reading a file it could still describe,
+// such as a truncated stream, are returned as problems. The process is
+// killed when ctx is done.
+func (p *Provider) runProbe(ctx context.Context, path string, extraOpts ...string) (*ffprobe.ProbeData, []string, error) {
+	args := append([]string{
+		"-loglevel", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+	}, extraOpts...)
+	args = append(args, path)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, fmt.Errorf("%s: %w %s", p.binary, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var data ffprobe.ProbeData
+	if err := json.Unmarshal(stdout.Bytes(), &data); err != nil {
+		return nil, nil, fmt.Errorf("decode ffprobe output: %w", err)
+	}
+	return &data, probeProblems(stderr.String()), nil
+}
+
+// probeProblems splits ffprobe's error log into distinct lines.
+func probeProblems(log string) []string {
+	var problems []string
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !slices.Contains(problems, line) {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// probeCacheKey identifies a file by path, size and modification time, so a
+// replaced or re-encoded file is probed again. ok is false when the file
+// can't be read.
+func probeCacheKey(path string) (key string, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("v%s|%s|%d|%d", cacheVersion, path, info.Size(), info.ModTime().UnixNano()), true
+}
+
+// cachedProbe returns the cached metadata for the file under key, adjusted
+// to the request that asked for it.
+func (p *Provider) cachedProbe(key string, request provider.FetchRequest) (*provider.Metadata, bool) {
+	if p.cache == nil || key == "" {
+		return nil, false
+	}
+	meta, ok := p.cache.Get(providerName, key)
+	if !ok {
+		return nil, false
+	}
+	meta.Core.MediaType = request.MediaType
+	meta.Core.Title = request.Name
+	return meta, true
+}
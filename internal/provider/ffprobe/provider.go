@@ -2,30 +2,48 @@ package ffprobe
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
 	"gopkg.in/vansante/go-ffprobe.v2"
 )
 
 const (
-	providerName = "ffprobe"
-	filePathKey  = "path"
+	providerName   = "ffprobe"
+	filePathKey    = "path"
+	defaultBinary  = "ffprobe"
+	defaultTimeout = 30 * time.Second
 )
 
-// probeFunc defines the function signature used to execute ffprobe.
-type probeFunc func(ctx context.Context, path string, extraOpts ...string) (*ffprobe.ProbeData, error)
+// probeFunc defines the function signature used to execute ffprobe. It
+// returns the decoded report and any errors logged while reading the file.
+type probeFunc func(ctx context.Context, path string, extraOpts ...string) (*ffprobe.ProbeData, []string, error)
 
 // Provider implements the provider.Provider interface for ffprobe-based metadata.
 type Provider struct {
-	probe probeFunc
+	probe   probeFunc
+	binary  string
+	timeout time.Duration
+	cache   *provider.DiskCache
 }
 
 // New creates a new ffprobe provider instance with default configuration.
 func New() *Provider {
-	return &Provider{
-		probe: ffprobe.ProbeURL,
+	p := &Provider{
+		binary:  defaultBinary,
+		timeout: defaultTimeout,
 	}
+	p.probe = p.runProbe
+	return p
+}
+
+// SetCache attaches the cache probe results are kept in. Results are keyed
+// by file path, size and modification time. A nil cache probes every file.
+func (p *Provider) SetCache(cache *provider.DiskCache) {
+	p.cache = cache
 }
 
 // Name returns the provider name.
@@ -46,6 +64,7 @@ func (p *Provider) Capabilities() provider.ProviderCapabilities {
 			provider.MediaTypeEpisode,
 		},
 		RequiresAuth: false,
+		RequiresFile: true,
 		Priority:     50,
 	}
 }
@@ -67,12 +86,49 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 		{
 			Name:        "video_resolution",
 			DisplayName: "Video Resolution",
-			Description: "Primary video resolution reported by ffprobe",
+			Description: "Resolution tier of the primary video stream",
 			MediaTypes:  mediaTypes,
 			Example:     "1080p",
 			Category:    "technical",
 			Provider:    providerName,
 		},
+		{
+			Name:        "video_resolution_label",
+			DisplayName: "Resolution Label",
+			Description: "Resolution name: 8K, 4K, 1440p, 1080p, 720p or SD",
+			MediaTypes:  mediaTypes,
+			Example:     "4K",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "hdr_format",
+			DisplayName: "HDR Format",
+			Description: "HDR format: Dolby Vision, HDR10+, HDR10 or HLG; empty for SDR",
+			MediaTypes:  mediaTypes,
+			Example:     "HDR10",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "video_bit_depth",
+			DisplayName: "Bit Depth",
+			Description: "Bit depth of the primary video stream",
+			MediaTypes:  mediaTypes,
+			Example:     "10bit",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "video_frame_rate",
+			DisplayName: "Frame Rate",
+			Description: "Frames per second of the primary video stream",
+			MediaTypes:  mediaTypes,
+			Example:     "23.976",
+			Category:    "technical",
+			Format:      "number",
+			Provider:    providerName,
+		},
 		{
 			Name:        "audio_codec",
 			DisplayName: "Audio Codec",
@@ -82,21 +138,115 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Category:    "technical",
 			Provider:    providerName,
 		},
+		{
+			Name:        "audio_channels",
+			DisplayName: "Audio Channels",
+			Description: "Channel layout of the primary audio stream",
+			MediaTypes:  mediaTypes,
+			Example:     "5.1",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "audio_object_format",
+			DisplayName: "Object Audio",
+			Description: "Atmos or DTS-X when the primary audio stream carries it",
+			MediaTypes:  mediaTypes,
+			Example:     "Atmos",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "audio_languages",
+			DisplayName: "Audio Languages",
+			Description: "Languages of the audio streams",
+			MediaTypes:  mediaTypes,
+			Example:     "eng, jpn",
+			Category:    "technical",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "subtitle_languages",
+			DisplayName: "Subtitle Languages",
+			Description: "Languages of the subtitle streams",
+			MediaTypes:  mediaTypes,
+			Example:     "eng, spa",
+			Category:    "technical",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "container",
+			DisplayName: "Container",
+			Description: "Container format of the file",
+			MediaTypes:  mediaTypes,
+			Example:     "mkv",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "duration",
+			DisplayName: "Duration",
+			Description: "Running time of the file",
+			MediaTypes:  mediaTypes,
+			Example:     "2h 49m",
+			Category:    "technical",
+			Provider:    providerName,
+		},
+		{
+			Name:        "bitrate",
+			DisplayName: "Bitrate",
+			Description: "Overall bitrate of the file",
+			MediaTypes:  mediaTypes,
+			Example:     "12.4 Mbps",
+			Category:    "technical",
+			Provider:    providerName,
+		},
 	}
 }
 
 // ConfigSchema returns the configuration schema for this provider.
 func (p *Provider) ConfigSchema() provider.ConfigSchema {
-	// Currently no configurable fields beyond enable/disable.
-	return provider.ConfigSchema{Fields: []provider.ConfigField{}}
+	return provider.ConfigSchema{Fields: []provider.ConfigField{
+		{
+			Name:        "binary_path",
+			DisplayName: "ffprobe Path",
+			Type:        provider.ConfigFieldTypeString,
+			Required:    false,
+			Default:     defaultBinary,
+			Description: "ffprobe executable to run; a bare name is looked up on the PATH",
+		},
+		{
+			Name:        "timeout_seconds",
+			DisplayName: "Timeout",
+			Type:        provider.ConfigFieldTypeInt,
+			Required:    false,
+			Default:     int(defaultTimeout / time.Second),
+			Description: "Seconds a single probe may take before it is stopped",
+			Validation:  &provider.ConfigFieldValidation{MinValue: 1},
+		},
+	}}
 }
 
 // Configure applies configuration to the provider.
 func (p *Provider) Configure(config map[string]interface{}) error {
-	if config == nil {
-		return nil
+	p.binary = defaultBinary
+	if path, ok := config["binary_path"].(string); ok && path != "" {
+		p.binary = path
 	}
 
+	p.timeout = defaultTimeout
+	switch v := config["timeout_seconds"].(type) {
+	case int:
+		if v > 0 {
+			p.timeout = time.Duration(v) * time.Second
+		}
+	case float64:
+		if v > 0 {
+			p.timeout = time.Duration(v * float64(time.Second))
+		}
+	}
 	return nil
 }
 
@@ -116,8 +266,26 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 		return nil, err
 	}
 
-	data, err := p.probe(context.Background(), path)
+	key, cacheable := probeCacheKey(path)
+	if meta, ok := p.cachedProbe(key, request); ok {
+		return meta, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	data, problems, err := p.probe(probeCtx, path)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, &provider.ProviderError{
+				Provider: providerName,
+				Code:     "TIMEOUT",
+				Message:  fmt.Sprintf("ffprobe timed out after %s for %s", p.timeout, path),
+				Retry:    false,
+			}
+		}
 		return nil, &provider.ProviderError{
 			Provider: providerName,
 			Code:     "PROBE_FAILED",
@@ -127,6 +295,13 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 	}
 
 	meta := p.buildMetadata(request, data)
+	if len(problems) > 0 {
+		// Kept for the integrity check, not a template variable
+		meta.Extended["probe_errors"] = strings.Join(problems, "\n")
+	}
+	if p.cache != nil && cacheable {
+		p.cache.Set(providerName, key, meta)
+	}
 	return meta, nil
 }
 
@@ -146,26 +321,40 @@ func (p *Provider) buildMetadata(request provider.FetchRequest, data *ffprobe.Pr
 		return meta
 	}
 
-	if videoStream := data.FirstVideoStream(); videoStream != nil {
-		codec := pickCodecName(videoStream)
-		if codec != "" {
-			meta.Extended["video_codec"] = codec
-			meta.Sources["video_codec"] = providerName
+	set := func(name, value string) {
+		if value != "" {
+			meta.Extended[name] = value
+			meta.Sources[name] = providerName
 		}
+	}
 
-		if resolution := formatVideoResolution(videoStream); resolution != "" {
-			meta.Extended["video_resolution"] = resolution
-			meta.Sources["video_resolution"] = providerName
-		}
+	if videoStream := data.FirstVideoStream(); videoStream != nil {
+		set("video_codec", pickCodecName(videoStream))
+		set("video_resolution", formatVideoResolution(videoStream))
+		set("video_resolution_label", formatResolutionLabel(videoStream))
+		set("hdr_format", formatHDR(videoStream))
+		set("video_bit_depth", formatBitDepth(videoStream))
+		set("video_frame_rate", formatFrameRate(videoStream))
 	}
 
 	if audioStream := data.FirstAudioStream(); audioStream != nil {
-		codec := pickCodecName(audioStream)
-		if codec != "" {
-			meta.Extended["audio_codec"] = codec
-			meta.Sources["audio_codec"] = providerName
-		}
+		set("audio_codec", pickCodecName(audioStream))
+		set("audio_channels", formatChannels(audioStream))
+		set("audio_object_format", formatObjectAudio(audioStream))
+	}
+
+	set("audio_languages", streamLanguages(data, ffprobe.StreamAudio))
+	set("subtitle_languages", streamLanguages(data, ffprobe.StreamSubtitle))
+	set("container", formatContainer(data.Format))
+	set("duration", formatDuration(data.Format))
+	if data.Format.DurationSeconds > 0 {
+		// Raw length for sample detection, not a template variable
+		meta.Extended["duration_seconds"] = int(data.Format.DurationSeconds)
 	}
+	// Stream counts for the integrity check
+	meta.Extended["video_streams"] = len(data.StreamType(ffprobe.StreamVideo))
+	meta.Extended["audio_streams"] = len(data.StreamType(ffprobe.StreamAudio))
+	set("bitrate", formatBitrate(data.Format))
 
 	return meta
 }
@@ -211,13 +400,3 @@ func pickCodecName(stream *ffprobe.Stream) string {
 	}
 	return stream.CodecLongName
 }
-
-func formatVideoResolution(stream *ffprobe.Stream) string {
-	if stream == nil {
-		return ""
-	}
-	if stream.Height <= 0 {
-		return ""
-	}
-	return fmt.Sprintf("%dp", stream.Height)
-}
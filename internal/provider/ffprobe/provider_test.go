@@ -3,6 +3,9 @@ package ffprobe
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
@@ -11,7 +14,7 @@ import (
 
 func TestFetch_Success(t *testing.T) {
 	p := New()
-	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, error) {
+	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, []string, error) {
 		return &ffprobeLib.ProbeData{
 			Format: &ffprobeLib.Format{},
 			Streams: []*ffprobeLib.Stream{
@@ -25,7 +28,7 @@ func TestFetch_Success(t *testing.T) {
 					CodecType: string(ffprobeLib.StreamAudio),
 				},
 			},
-		}, nil
+		}, nil, nil
 	}
 
 	req := provider.FetchRequest{
@@ -91,7 +94,7 @@ func TestFetch_MissingPath(t *testing.T) {
 
 func TestFetch_SkipsResolutionWhenHeightMissing(t *testing.T) {
 	p := New()
-	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, error) {
+	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, []string, error) {
 		return &ffprobeLib.ProbeData{
 			Format: &ffprobeLib.Format{},
 			Streams: []*ffprobeLib.Stream{
@@ -100,7 +103,7 @@ func TestFetch_SkipsResolutionWhenHeightMissing(t *testing.T) {
 					CodecType: string(ffprobeLib.StreamVideo),
 				},
 			},
-		}, nil
+		}, nil, nil
 	}
 
 	req := provider.FetchRequest{
@@ -148,3 +151,272 @@ func TestFetch_UnsupportedMediaType(t *testing.T) {
 		t.Errorf("ProviderError.Code = %v, want UNSUPPORTED_MEDIA_TYPE", provErr.Code)
 	}
 }
+
+func TestFetch_TechnicalVariables(t *testing.T) {
+	p := New()
+	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, []string, error) {
+		return &ffprobeLib.ProbeData{
+			Format: &ffprobeLib.Format{
+				Filename:        "/videos/example.mp4",
+				FormatName:      "mov,mp4,m4a,3gp,3g2,mj2",
+				DurationSeconds: 8130,
+				BitRate:         "24512000",
+			},
+			Streams: []*ffprobeLib.Stream{
+				{
+					CodecName:     "hevc",
+					CodecType:     string(ffprobeLib.StreamVideo),
+					Width:         3840,
+					Height:        1608,
+					PixFmt:        "yuv420p10le",
+					AvgFrameRate:  "24000/1001",
+					ColorTransfer: "smpte2084",
+					SideDataList: ffprobeLib.SideDataList{
+						{SideDataBase: ffprobeLib.SideDataBase{Type: "DOVI configuration record"}},
+					},
+				},
+				{
+					CodecName:     "truehd",
+					CodecType:     string(ffprobeLib.StreamAudio),
+					Profile:       "Dolby TrueHD + Dolby Atmos",
+					Channels:      8,
+					ChannelLayout: "7.1",
+					TagList:       ffprobeLib.Tags{"language": "eng"},
+				},
+				{
+					CodecName: "ac3",
+					CodecType: string(ffprobeLib.StreamAudio),
+					TagList:   ffprobeLib.Tags{"language": "jpn"},
+				},
+				{
+					CodecName: "subrip",
+					CodecType: string(ffprobeLib.StreamSubtitle),
+					TagList:   ffprobeLib.Tags{"language": "eng"},
+				},
+				{
+					CodecName: "subrip",
+					CodecType: string(ffprobeLib.StreamSubtitle),
+					TagList:   ffprobeLib.Tags{"language": "und"},
+				},
+			},
+		}, nil, nil
+	}
+
+	meta, err := p.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Name:      "Example",
+		Extra:     map[string]interface{}{"path": "/videos/example.mp4"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"video_codec":            "hevc",
+		"video_resolution":       "2160p",
+		"video_resolution_label": "4K",
+		"hdr_format":             "Dolby Vision",
+		"video_bit_depth":        "10bit",
+		"video_frame_rate":       "23.976",
+		"audio_codec":            "truehd",
+		"audio_channels":         "7.1",
+		"audio_object_format":    "Atmos",
+		"audio_languages":        "eng, jpn",
+		"subtitle_languages":     "eng",
+		"container":              "mp4",
+		"duration":               "2h 16m",
+		"bitrate":                "24.5 Mbps",
+	}
+	for name, value := range want {
+		if got := meta.Extended[name]; got != value {
+			t.Errorf("%s = %v, want %s", name, got, value)
+		}
+		if got := meta.Sources[name]; got != providerName {
+			t.Errorf("source(%s) = %v, want %v", name, got, providerName)
+		}
+	}
+}
+
+func TestTechnicalFormatting(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "hdr10", got: formatHDR(&ffprobeLib.Stream{ColorTransfer: "smpte2084"}), want: "HDR10"},
+		{name: "hlg", got: formatHDR(&ffprobeLib.Stream{ColorTransfer: "arib-std-b67"}), want: "HLG"},
+		{name: "sdr", got: formatHDR(&ffprobeLib.Stream{ColorTransfer: "bt709"}), want: ""},
+		{name: "720p", got: formatResolutionLabel(&ffprobeLib.Stream{Width: 1280, Height: 720}), want: "720p"},
+		{name: "sd", got: formatResolutionLabel(&ffprobeLib.Stream{Width: 720, Height: 480}), want: "SD"},
+		{name: "stereo", got: formatChannels(&ffprobeLib.Stream{ChannelLayout: "stereo", Channels: 2}), want: "2.0"},
+		{name: "side layout", got: formatChannels(&ffprobeLib.Stream{ChannelLayout: "5.1(side)", Channels: 6}), want: "5.1"},
+		{name: "channel count", got: formatChannels(&ffprobeLib.Stream{Channels: 6}), want: "5.1"},
+		{name: "dts:x", got: formatObjectAudio(&ffprobeLib.Stream{Profile: "DTS-HD MA + DTS:X"}), want: "DTS-X"},
+		{name: "integer fps", got: formatFrameRate(&ffprobeLib.Stream{RFrameRate: "25/1"}), want: "25"},
+		{name: "8bit", got: formatBitDepth(&ffprobeLib.Stream{PixFmt: "yuv420p"}), want: "8bit"},
+		{name: "matroska", got: formatContainer(&ffprobeLib.Format{FormatName: "matroska,webm", Filename: "a.mkv"}), want: "mkv"},
+		{name: "short", got: formatDuration(&ffprobeLib.Format{DurationSeconds: 1290}), want: "22m"},
+		{name: "kbps", got: formatBitrate(&ffprobeLib.Format{BitRate: "320000"}), want: "320 kbps"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %q, want %q", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFetch_CachesByFileIdentity(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.mkv")
+	if err := os.WriteFile(path, []byte("video"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cache, err := provider.OpenDiskCache(filepath.Join(dir, "metadata.json"))
+	if err != nil {
+		t.Fatalf("OpenDiskCache() error = %v", err)
+	}
+
+	probes := 0
+	p := New()
+	p.SetCache(cache)
+	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, []string, error) {
+		probes++
+		return &ffprobeLib.ProbeData{
+			Format:  &ffprobeLib.Format{},
+			Streams: []*ffprobeLib.Stream{{CodecName: "h264", CodecType: string(ffprobeLib.StreamVideo)}},
+		}, nil, nil
+	}
+
+	req := provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Example", Extra: map[string]interface{}{"path": path}}
+	for range 2 {
+		meta, err := p.Fetch(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Fetch() unexpected error: %v", err)
+		}
+		if got := meta.Extended["video_codec"]; got != "h264" {
+			t.Errorf("video_codec = %v, want h264", got)
+		}
+	}
+	if probes != 1 {
+		t.Fatalf("probes = %d after repeat fetch, want 1", probes)
+	}
+
+	// A changed file is probed again
+	if err := os.WriteFile(path, []byte("re-encoded video"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := p.Fetch(context.Background(), req); err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if probes != 2 {
+		t.Errorf("probes = %d after the file changed, want 2", probes)
+	}
+}
+
+func TestFetch_Timeout(t *testing.T) {
+	p := New()
+	if err := p.Configure(map[string]interface{}{"timeout_seconds": 0.01}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	p.probe = func(ctx context.Context, path string, extraOpts ...string) (*ffprobeLib.ProbeData, []string, error) {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+
+	req := provider.FetchRequest{MediaType: provider.MediaTypeMovie, Extra: map[string]interface{}{"path": "/videos/slow.mkv"}}
+	_, err := p.Fetch(context.Background(), req)
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "TIMEOUT" {
+		t.Fatalf("Fetch() error = %v, want TIMEOUT ProviderError", err)
+	}
+
+	// A cancelled run reports the cancellation rather than a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Fetch(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch() with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestConfigureBinaryPath(t *testing.T) {
+	p := New()
+	if err := p.Configure(map[string]interface{}{"binary_path": "/opt/ffmpeg/bin/ffprobe"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if p.binary != "/opt/ffmpeg/bin/ffprobe" {
+		t.Errorf("binary = %q, want /opt/ffmpeg/bin/ffprobe", p.binary)
+	}
+	if err := p.Configure(map[string]interface{}{}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if p.binary != defaultBinary || p.timeout != defaultTimeout {
+		t.Errorf("defaults = %q, %s, want %q, %s", p.binary, p.timeout, defaultBinary, defaultTimeout)
+	}
+}
+
+func TestRunProbeUsesConfiguredBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the ffprobe binary")
+	}
+	binary := filepath.Join(t.TempDir(), "fake-ffprobe")
+	script := "#!/bin/sh\necho '{\"format\":{\"format_name\":\"matroska,webm\"},\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"av1\",\"height\":720}]}'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p := New()
+	if err := p.Configure(map[string]interface{}{"binary_path": binary}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	meta, err := p.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeEpisode,
+		Extra:     map[string]interface{}{"path": "/videos/episode.mkv"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if got := meta.Extended["video_codec"]; got != "av1" {
+		t.Errorf("video_codec = %v, want av1", got)
+	}
+	if got := meta.Extended["container"]; got != "mkv" {
+		t.Errorf("container = %v, want mkv", got)
+	}
+}
+
+func TestRunProbeReportsProblems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the ffprobe binary")
+	}
+	binary := filepath.Join(t.TempDir(), "fake-ffprobe")
+	script := "#!/bin/sh\n" +
+		"echo '[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] stream 1, offset 0x2a: partial file' >&2\n" +
+		"echo '[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] stream 1, offset 0x2a: partial file' >&2\n" +
+		"echo '{\"format\":{\"format_name\":\"mov,mp4\",\"duration\":\"600.0\"},\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"h264\"}]}'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	p := New()
+	if err := p.Configure(map[string]interface{}{"binary_path": binary}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	meta, err := p.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Extra:     map[string]interface{}{"path": "/videos/movie.mp4"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if got, want := meta.Extended["probe_errors"], "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] stream 1, offset 0x2a: partial file"; got != want {
+		t.Errorf("probe_errors = %q, want %q", got, want)
+	}
+	if got := meta.Extended["video_streams"]; got != 1 {
+		t.Errorf("video_streams = %v, want 1", got)
+	}
+	if got := meta.Extended["audio_streams"]; got != 0 {
+		t.Errorf("audio_streams = %v, want 0", got)
+	}
+}
@@ -0,0 +1,258 @@
+package ffprobe
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/vansante/go-ffprobe.v2"
+)
+
+// resolutionTiers are the standard video heights, largest first.
+var resolutionTiers = []int{4320, 2160, 1440, 1080, 720, 576, 480}
+
+// bitDepthPattern finds the bit depth in pixel formats such as yuv420p10le.
+var bitDepthPattern = regexp.MustCompile(`p(\d{2})(?:le|be)$`)
+
+// containerAliases maps ffprobe demuxer names to the usual file extension.
+var containerAliases = map[string]string{
+	"matroska": "mkv",
+	"mpegts":   "ts",
+	"mov":      "mp4",
+	"asf":      "wmv",
+	"mpeg":     "mpg",
+}
+
+// formatVideoResolution names the stream's resolution tier. Cropped video
+// counts by its width too, so a 1920x800 scope release is 1080p.
+func formatVideoResolution(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	if stream.Height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dp", resolutionTier(stream.Width, stream.Height))
+}
+
+func resolutionTier(width, height int) int {
+	effective := max(height, width*9/16)
+	for _, tier := range resolutionTiers {
+		// Allow slightly short encodes such as 1916x1076
+		if effective*10 >= tier*9 {
+			return tier
+		}
+	}
+	return height
+}
+
+// formatResolutionLabel returns the marketing name of the resolution: 8K and
+// 4K for UHD, SD below 720p, and the p-value otherwise.
+func formatResolutionLabel(stream *ffprobe.Stream) string {
+	if stream == nil || stream.Height <= 0 {
+		return ""
+	}
+	switch tier := resolutionTier(stream.Width, stream.Height); {
+	case tier >= 4320:
+		return "8K"
+	case tier >= 2160:
+		return "4K"
+	case tier < 720:
+		return "SD"
+	default:
+		return fmt.Sprintf("%dp", tier)
+	}
+}
+
+// formatHDR identifies the stream's HDR format from its transfer function
+// and side data. Dolby Vision wins over the HDR10 base layer it usually
+// carries. SDR streams return an empty string.
+func formatHDR(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	hdr10Plus := false
+	for _, sd := range stream.SideDataList {
+		kind := strings.ToLower(sd.Type)
+		switch {
+		case strings.Contains(kind, "dovi"), strings.Contains(kind, "dolby vision"):
+			return "Dolby Vision"
+		case strings.Contains(kind, "hdr10+"), strings.Contains(kind, "smpte2094-40"):
+			hdr10Plus = true
+		}
+	}
+
+	switch {
+	case hdr10Plus:
+		return "HDR10+"
+	case stream.ColorTransfer == "smpte2084":
+		return "HDR10"
+	case stream.ColorTransfer == "arib-std-b67":
+		return "HLG"
+	}
+	return ""
+}
+
+// formatBitDepth returns the stream's bit depth, such as "10bit", from the
+// raw sample size or else the pixel format.
+func formatBitDepth(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	depth, _ := strconv.Atoi(stream.BitsPerRawSample)
+	if depth <= 0 {
+		if m := bitDepthPattern.FindStringSubmatch(stream.PixFmt); m != nil {
+			depth, _ = strconv.Atoi(m[1])
+		} else if stream.PixFmt != "" {
+			depth = 8
+		}
+	}
+	if depth <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dbit", depth)
+}
+
+// formatFrameRate returns the frame rate with up to three decimals, such as
+// "23.976" or "25".
+func formatFrameRate(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	for _, rate := range []string{stream.AvgFrameRate, stream.RFrameRate} {
+		if fps := parseRatio(rate); fps > 0 {
+			return strconv.FormatFloat(float64(int(fps*1000+0.5))/1000, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+func parseRatio(value string) float64 {
+	num, den, found := strings.Cut(value, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return n
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// formatChannels returns the audio channel layout in speaker notation, such
+// as "5.1" or "2.0".
+func formatChannels(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	layout, _, _ := strings.Cut(stream.ChannelLayout, "(")
+	switch layout {
+	case "mono":
+		return "1.0"
+	case "stereo":
+		return "2.0"
+	}
+	if _, err := strconv.ParseFloat(layout, 64); err == nil && strings.Contains(layout, ".") {
+		return layout
+	}
+
+	switch stream.Channels {
+	case 0:
+		return ""
+	case 1:
+		return "1.0"
+	case 2:
+		return "2.0"
+	case 6:
+		return "5.1"
+	case 8:
+		return "7.1"
+	default:
+		return fmt.Sprintf("%dch", stream.Channels)
+	}
+}
+
+// formatObjectAudio reports Dolby Atmos or DTS:X from the audio profile.
+func formatObjectAudio(stream *ffprobe.Stream) string {
+	if stream == nil {
+		return ""
+	}
+	profile := strings.ToLower(stream.Profile)
+	switch {
+	case strings.Contains(profile, "atmos"):
+		return "Atmos"
+	case strings.Contains(profile, "dts:x"), strings.Contains(profile, "dts-x"):
+		return "DTS-X"
+	}
+	return ""
+}
+
+// streamLanguages lists the distinct language tags of the streams of one
+// type, in stream order. Undetermined languages are skipped.
+func streamLanguages(data *ffprobe.ProbeData, streamType ffprobe.StreamType) string {
+	var languages []string
+	for _, stream := range data.StreamType(streamType) {
+		lang, _ := stream.TagList.GetString("language")
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" || lang == "und" || slices.Contains(languages, lang) {
+			continue
+		}
+		languages = append(languages, lang)
+	}
+	return strings.Join(languages, ", ")
+}
+
+// formatContainer names the container by its usual extension. ffprobe
+// reports demuxers that handle several formats, such as "mov,mp4,m4a", so
+// the file's own extension is used when the demuxer lists it.
+func formatContainer(format *ffprobe.Format) string {
+	if format == nil || format.FormatName == "" {
+		return ""
+	}
+	names := strings.Split(format.FormatName, ",")
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(format.Filename), "."))
+	for _, name := range names {
+		if ext != "" && (name == ext || containerAliases[name] == ext) {
+			return ext
+		}
+	}
+	if alias, ok := containerAliases[names[0]]; ok {
+		return alias
+	}
+	return names[0]
+}
+
+// formatDuration returns the running time as hours and minutes, such as
+// "2h 49m", or minutes alone under an hour.
+func formatDuration(format *ffprobe.Format) string {
+	if format == nil || format.DurationSeconds <= 0 {
+		return ""
+	}
+	minutes := int(format.DurationSeconds/60 + 0.5)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// formatBitrate returns the overall bitrate in Mbps, or kbps below 1 Mbps.
+func formatBitrate(format *ffprobe.Format) string {
+	if format == nil {
+		return ""
+	}
+	bps, err := strconv.ParseFloat(format.BitRate, 64)
+	if err != nil || bps <= 0 {
+		return ""
+	}
+	if bps < 1_000_000 {
+		return fmt.Sprintf("%.0f kbps", bps/1000)
+	}
+	return fmt.Sprintf("%.1f Mbps", bps/1_000_000)
+}
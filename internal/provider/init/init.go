@@ -2,18 +2,61 @@
 package init
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/anilist"
 	"github.com/Digital-Shane/title-tidy/internal/provider/ffprobe"
+	"github.com/Digital-Shane/title-tidy/internal/provider/library"
 	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/Digital-Shane/title-tidy/internal/provider/omdb"
+	"github.com/Digital-Shane/title-tidy/internal/provider/opensubtitles"
+	"github.com/Digital-Shane/title-tidy/internal/provider/plugin"
 	"github.com/Digital-Shane/title-tidy/internal/provider/tmdb"
 	"github.com/Digital-Shane/title-tidy/internal/provider/tvdb"
+	"github.com/Digital-Shane/title-tidy/internal/provider/tvmaze"
 )
 
-// LoadBuiltinProviders loads all built-in providers into the global registry
+var (
+	loadOnce sync.Once
+	loadErr  error
+
+	pluginOnce sync.Once
+	pluginErr  error
+)
+
+// LoadBuiltinProviders loads all built-in providers into the global registry.
+// Only the first call registers them; later calls return its result.
 func LoadBuiltinProviders() error {
+	loadOnce.Do(func() {
+		loadErr = registerBuiltinProviders()
+	})
+	return loadErr
+}
+
+// LoadPluginProviders registers the plugin executables in dir with the global
+// registry. Only the first call loads them; later calls return its result.
+func LoadPluginProviders(dir string) error {
+	pluginOnce.Do(func() {
+		_, pluginErr = plugin.LoadDir(context.Background(), dir, provider.GlobalRegistry)
+	})
+	return pluginErr
+}
+
+// rateLimited returns the default retry policy with a rate limit of
+// requests calls per window. The limits stay under each service's published
+// allowance; users can override them with the rate_limits setting.
+func rateLimited(requests int, window time.Duration) provider.Policy {
+	policy := provider.DefaultPolicy()
+	policy.Requests = requests
+	policy.Window = window
+	return policy
+}
+
+func registerBuiltinProviders() error {
 	// Register local provider first (always enabled)
 	localProvider := local.New()
 	if err := provider.GlobalRegistry.Register("local", localProvider, 0); err != nil {
@@ -25,22 +68,46 @@ func LoadBuiltinProviders() error {
 	}
 
 	// Register TMDB provider
-	tmdbProvider := tmdb.New()
+	tmdbProvider := provider.NewResilient(tmdb.New(), rateLimited(38, 10*time.Second))
 	if err := provider.GlobalRegistry.Register("tmdb", tmdbProvider, 100); err != nil {
 		return fmt.Errorf("failed to register TMDB provider: %w", err)
 	}
 
 	// Register OMDb provider
-	omdbProvider := omdb.New()
+	omdbProvider := provider.NewResilient(omdb.New(), rateLimited(20, 10*time.Second))
 	if err := provider.GlobalRegistry.Register("omdb", omdbProvider, 90); err != nil {
 		return fmt.Errorf("failed to register OMDb provider: %w", err)
 	}
 
-	tvdbProvider := tvdb.New()
+	tvdbProvider := provider.NewResilient(tvdb.New(), rateLimited(50, 10*time.Second))
 	if err := provider.GlobalRegistry.Register("tvdb", tvdbProvider, 95); err != nil {
 		return fmt.Errorf("failed to register TVDB provider: %w", err)
 	}
 
+	tvmazeProvider := provider.NewResilient(tvmaze.New(), rateLimited(20, 10*time.Second))
+	if err := provider.GlobalRegistry.Register("tvmaze", tvmazeProvider, 85); err != nil {
+		return fmt.Errorf("failed to register TVmaze provider: %w", err)
+	}
+
+	anilistProvider := provider.NewResilient(anilist.New(), rateLimited(30, time.Minute))
+	if err := provider.GlobalRegistry.Register("anilist", anilistProvider, 80); err != nil {
+		return fmt.Errorf("failed to register AniList provider: %w", err)
+	}
+
+	// Hash lookups read the file, so they cache by hash instead of through
+	// the shared wrapper
+	opensubtitlesProvider := opensubtitles.New()
+	if err := provider.GlobalRegistry.Register("opensubtitles", opensubtitlesProvider, 75); err != nil {
+		return fmt.Errorf("failed to register OpenSubtitles provider: %w", err)
+	}
+
+	// Library matching reads the destination folder, so it needs neither
+	// rate limits nor the response cache
+	libraryProvider := library.New()
+	if err := provider.GlobalRegistry.Register("library", libraryProvider, 120); err != nil {
+		return fmt.Errorf("failed to register library provider: %w", err)
+	}
+
 	// Register ffprobe provider
 	ffprobeProvider := ffprobe.New()
 	if err := provider.GlobalRegistry.Register("ffprobe", ffprobeProvider, 50); err != nil {
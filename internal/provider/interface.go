@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"strings"
 )
 
 // MediaType represents the type of media content
@@ -32,10 +34,70 @@ type Provider interface {
 	Fetch(ctx context.Context, request FetchRequest) (*Metadata, error)
 }
 
+// CandidateSearcher is implemented by providers that can list the results of
+// a movie or show search so the user can pick the right match. Candidates are
+// ordered best match first and capped at limit.
+type CandidateSearcher interface {
+	SearchCandidates(ctx context.Context, request FetchRequest, limit int) ([]Candidate, error)
+}
+
+// Candidate is a single search result offered for manual selection. Passing
+// its ID as FetchRequest.ID fetches exactly this result.
+type Candidate struct {
+	ID         string
+	Title      string
+	Year       string
+	MediaType  MediaType
+	Overview   string
+	Confidence float64
+}
+
+// ExactIDer is implemented by providers that can report the ID of the show or
+// movie behind metadata they returned. Passing that ID as FetchRequest.ID
+// fetches the same match again without searching.
+type ExactIDer interface {
+	ExactID(meta *Metadata) string
+}
+
+// DiskCacher is implemented by providers that keep their results in the
+// shared on-disk metadata cache.
+type DiskCacher interface {
+	SetCache(cache *DiskCache)
+}
+
+// SeasonBatcher is implemented by providers whose season lookup describes
+// every episode of the season, so a whole season of episodes costs one
+// request. FetchSeasonEpisodes takes a season request and returns the
+// episodes keyed by episode number. SeasonEpisodeVariables lists the
+// template variables the season payload fills; episodes named with any other
+// variable the provider supports are still fetched one at a time.
+type SeasonBatcher interface {
+	FetchSeasonEpisodes(ctx context.Context, request FetchRequest) (map[int]*Metadata, error)
+	SeasonEpisodeVariables() []string
+}
+
+// BatchesSeasons reports whether p fetches whole seasons of episodes, looking
+// through wrappers such as Resilient that implement SeasonBatcher for any
+// provider they wrap.
+func BatchesSeasons(p Provider) bool {
+	for p != nil {
+		if _, ok := p.(SeasonBatcher); !ok {
+			return false
+		}
+		wrapper, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			return true
+		}
+		p = wrapper.Unwrap()
+	}
+	return false
+}
+
 // ProviderCapabilities describes what a provider can do
 type ProviderCapabilities struct {
 	MediaTypes   []MediaType // What media types are supported
 	RequiresAuth bool        // Whether authentication is required
+	RequiresFile bool        // Whether the provider reads the media file (Extra["path"]) instead of searching
 	Priority     int         // Default priority for this provider (higher = preferred)
 }
 
@@ -73,10 +135,12 @@ type ConfigField struct {
 type ConfigFieldType string
 
 const (
+	ConfigFieldTypeString   ConfigFieldType = "string"
 	ConfigFieldTypeInt      ConfigFieldType = "int"
 	ConfigFieldTypeBool     ConfigFieldType = "bool"
 	ConfigFieldTypeSelect   ConfigFieldType = "select"
 	ConfigFieldTypePassword ConfigFieldType = "password"
+	ConfigFieldTypeList     ConfigFieldType = "list"
 )
 
 // ConfigFieldValidation contains validation rules for a field
@@ -108,6 +172,48 @@ type FetchRequest struct {
 	Extra     map[string]interface{} // Provider-specific parameters
 }
 
+// ExtraKnownIDs is the Extra key holding IDs that higher priority providers
+// resolved for the same title, as a map[string]string keyed like
+// Metadata.IDs ("imdb_id", "tmdb_id", "tvdb_id", ...). Providers use them for
+// exact lookups instead of searching by name.
+const ExtraKnownIDs = "known_ids"
+
+// KnownIDs returns the IDs passed under ExtraKnownIDs.
+func (r FetchRequest) KnownIDs() map[string]string {
+	switch ids := r.Extra[ExtraKnownIDs].(type) {
+	case map[string]string:
+		return ids
+	case map[string]interface{}:
+		// Decoded from JSON, as plugins see it
+		result := make(map[string]string, len(ids))
+		for key, value := range ids {
+			if s, ok := value.(string); ok {
+				result[key] = s
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// ExtraShowName is the Extra key holding the show title parsed from the file
+// name on season and episode requests, whose Name is the title the provider
+// matched. Per-show settings such as episode_orders are keyed by the parsed
+// title, which may differ from a localized match.
+const ExtraShowName = "show_name"
+
+// ShowName returns the parsed show title passed under ExtraShowName, or "".
+func (r FetchRequest) ShowName() string {
+	name, _ := r.Extra[ExtraShowName].(string)
+	return name
+}
+
+// KnownID returns the ID another provider resolved under key, or "".
+func (r FetchRequest) KnownID(key string) string {
+	return strings.TrimSpace(r.KnownIDs()[key])
+}
+
 // Metadata represents the fetched metadata
 type Metadata struct {
 	// Core fields that are common across all providers
@@ -158,3 +264,9 @@ type ProviderError struct {
 func (e *ProviderError) Error() string {
 	return e.Message
 }
+
+// IsNotFound reports whether err is a provider's NOT_FOUND error.
+func IsNotFound(err error) bool {
+	var provErr *ProviderError
+	return errors.As(err, &provErr) && provErr.Code == "NOT_FOUND"
+}
@@ -0,0 +1,159 @@
+package library
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
+)
+
+var (
+	// folderIDRe matches the ID tags media servers read from folder names,
+	// such as {tmdb-1396}, [imdbid-tt0903747] or {tvdb-81189}.
+	folderIDRe = regexp.MustCompile(`(?i)[\[{](imdb|tmdb|tvdb)(?:id)?[-=]([a-z0-9]+)[\]}]`)
+
+	// qualifierRe matches a trailing qualifier such as the (US) in
+	// "The Office (US)", which downloads usually leave out.
+	qualifierRe = regexp.MustCompile(`\s*\([^()]*\)$`)
+)
+
+// idKeys maps the ID names used in folder tags and NFO files to metadata ID
+// keys.
+var idKeys = map[string]string{
+	"imdb": "imdb_id",
+	"tmdb": "tmdb_id",
+	"tvdb": "tvdb_id",
+}
+
+// entry is one show or movie folder already in the library.
+type entry struct {
+	folder    string
+	title     string
+	year      string
+	aliases   []string
+	mediaType provider.MediaType // blank when the folder has no NFO to say
+	ids       map[string]string
+}
+
+// nfo holds the fields read from Kodi style tvshow.nfo and movie.nfo files.
+type nfo struct {
+	XMLName   xml.Name
+	Title     string `xml:"title"`
+	Year      string `xml:"year"`
+	IMDBID    string `xml:"imdbid"`
+	TMDBID    string `xml:"tmdbid"`
+	TVDBID    string `xml:"tvdbid"`
+	UniqueIDs []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"uniqueid"`
+}
+
+// buildIndex reads the folders directly inside root. Files and hidden
+// folders are skipped.
+func buildIndex(root string) ([]entry, error) {
+	dirEntries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") {
+			continue
+		}
+		if e, ok := parseFolder(filepath.Join(root, dirEntry.Name())); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// parseFolder indexes a library folder by the title and year in its name,
+// the ID tags in its name and the IDs in its NFO file. Tags in the name win
+// over the NFO.
+func parseFolder(dir string) (entry, bool) {
+	folder := filepath.Base(dir)
+	e := entry{folder: folder, ids: make(map[string]string)}
+
+	for _, match := range folderIDRe.FindAllStringSubmatch(folder, -1) {
+		e.ids[idKeys[strings.ToLower(match[1])]] = match[2]
+	}
+	e.title, e.year = local.ExtractNameAndYear(folderIDRe.ReplaceAllString(folder, ""))
+	if e.title == "" {
+		return entry{}, false
+	}
+	if short := qualifierRe.ReplaceAllString(e.title, ""); short != "" && short != e.title {
+		e.aliases = append(e.aliases, short)
+	}
+
+	if info, ok := readNFO(dir); ok {
+		switch strings.ToLower(info.XMLName.Local) {
+		case "tvshow":
+			e.mediaType = provider.MediaTypeShow
+		case "movie":
+			e.mediaType = provider.MediaTypeMovie
+		}
+		if title := strings.TrimSpace(info.Title); title != "" && title != e.title && !slices.Contains(e.aliases, title) {
+			e.aliases = append(e.aliases, title)
+		}
+		if e.year == "" {
+			e.year = strings.TrimSpace(info.Year)
+		}
+		for key, value := range info.ids() {
+			if _, ok := e.ids[key]; !ok {
+				e.ids[key] = value
+			}
+		}
+	}
+	return e, true
+}
+
+// readNFO parses the folder's tvshow.nfo or movie.nfo, falling back to the
+// first other NFO file, which is how movie folders often name theirs.
+func readNFO(dir string) (nfo, bool) {
+	candidates := []string{"tvshow.nfo", "movie.nfo"}
+	if dirEntries, err := os.ReadDir(dir); err == nil {
+		for _, dirEntry := range dirEntries {
+			if !dirEntry.IsDir() && local.IsNFO(dirEntry.Name()) && !slices.Contains(candidates, dirEntry.Name()) {
+				candidates = append(candidates, dirEntry.Name())
+			}
+		}
+	}
+
+	for _, name := range candidates {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		var info nfo
+		if err := xml.Unmarshal(data, &info); err != nil {
+			continue
+		}
+		return info, true
+	}
+	return nfo{}, false
+}
+
+// ids returns the NFO's IDs keyed like provider IDs. uniqueid elements take
+// precedence over the older dedicated elements.
+func (n nfo) ids() map[string]string {
+	ids := make(map[string]string)
+	for key, value := range map[string]string{"imdb_id": n.IMDBID, "tmdb_id": n.TMDBID, "tvdb_id": n.TVDBID} {
+		if value = strings.TrimSpace(value); value != "" {
+			ids[key] = value
+		}
+	}
+	for _, uid := range n.UniqueIDs {
+		key, ok := idKeys[strings.ToLower(strings.TrimSpace(uid.Type))]
+		if value := strings.TrimSpace(uid.Value); ok && value != "" {
+			ids[key] = value
+		}
+	}
+	return ids
+}
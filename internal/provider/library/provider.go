@@ -0,0 +1,259 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+const (
+	providerName = "library"
+
+	// FolderKey is the Extended key holding the name of the library folder
+	// an item matched.
+	FolderKey = "library_folder"
+
+	// matchThreshold is the score a library folder needs to claim an item.
+	// It is stricter than search matching so a show or movie new to the
+	// library is never filed under a similar title, and it rejects folders
+	// whose year is more than two years off.
+	matchThreshold = 0.85
+)
+
+// Provider matches items to the show and movie folders already in a library,
+// usually the link destination, so new episodes and movies land in the
+// established folder with its title, year and IDs.
+type Provider struct {
+	root string
+
+	mu    sync.Mutex
+	index []entry
+}
+
+// New creates a new library provider instance.
+func New() *Provider {
+	return &Provider{}
+}
+
+// SetOffline is a no-op: the library is read from disk, so lookups work the
+// same offline.
+func (p *Provider) SetOffline(bool) {}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// Description returns a human readable description of the provider.
+func (p *Provider) Description() string {
+	return "Matches items to the show and movie folders already in the library"
+}
+
+// Capabilities returns what this provider can handle. It ranks above the
+// online providers so the library's title and year win and its IDs steer
+// their lookups.
+func (p *Provider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{
+		MediaTypes: []provider.MediaType{
+			provider.MediaTypeMovie,
+			provider.MediaTypeShow,
+			provider.MediaTypeSeason,
+			provider.MediaTypeEpisode,
+		},
+		RequiresAuth: false,
+		Priority:     120,
+	}
+}
+
+// SupportedVariables returns the template variables supplied by this provider.
+func (p *Provider) SupportedVariables() []provider.TemplateVariable {
+	mediaTypes := []provider.MediaType{
+		provider.MediaTypeMovie,
+		provider.MediaTypeShow,
+		provider.MediaTypeSeason,
+		provider.MediaTypeEpisode,
+	}
+
+	return []provider.TemplateVariable{
+		{
+			Name:        "imdb_id",
+			DisplayName: "IMDB ID",
+			Description: "Internet Movie Database ID from the library folder",
+			MediaTypes:  mediaTypes,
+			Example:     "tt0386676",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "tmdb_id",
+			DisplayName: "TMDB ID",
+			Description: "The Movie Database ID from the library folder",
+			MediaTypes:  mediaTypes,
+			Example:     "2316",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "tvdb_id",
+			DisplayName: "TVDB ID",
+			Description: "TheTVDB ID from the library folder",
+			MediaTypes:  mediaTypes,
+			Example:     "73244",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+	}
+}
+
+// ConfigSchema returns the configuration schema for this provider.
+func (p *Provider) ConfigSchema() provider.ConfigSchema {
+	return provider.ConfigSchema{
+		Fields: []provider.ConfigField{
+			{
+				Name:        "path",
+				DisplayName: "Library Path",
+				Type:        provider.ConfigFieldTypeString,
+				Required:    true,
+				Description: "Folder holding the existing show or movie folders",
+			},
+		},
+	}
+}
+
+// Configure sets the library folder. The folder is indexed on the first
+// lookup, so reconfiguring picks up folders added since.
+func (p *Provider) Configure(config map[string]interface{}) error {
+	root, _ := config["path"].(string)
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return fmt.Errorf("path is required")
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("library path: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("library path %s is not a directory", root)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.root = root
+	p.index = nil
+	return nil
+}
+
+// Fetch returns the library folder matching the request's title and year.
+// Seasons and episodes match their show's folder. Items new to the library
+// return no metadata rather than an error, since they have nothing to be
+// reviewed against.
+func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := p.entries()
+	if err != nil {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "UNAVAILABLE",
+			Message:  fmt.Sprintf("read library: %v", err),
+			Retry:    false,
+		}
+	}
+
+	mediaType := request.MediaType
+	if mediaType == provider.MediaTypeSeason || mediaType == provider.MediaTypeEpisode {
+		mediaType = provider.MediaTypeShow
+	}
+	match, score := bestEntry(entries, request.Name, request.Year, mediaType)
+	if match == nil {
+		return nil, nil
+	}
+	return entryMetadata(match, request.MediaType, score), nil
+}
+
+// entries returns the library index, building it on first use.
+func (p *Provider) entries() ([]entry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.root == "" {
+		return nil, fmt.Errorf("no library path configured")
+	}
+	if p.index == nil {
+		index, err := buildIndex(p.root)
+		if err != nil {
+			return nil, err
+		}
+		p.index = index
+	}
+	return p.index, nil
+}
+
+// bestEntry returns the folder that best matches name and year, or nil when
+// none scores matchThreshold or two folders match equally well, as with
+// "The Office (UK)" and "The Office (US)" for a download named "The Office".
+func bestEntry(entries []entry, name, year string, mediaType provider.MediaType) (*entry, float64) {
+	if strings.TrimSpace(name) == "" {
+		return nil, 0
+	}
+
+	var best *entry
+	bestScore, runnerUp := 0.0, 0.0
+	for i := range entries {
+		// A folder known to hold the other kind of media never matches
+		if entries[i].mediaType != "" && mediaType != "" && entries[i].mediaType != mediaType {
+			continue
+		}
+		score := provider.ScoreMatch(name, year, mediaType, provider.MatchCandidate{
+			Title:     entries[i].title,
+			Titles:    entries[i].aliases,
+			Year:      entries[i].year,
+			MediaType: entries[i].mediaType,
+		})
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = &entries[i], score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if best == nil || bestScore < matchThreshold || runnerUp == bestScore {
+		return nil, 0
+	}
+	return best, bestScore
+}
+
+// entryMetadata converts a matched folder to metadata for the requested
+// media type.
+func entryMetadata(e *entry, mediaType provider.MediaType, score float64) *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:     e.title,
+			Year:      e.year,
+			MediaType: mediaType,
+		},
+		Extended:   map[string]interface{}{FolderKey: e.folder},
+		Sources:    make(map[string]string),
+		IDs:        make(map[string]string, len(e.ids)),
+		Confidence: score,
+	}
+	for key, value := range e.ids {
+		meta.IDs[key] = value
+	}
+
+	meta.Sources["title"] = providerName
+	if e.year != "" {
+		meta.Sources["year"] = providerName
+	}
+	meta.Sources[FolderKey] = providerName
+	for key := range meta.IDs {
+		meta.Sources[key] = providerName
+	}
+	return meta
+}
@@ -0,0 +1,237 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+const officeNFO = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<tvshow>
+	<title>The Office</title>
+	<year>2005</year>
+	<uniqueid type="tvdb" default="true">73244</uniqueid>
+	<uniqueid type="imdb">tt0386676</uniqueid>
+</tvshow>`
+
+const heatNFO = `<movie><title>Heat</title><tmdbid>949</tmdbid></movie>`
+
+// newLibrary creates a library folder holding the named folders, each with
+// its files, and returns a provider configured with it.
+func newLibrary(t *testing.T, folders map[string]map[string]string) *Provider {
+	t.Helper()
+	root := t.TempDir()
+	for folder, files := range folders {
+		dir := filepath.Join(root, folder)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+		}
+	}
+
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"path": root}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	return prov
+}
+
+func TestParseFolder(t *testing.T) {
+	prov := newLibrary(t, map[string]map[string]string{
+		"The Office (US) (2005)":          {"tvshow.nfo": officeNFO},
+		"Breaking Bad (2008) {tmdb-1396}": nil,
+		"Heat [imdbid-tt0113277]":         {"Heat.nfo": heatNFO},
+		"Mystery Show (2010) {tvdb-111}":  {"tvshow.nfo": `<tvshow><uniqueid type="tvdb">222</uniqueid></tvshow>`},
+		".hidden":                         nil,
+	})
+
+	entries, err := prov.entries()
+	if err != nil {
+		t.Fatalf("entries() error = %v", err)
+	}
+	got := make(map[string]entry, len(entries))
+	for _, e := range entries {
+		got[e.folder] = e
+	}
+	want := map[string]entry{
+		"The Office (US) (2005)": {
+			folder:    "The Office (US) (2005)",
+			title:     "The Office (US)",
+			year:      "2005",
+			aliases:   []string{"The Office"},
+			mediaType: provider.MediaTypeShow,
+			ids:       map[string]string{"tvdb_id": "73244", "imdb_id": "tt0386676"},
+		},
+		"Breaking Bad (2008) {tmdb-1396}": {
+			folder: "Breaking Bad (2008) {tmdb-1396}",
+			title:  "Breaking Bad",
+			year:   "2008",
+			ids:    map[string]string{"tmdb_id": "1396"},
+		},
+		"Heat [imdbid-tt0113277]": {
+			folder:    "Heat [imdbid-tt0113277]",
+			title:     "Heat",
+			mediaType: provider.MediaTypeMovie,
+			ids:       map[string]string{"imdb_id": "tt0113277", "tmdb_id": "949"},
+		},
+		// Tags in the folder name win over the NFO
+		"Mystery Show (2010) {tvdb-111}": {
+			folder:    "Mystery Show (2010) {tvdb-111}",
+			title:     "Mystery Show",
+			year:      "2010",
+			mediaType: provider.MediaTypeShow,
+			ids:       map[string]string{"tvdb_id": "111"},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(entry{})); diff != "" {
+		t.Errorf("index mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	prov := newLibrary(t, map[string]map[string]string{
+		"The Office (US) (2005)":          {"tvshow.nfo": officeNFO},
+		"Breaking Bad (2008) {tmdb-1396}": nil,
+		"Heat (1995)":                     {"movie.nfo": heatNFO},
+	})
+
+	tests := []struct {
+		name       string
+		request    provider.FetchRequest
+		wantFolder string
+		wantTitle  string
+		wantYear   string
+	}{
+		{
+			name:       "show without qualifier",
+			request:    provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "The Office"},
+			wantFolder: "The Office (US) (2005)",
+			wantTitle:  "The Office (US)",
+			wantYear:   "2005",
+		},
+		{
+			name:       "episode matches its show",
+			request:    provider.FetchRequest{MediaType: provider.MediaTypeEpisode, Name: "The Office US", Season: 2, Episode: 1},
+			wantFolder: "The Office (US) (2005)",
+			wantTitle:  "The Office (US)",
+			wantYear:   "2005",
+		},
+		{
+			name:       "movie with year",
+			request:    provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Heat", Year: "1995"},
+			wantFolder: "Heat (1995)",
+			wantTitle:  "Heat",
+			wantYear:   "1995",
+		},
+		{
+			name:    "movie from another year",
+			request: provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Heat", Year: "1986"},
+		},
+		{
+			name:    "show typed as movie",
+			request: provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "The Office", Year: "2005"},
+		},
+		{
+			name:    "similar title",
+			request: provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "The Offer"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			meta, err := prov.Fetch(context.Background(), tc.request)
+			if err != nil {
+				t.Fatalf("Fetch() unexpected error: %v", err)
+			}
+			if tc.wantFolder == "" {
+				if meta != nil {
+					t.Fatalf("Fetch() matched %v, want no match", meta.Extended[FolderKey])
+				}
+				return
+			}
+			if meta == nil {
+				t.Fatalf("Fetch() found no match, want %s", tc.wantFolder)
+			}
+			if got := meta.Extended[FolderKey]; got != tc.wantFolder {
+				t.Errorf("folder = %v, want %s", got, tc.wantFolder)
+			}
+			if meta.Core.Title != tc.wantTitle || meta.Core.Year != tc.wantYear {
+				t.Errorf("title, year = %q, %q, want %q, %q", meta.Core.Title, meta.Core.Year, tc.wantTitle, tc.wantYear)
+			}
+			if meta.Core.MediaType != tc.request.MediaType {
+				t.Errorf("media type = %s, want %s", meta.Core.MediaType, tc.request.MediaType)
+			}
+			if meta.Sources["title"] != providerName {
+				t.Errorf("Sources[title] = %q, want %q", meta.Sources["title"], providerName)
+			}
+		})
+	}
+}
+
+func TestFetchAmbiguous(t *testing.T) {
+	prov := newLibrary(t, map[string]map[string]string{
+		"The Office (UK) (2001)": nil,
+		"The Office (US) (2005)": nil,
+	})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "The Office"})
+	if err != nil || meta != nil {
+		t.Errorf("Fetch(The Office) = %v, %v, want no match", meta, err)
+	}
+
+	meta, err = prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "The Office", Year: "2005"})
+	if err != nil || meta == nil {
+		t.Fatalf("Fetch(The Office 2005) = %v, %v, want a match", meta, err)
+	}
+	if got := meta.Extended[FolderKey]; got != "The Office (US) (2005)" {
+		t.Errorf("folder = %v, want The Office (US) (2005)", got)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{}); err == nil {
+		t.Errorf("Configure() without path succeeded, want error")
+	}
+	if err := prov.Configure(map[string]interface{}{"path": filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("Configure(missing path) succeeded, want error")
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := prov.Configure(map[string]interface{}{"path": file}); err == nil {
+		t.Errorf("Configure(file) succeeded, want error")
+	}
+}
+
+func TestConfigurePicksUpNewFolders(t *testing.T) {
+	root := t.TempDir()
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"path": root}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	request := provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Heat", Year: "1995"}
+	if meta, _ := prov.Fetch(context.Background(), request); meta != nil {
+		t.Fatalf("Fetch() matched in an empty library")
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "Heat (1995)"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := prov.Configure(map[string]interface{}{"path": root}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	if meta, _ := prov.Fetch(context.Background(), request); meta == nil {
+		t.Errorf("Fetch() found no match after reconfiguring")
+	}
+}
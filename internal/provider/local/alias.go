@@ -0,0 +1,138 @@
+package local
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+// Alias maps a title the parser always gets wrong to the canonical title,
+// year or provider IDs to use instead. Name is compared after normalization
+// and may end in a year, as in "Doctor Who 2005", to only match names parsed
+// with that year. Path limits the alias to media under that folder.
+type Alias struct {
+	Name  string            `json:"name"`
+	Path  string            `json:"path,omitempty"`
+	Title string            `json:"title,omitempty"`
+	Year  string            `json:"year,omitempty"`
+	IDs   map[string]string `json:"ids,omitempty"` // Provider IDs keyed by provider name
+}
+
+// SetAliases replaces the aliases applied to parsed titles.
+func (p *Provider) SetAliases(aliases []Alias) {
+	p.aliases = aliases
+}
+
+// PinnedID returns the ID an alias pins for the provider and the canonical
+// title and year an item was given, or "" when no alias does. path scopes
+// the lookup like the alias Path.
+func (p *Provider) PinnedID(title, year, path, providerName string) string {
+	want := provider.NormalizeTitle(title)
+	alias := bestAlias(p.aliases, path, func(a Alias) (bool, bool) {
+		aliasTitle, aliasYear := ExtractNameAndYear(a.Name)
+		if a.Title != "" {
+			aliasTitle = a.Title
+		}
+		if a.Year != "" {
+			aliasYear = a.Year
+		}
+		if a.IDs[providerName] == "" || provider.NormalizeTitle(aliasTitle) != want {
+			return false, false
+		}
+		if aliasYear != "" {
+			return aliasYear == year, true
+		}
+		return true, false
+	})
+	if alias == nil {
+		return ""
+	}
+	return alias.IDs[providerName]
+}
+
+// applyAliases rewrites the parsed title and year of meta with the alias
+// matching it.
+func (p *Provider) applyAliases(meta *provider.Metadata, node *treeview.Node[treeview.FileInfo]) {
+	if meta == nil || meta.Core.Title == "" || len(p.aliases) == 0 {
+		return
+	}
+
+	title := provider.NormalizeTitle(meta.Core.Title)
+	withYear := ""
+	if meta.Core.Year != "" {
+		withYear = provider.NormalizeTitle(meta.Core.Title + " " + meta.Core.Year)
+	}
+	alias := bestAlias(p.aliases, nodePath(node), func(a Alias) (bool, bool) {
+		name := provider.NormalizeTitle(a.Name)
+		if withYear != "" && name == withYear {
+			return true, true
+		}
+		return name == title, false
+	})
+	if alias == nil {
+		return
+	}
+
+	if alias.Title != "" {
+		meta.Core.Title = alias.Title
+	}
+	if alias.Year != "" {
+		meta.Core.Year = alias.Year
+	}
+}
+
+// bestAlias returns the most specific alias matching path that match
+// accepts. match also reports whether the alias matched by name and year. A
+// Path scope counts for more than a year, and the first alias wins ties.
+func bestAlias(aliases []Alias, path string, match func(Alias) (ok, byYear bool)) *Alias {
+	var best *Alias
+	bestRank := -1
+	for i := range aliases {
+		scoped := aliases[i].Path != ""
+		if scoped && !underPath(path, aliases[i].Path) {
+			continue
+		}
+		ok, byYear := match(aliases[i])
+		if !ok {
+			continue
+		}
+		rank := 0
+		if scoped {
+			rank += 2
+		}
+		if byYear {
+			rank++
+		}
+		if rank > bestRank {
+			best, bestRank = &aliases[i], rank
+		}
+	}
+	return best
+}
+
+// underPath reports whether path is dir or inside it. Relative paths are
+// taken from the working directory, where the media is indexed from.
+func underPath(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func nodePath(node *treeview.Node[treeview.FileInfo]) string {
+	if node == nil || node.Data() == nil {
+		return ""
+	}
+	return node.Data().Path
+}
@@ -0,0 +1,125 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/treeview/v2"
+)
+
+func newAliasTestNode(name, path string) *treeview.Node[treeview.FileInfo] {
+	return treeview.NewNodeSimple(name, treeview.FileInfo{
+		FileInfo: &mockFileInfo{name: name},
+		Path:     path,
+	})
+}
+
+func TestProviderAppliesAliases(t *testing.T) {
+	aliases := []Alias{
+		{Name: "Shield", Title: "Agents of S.H.I.E.L.D.", Year: "2013"},
+		{Name: "Doctor Who 2005", Title: "Doctor Who (2005)"},
+		{Name: "The Office", Year: "2005"},
+		{Name: "The Office", Path: "/media/uk", Year: "2001"},
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		path      string
+		wantTitle string
+		wantYear  string
+	}{
+		{
+			name:      "title and year",
+			file:      "Shield.S01E01.720p.mkv",
+			path:      "/media/tv/Shield.S01E01.720p.mkv",
+			wantTitle: "Agents of S.H.I.E.L.D.",
+			wantYear:  "2013",
+		},
+		{
+			name:      "year qualified name",
+			file:      "Doctor.Who.2005.S01E01.mkv",
+			path:      "/media/tv/Doctor.Who.2005.S01E01.mkv",
+			wantTitle: "Doctor Who (2005)",
+			wantYear:  "2005",
+		},
+		{
+			name:      "year qualified name needs the year",
+			file:      "Doctor.Who.S01E01.mkv",
+			path:      "/media/tv/Doctor.Who.S01E01.mkv",
+			wantTitle: "Doctor Who",
+		},
+		{
+			name:      "unscoped alias",
+			file:      "The.Office.S02E01.mkv",
+			path:      "/media/us/The.Office.S02E01.mkv",
+			wantTitle: "The Office",
+			wantYear:  "2005",
+		},
+		{
+			name:      "path scope wins",
+			file:      "The.Office.S02E01.mkv",
+			path:      "/media/uk/The.Office.S02E01.mkv",
+			wantTitle: "The Office",
+			wantYear:  "2001",
+		},
+		{
+			name:      "no alias",
+			file:      "Breaking.Bad.S01E01.mkv",
+			path:      "/media/tv/Breaking.Bad.S01E01.mkv",
+			wantTitle: "Breaking Bad",
+		},
+	}
+
+	p := New()
+	p.SetAliases(aliases)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mediaType, meta, err := p.Detect(newAliasTestNode(tc.file, tc.path))
+			if err != nil {
+				t.Fatalf("Detect() unexpected error: %v", err)
+			}
+			if mediaType != provider.MediaTypeEpisode {
+				t.Fatalf("Detect() media type = %s, want episode", mediaType)
+			}
+			if meta.Core.Title != tc.wantTitle || meta.Core.Year != tc.wantYear {
+				t.Errorf("Detect() = %q (%q), want %q (%q)", meta.Core.Title, meta.Core.Year, tc.wantTitle, tc.wantYear)
+			}
+		})
+	}
+}
+
+func TestProviderPinnedID(t *testing.T) {
+	p := New()
+	p.SetAliases([]Alias{
+		{Name: "Shield", Title: "Agents of S.H.I.E.L.D.", Year: "2013", IDs: map[string]string{"tmdb": "1403"}},
+		{Name: "Doctor Who 2005", IDs: map[string]string{"tmdb": "57243"}},
+		{Name: "Heat", IDs: map[string]string{"tmdb": "949"}},
+		{Name: "Heat", Path: "/media/tv", IDs: map[string]string{"tmdb": "102"}},
+	})
+
+	tests := []struct {
+		name     string
+		title    string
+		year     string
+		path     string
+		provider string
+		want     string
+	}{
+		{name: "canonical title", title: "Agents of S.H.I.E.L.D.", year: "2013", provider: "tmdb", want: "1403"},
+		{name: "other year", title: "Agents of S.H.I.E.L.D.", year: "2014", provider: "tmdb"},
+		{name: "other provider", title: "Agents of S.H.I.E.L.D.", year: "2013", provider: "tvdb"},
+		{name: "year from name", title: "Doctor Who", year: "2005", provider: "tmdb", want: "57243"},
+		{name: "year from name mismatch", title: "Doctor Who", year: "1963", provider: "tmdb"},
+		{name: "unscoped", title: "Heat", year: "1995", path: "/media/movies/Heat.mkv", provider: "tmdb", want: "949"},
+		{name: "scoped", title: "Heat", path: "/media/tv/Heat/S01E01.mkv", provider: "tmdb", want: "102"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := p.PinnedID(tc.title, tc.year, tc.path, tc.provider); got != tc.want {
+				t.Errorf("PinnedID() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -25,6 +25,10 @@ var (
 	nfoRe      = regexp.MustCompile(`(?i)\.nfo$`)
 	imageRe    = regexp.MustCompile(`(?i)\.(jpg|jpeg|png|gif|bmp|webp|tiff?|ico|svg)$`)
 
+	// Sample hints: "sample" as a word of its own
+	sampleRe       = regexp.MustCompile(`(?i)(?:^|[^a-z])sample(?:[^a-z]|$)`)
+	sampleFolderRe = regexp.MustCompile(`(?i)^samples?$`)
+
 	// Language pattern for subtitles
 	langPattern = regexp.MustCompile(`(\.[a-zA-Z]{2,3}(?:[-_][a-zA-Z]{2,4})?)$`)
 
@@ -72,9 +76,16 @@ func IsImage(filename string) bool {
 	return imageRe.MatchString(filename)
 }
 
-// IsSample checks if the filename contains "sample"
+// IsSample reports whether name carries a sample hint: "sample" as a word of
+// its own, as in "movie-sample.mkv", but not "Samples" or "Sampler". A hint
+// alone doesn't make a file a sample; see core.SampleDetector.
 func IsSample(name string) bool {
-	return strings.Contains(strings.ToLower(name), "sample")
+	return sampleRe.MatchString(name)
+}
+
+// IsSampleFolder reports whether name is a folder of release samples.
+func IsSampleFolder(name string) bool {
+	return sampleFolderRe.MatchString(strings.TrimSpace(name))
 }
 
 // ExtractExtension extracts the file extension (handles both regular and subtitle files)
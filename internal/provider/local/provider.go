@@ -15,6 +15,7 @@ const (
 // Provider implements the provider.Provider interface for local filesystem metadata
 type Provider struct {
 	parserEngine *ParserEngine
+	aliases      []Alias
 }
 
 // New creates a new local provider instance
@@ -152,6 +153,8 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 		return nil, err
 	}
 
+	p.applyAliases(metadata, node)
+
 	// Add additional request data to metadata if provided
 	if request.Year != "" && metadata.Core.Year == "" {
 		metadata.Core.Year = request.Year
@@ -168,5 +171,10 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 
 // Detect analyses a tree node and returns parsed metadata alongside the detected media type.
 func (p *Provider) Detect(node *treeview.Node[treeview.FileInfo]) (provider.MediaType, *provider.Metadata, error) {
-	return p.parserEngine.DetectNode(node)
+	mediaType, metadata, err := p.parserEngine.DetectNode(node)
+	if err != nil {
+		return mediaType, metadata, err
+	}
+	p.applyAliases(metadata, node)
+	return mediaType, metadata, nil
 }
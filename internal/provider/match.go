@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultMatchThreshold is the minimum confidence a search match needs before
+// its metadata is applied without review.
+const DefaultMatchThreshold = 0.6
+
+// Weights used to combine the individual match signals into a confidence.
+const (
+	matchTitleWeight = 0.7
+	matchYearWeight  = 0.2
+	matchTypeWeight  = 0.1
+)
+
+// MatchCandidate describes a search result scored against the parsed name.
+type MatchCandidate struct {
+	Title     string    // Primary title of the result
+	Titles    []string  // Original or alternate titles also compared
+	Year      string    // Release or first air year
+	MediaType MediaType // Media type of the result, blank when unknown
+}
+
+// ScoreMatch rates how well a candidate matches a query on a 0-1 scale using
+// normalized title similarity, year distance and media type. Scores are
+// rounded to two decimals.
+func ScoreMatch(name, year string, mediaType MediaType, candidate MatchCandidate) float64 {
+	titleScore := TitleSimilarity(name, candidate.Title)
+	for _, alt := range candidate.Titles {
+		if score := TitleSimilarity(name, alt); score > titleScore {
+			titleScore = score
+		}
+	}
+
+	typeScore := 1.0
+	if mediaType != "" && candidate.MediaType != "" && mediaType != candidate.MediaType {
+		typeScore = 0
+	}
+
+	score := matchTitleWeight*titleScore + matchYearWeight*yearScore(year, candidate.Year) + matchTypeWeight*typeScore
+	return math.Round(score*100) / 100
+}
+
+// BestMatch scores every candidate and returns the index and score of the
+// best one. Earlier candidates win ties so the provider's own ranking is kept.
+// The index is -1 when there are no candidates.
+func BestMatch(name, year string, mediaType MediaType, candidates []MatchCandidate) (int, float64) {
+	best, bestScore := -1, -1.0
+	for i, candidate := range candidates {
+		if score := ScoreMatch(name, year, mediaType, candidate); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return -1, 0
+	}
+	return best, bestScore
+}
+
+// RankCandidates scores candidates against the query, sorts them best first
+// (keeping the provider's order for ties) and returns at most limit of them.
+// A limit of zero or less returns every candidate.
+func RankCandidates(name, year string, mediaType MediaType, candidates []Candidate, limit int) []Candidate {
+	ranked := make([]Candidate, len(candidates))
+	for i, candidate := range candidates {
+		candidate.Confidence = ScoreMatch(name, year, mediaType, MatchCandidate{
+			Title:     candidate.Title,
+			Year:      candidate.Year,
+			MediaType: candidate.MediaType,
+		})
+		ranked[i] = candidate
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Confidence > ranked[j].Confidence
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// NormalizeTitle lowercases a title, spells out "&", drops a leading or
+// trailing article and removes punctuation so cosmetic differences don't
+// affect matching.
+func NormalizeTitle(title string) string {
+	title = trailingArticleRe.ReplaceAllString(strings.TrimSpace(title), "")
+	title = strings.ToLower(strings.ReplaceAll(title, "&", " and "))
+	title = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			return r
+		case r == '\'' || r == '’':
+			return -1
+		default:
+			return ' '
+		}
+	}, title)
+
+	words := strings.Fields(title)
+	if len(words) > 1 {
+		switch words[0] {
+		case "the", "a", "an":
+			words = words[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// TitleSimilarity compares two titles after normalization and returns 1 for
+// identical titles, falling towards 0 as the edit distance grows.
+func TitleSimilarity(a, b string) float64 {
+	left := []rune(NormalizeTitle(a))
+	right := []rune(NormalizeTitle(b))
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+	longest := max(len(left), len(right))
+	return 1 - float64(levenshtein(left, right))/float64(longest)
+}
+
+// yearScore rates the distance between the requested and candidate years.
+// Missing years are neither rewarded nor heavily penalized.
+func yearScore(want, got string) float64 {
+	wantYear, wantErr := strconv.Atoi(strings.TrimSpace(want))
+	gotYear, gotErr := strconv.Atoi(strings.TrimSpace(got))
+	if wantErr != nil || gotErr != nil {
+		return 0.75
+	}
+
+	diff := wantYear - gotYear
+	if diff < 0 {
+		diff = -diff
+	}
+	switch diff {
+	case 0:
+		return 1
+	case 1:
+		return 0.8
+	case 2:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
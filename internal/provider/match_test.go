@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"The Office":                      "office",
+		"Marvel's Agents of S.H.I.E.L.D.": "marvels agents of s h i e l d",
+		"Law & Order":                     "law and order",
+		"  Spider-Man:  Homecoming ":      "spider man homecoming",
+		"Office, The":                     "office",
+		"The":                             "the",
+	}
+	for input, want := range tests {
+		if got := NormalizeTitle(input); got != want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestScoreMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		query     string
+		year      string
+		mediaType MediaType
+		candidate MatchCandidate
+		want      float64
+	}{
+		{name: "exact", query: "Heat", year: "1995", mediaType: MediaTypeMovie, candidate: MatchCandidate{Title: "Heat", Year: "1995", MediaType: MediaTypeMovie}, want: 1},
+		{name: "cosmetic differences", query: "Office, The", candidate: MatchCandidate{Title: "The Office"}, want: 0.95},
+		{name: "remake year", query: "The Lion King", year: "2019", candidate: MatchCandidate{Title: "The Lion King", Year: "1994"}, want: 0.8},
+		{name: "off by one year", query: "Dune", year: "2021", candidate: MatchCandidate{Title: "Dune", Year: "2020"}, want: 0.96},
+		{name: "alternate title", query: "Das Boot", candidate: MatchCandidate{Title: "The Boat", Titles: []string{"Das Boot"}}, want: 0.95},
+		{name: "wrong media type", query: "Fargo", mediaType: MediaTypeMovie, candidate: MatchCandidate{Title: "Fargo", MediaType: MediaTypeShow}, want: 0.85},
+		{name: "different title", query: "Heat", year: "1995", candidate: MatchCandidate{Title: "Heathers", Year: "1989"}, want: 0.45},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ScoreMatch(tc.query, tc.year, tc.mediaType, tc.candidate)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ScoreMatch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	t.Parallel()
+
+	candidates := []MatchCandidate{
+		{Title: "The Lion King", Year: "1994", MediaType: MediaTypeMovie},
+		{Title: "The Lion King", Year: "2019", MediaType: MediaTypeMovie},
+		{Title: "The Lion King II: Simba's Pride", Year: "1998", MediaType: MediaTypeMovie},
+	}
+
+	idx, score := BestMatch("The Lion King", "2019", MediaTypeMovie, candidates)
+	if diff := cmp.Diff(1, idx); diff != "" {
+		t.Errorf("BestMatch index mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1.0, score); diff != "" {
+		t.Errorf("BestMatch score mismatch (-want +got):\n%s", diff)
+	}
+
+	idx, _ = BestMatch("The Lion King", "", MediaTypeMovie, candidates)
+	if diff := cmp.Diff(0, idx); diff != "" {
+		t.Errorf("BestMatch without year should keep provider order (-want +got):\n%s", diff)
+	}
+
+	if idx, score := BestMatch("Anything", "", MediaTypeMovie, nil); idx != -1 || score != 0 {
+		t.Errorf("BestMatch(nil) = (%d, %v), want (-1, 0)", idx, score)
+	}
+}
@@ -5,10 +5,20 @@ import (
 	"fmt"
 )
 
-// MetadataCache provides safe access to cached provider metadata.
+// MetadataCache remembers the show a provider resolved for the seasons and
+// episodes of a show, so the show is searched once. Shows that weren't found
+// are remembered too, so the search strategies don't run again for every
+// episode.
 type MetadataCache interface {
-	Get(key string) (*Metadata, bool)
-	Set(key string, meta *Metadata)
+	Get(key string) (ShowLookup, bool)
+	Set(key string, lookup ShowLookup)
+}
+
+// ShowLookup is the outcome of a show search: the match, which may score
+// too low to use, or the NOT_FOUND error.
+type ShowLookup struct {
+	Meta *Metadata
+	Err  error
 }
 
 // GenerateMetadataKey creates a unique key for caching metadata.
@@ -29,8 +39,13 @@ func GenerateMetadataKey(mediaType string, name, year string, season, episode in
 
 // FetchMetadataWithDependencies fetches metadata with proper dependency resolution.
 // For episodes/seasons, it ensures show metadata is fetched first.
+// When the movie or show search finds nothing, each search strategy is tried in
+// order before giving up; the queries tried are reported through SearchError.
+// Season and episode confidence never exceeds the confidence of the show match.
+// ids holds IDs other providers resolved for the movie or show and is passed
+// on as ExtraKnownIDs; seasons and episodes get the IDs of the show instead.
 // Returns both metadata and error so callers can handle rate limiting properly.
-func FetchMetadataWithDependencies(ctx context.Context, metadataProvider Provider, name, year string, season, episode int, isMovie bool, cache MetadataCache) (*Metadata, error) {
+func FetchMetadataWithDependencies(ctx context.Context, metadataProvider Provider, name, year string, season, episode int, isMovie bool, cache MetadataCache, ids map[string]string, strategies ...SearchStrategy) (*Metadata, error) {
 	if metadataProvider == nil || name == "" {
 		return nil, nil
 	}
@@ -40,121 +55,169 @@ func FetchMetadataWithDependencies(ctx context.Context, metadataProvider Provide
 
 	var meta *Metadata
 	var err error
-	getFromCache := func(key string) *Metadata {
-		if cache == nil {
-			return nil
-		}
-		meta, _ := cache.Get(key)
-		return meta
-	}
-
-	setInCache := func(key string, meta *Metadata) {
-		if cache == nil || meta == nil {
-			return
-		}
-		cache.Set(key, meta)
-	}
 
 	if isMovie {
 		// Fetch movie metadata
-		request := FetchRequest{
-			MediaType: MediaTypeMovie,
-			Name:      name,
-			Year:      year,
-		}
-		meta, err = metadataProvider.Fetch(ctx, request)
-	} else if season > 0 && episode > 0 {
-		// For episodes, first get show metadata
-		showKey := GenerateMetadataKey("show", name, year, 0, 0)
-		showMeta := getFromCache(showKey)
-
-		if showMeta == nil {
-			// Fetch show metadata first
-			request := FetchRequest{
-				MediaType: MediaTypeShow,
-				Name:      name,
-			}
-			showMeta, err = metadataProvider.Fetch(ctx, request)
-			if err != nil {
-				return nil, err // Return error (including rate limiting) immediately
-			}
-			if showMeta != nil {
-				setInCache(showKey, showMeta)
-			}
-		}
-
-		if showMeta != nil {
-			// Extract show identifiers and metadata to enrich episode request
-			showID := extractShowID(showMeta)
-			showName := name
-			if showMeta.Core.Title != "" {
-				showName = showMeta.Core.Title
-			}
-			showYear := year
-			if showMeta.Core.Year != "" {
-				showYear = showMeta.Core.Year
-			}
-
-			request := FetchRequest{
-				MediaType: MediaTypeEpisode,
-				ID:        showID,
-				Name:      showName,
-				Year:      showYear,
-				Season:    season,
-				Episode:   episode,
-			}
-			meta, err = metadataProvider.Fetch(ctx, request)
-		}
+		meta, err = searchWithFallbacks(ctx, name, year, strategies, func(query, queryYear string) (*Metadata, error) {
+			request := withKnownIDs(FetchRequest{
+				MediaType: MediaTypeMovie,
+				Name:      query,
+				Year:      queryYear,
+			}, ids)
+			return metadataProvider.Fetch(ctx, request)
+		})
 	} else if season > 0 {
-		// For seasons, first get show metadata
-		showKey := GenerateMetadataKey("show", name, year, 0, 0)
-		showMeta := getFromCache(showKey)
-
-		if showMeta == nil {
-			// Fetch show metadata first
-			request := FetchRequest{
-				MediaType: MediaTypeShow,
-				Name:      name,
-			}
-			showMeta, err = metadataProvider.Fetch(ctx, request)
-			if err != nil {
-				return nil, err // Return error (including rate limiting) immediately
-			}
-			if showMeta != nil {
-				setInCache(showKey, showMeta)
-			}
+		// For seasons and episodes, first get show metadata
+		showMeta, err := cachedShow(ctx, metadataProvider, name, year, cache, ids, strategies)
+		if err != nil {
+			return nil, err // Return error (including rate limiting) immediately
 		}
 
 		if showMeta != nil {
-			showID := extractShowID(showMeta)
-			showName := name
-			if showMeta.Core.Title != "" {
-				showName = showMeta.Core.Title
-			}
-			showYear := year
-			if showMeta.Core.Year != "" {
-				showYear = showMeta.Core.Year
-			}
-
-			request := FetchRequest{
-				MediaType: MediaTypeSeason,
-				ID:        showID,
-				Name:      showName,
-				Year:      showYear,
-				Season:    season,
+			// Extract show identifiers and metadata to enrich the request
+			request := seasonRequest(metadataProvider, showMeta, name, year, season)
+			if episode > 0 {
+				request.MediaType = MediaTypeEpisode
+				request.Episode = episode
 			}
 			meta, err = metadataProvider.Fetch(ctx, request)
+			capConfidence(meta, showMeta)
+			return meta, err
 		}
 	} else {
 		// TV Show
-		request := FetchRequest{
+		meta, err = fetchShow(ctx, metadataProvider, name, year, ids, strategies)
+	}
+
+	return meta, err
+}
+
+// FetchSeasonEpisodesWithDependencies fetches every episode of a season in
+// one request when metadataProvider is a SeasonBatcher, resolving the show
+// first like FetchMetadataWithDependencies. Episodes are keyed by episode
+// number and their confidence never exceeds that of the show match. Other
+// providers return nil.
+func FetchSeasonEpisodesWithDependencies(ctx context.Context, metadataProvider Provider, name, year string, season int, cache MetadataCache, ids map[string]string, strategies ...SearchStrategy) (map[int]*Metadata, error) {
+	batcher, ok := metadataProvider.(SeasonBatcher)
+	if !ok || !BatchesSeasons(metadataProvider) || name == "" || season <= 0 {
+		return nil, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	showMeta, err := cachedShow(ctx, metadataProvider, name, year, cache, ids, strategies)
+	if err != nil || showMeta == nil {
+		return nil, err
+	}
+
+	episodes, err := batcher.FetchSeasonEpisodes(ctx, seasonRequest(metadataProvider, showMeta, name, year, season))
+	for _, meta := range episodes {
+		capConfidence(meta, showMeta)
+	}
+	return episodes, err
+}
+
+// fetchShow searches for a show, trying each search strategy when nothing
+// is found. The year tells remakes and same-name shows apart.
+func fetchShow(ctx context.Context, prov Provider, name, year string, ids map[string]string, strategies []SearchStrategy) (*Metadata, error) {
+	return searchWithFallbacks(ctx, name, year, strategies, func(query, queryYear string) (*Metadata, error) {
+		request := withKnownIDs(FetchRequest{
 			MediaType: MediaTypeShow,
-			Name:      name,
+			Name:      query,
+			Year:      queryYear,
+		}, ids)
+		return prov.Fetch(ctx, request)
+	})
+}
+
+// cachedShow returns the show lookup stored in cache, searching and storing
+// it first when it's missing. Matches are stored whatever their confidence,
+// since capConfidence carries a weak show match over to its episodes.
+// Failures other than NOT_FOUND, such as rate limits, aren't stored.
+func cachedShow(ctx context.Context, prov Provider, name, year string, cache MetadataCache, ids map[string]string, strategies []SearchStrategy) (*Metadata, error) {
+	showKey := GenerateMetadataKey("show", name, year, 0, 0)
+	if cache != nil {
+		if lookup, ok := cache.Get(showKey); ok {
+			return lookup.Meta, lookup.Err
 		}
-		meta, err = metadataProvider.Fetch(ctx, request)
 	}
 
-	return meta, err
+	showMeta, err := fetchShow(ctx, prov, name, year, ids, strategies)
+	if err != nil && !isSearchMiss(nil, err) {
+		return nil, err
+	}
+	if cache != nil {
+		cache.Set(showKey, ShowLookup{Meta: showMeta, Err: err})
+	}
+	return showMeta, err
+}
+
+// seasonRequest builds the request for a season of the show in showMeta,
+// preferring the show's matched title and year over the parsed ones. A
+// parsed title that differs from the match is passed on under ExtraShowName.
+func seasonRequest(prov Provider, showMeta *Metadata, name, year string, season int) FetchRequest {
+	parsed := name
+	if showMeta.Core.Title != "" {
+		name = showMeta.Core.Title
+	}
+	if showMeta.Core.Year != "" {
+		year = showMeta.Core.Year
+	}
+	request := withKnownIDs(FetchRequest{
+		MediaType: MediaTypeSeason,
+		ID:        showIDFor(prov, showMeta),
+		Name:      name,
+		Year:      year,
+		Season:    season,
+	}, showMeta.IDs)
+	if parsed != "" && NormalizeTitle(parsed) != NormalizeTitle(name) {
+		if request.Extra == nil {
+			request.Extra = make(map[string]interface{}, 1)
+		}
+		request.Extra[ExtraShowName] = parsed
+	}
+	return request
+}
+
+// capConfidence limits a season or episode's confidence to that of the show
+// match it was looked up through, since a wrong show means a wrong episode.
+// Unscored show metadata leaves the confidence untouched.
+func capConfidence(meta, showMeta *Metadata) {
+	if meta == nil || showMeta == nil || showMeta.Confidence <= 0 {
+		return
+	}
+	if showMeta.Confidence < meta.Confidence {
+		meta.Confidence = showMeta.Confidence
+	}
+}
+
+// showIDFor picks the ID prov fetches the seasons and episodes of a show by.
+// Show metadata may be merged from several providers, so providers that
+// report their own IDs get only one of theirs and never another provider's.
+func showIDFor(prov Provider, showMeta *Metadata) string {
+	if ider, ok := prov.(ExactIDer); ok {
+		return ider.ExactID(showMeta)
+	}
+	return extractShowID(showMeta)
+}
+
+// withKnownIDs returns request with a copy of ids under ExtraKnownIDs.
+func withKnownIDs(request FetchRequest, ids map[string]string) FetchRequest {
+	known := make(map[string]string, len(ids))
+	for key, id := range ids {
+		if id != "" {
+			known[key] = id
+		}
+	}
+	if len(known) == 0 {
+		return request
+	}
+	if request.Extra == nil {
+		request.Extra = make(map[string]interface{}, 1)
+	}
+	request.Extra[ExtraKnownIDs] = known
+	return request
 }
 
 func extractShowID(meta *Metadata) string {
@@ -8,20 +8,20 @@ import (
 )
 
 type testMetadataCache struct {
-	data map[string]*Metadata
+	data map[string]ShowLookup
 }
 
 func newTestMetadataCache() *testMetadataCache {
-	return &testMetadataCache{data: make(map[string]*Metadata)}
+	return &testMetadataCache{data: make(map[string]ShowLookup)}
 }
 
-func (c *testMetadataCache) Get(key string) (*Metadata, bool) {
-	meta, ok := c.data[key]
-	return meta, ok
+func (c *testMetadataCache) Get(key string) (ShowLookup, bool) {
+	lookup, ok := c.data[key]
+	return lookup, ok
 }
 
-func (c *testMetadataCache) Set(key string, meta *Metadata) {
-	c.data[key] = meta
+func (c *testMetadataCache) Set(key string, lookup ShowLookup) {
+	c.data[key] = lookup
 }
 
 type stubProvider struct {
@@ -106,7 +106,7 @@ func TestFetchMetadataWithDependenciesEarlyExit(t *testing.T) {
 
 	cache := newTestMetadataCache()
 
-	got, err := FetchMetadataWithDependencies(context.Background(), nil, "", "2020", 1, 1, false, cache)
+	got, err := FetchMetadataWithDependencies(context.Background(), nil, "", "2020", 1, 1, false, cache, nil)
 	if err != nil {
 		t.Fatalf("FetchMetadataWithDependencies(nil) returned error %v, want nil", err)
 	}
@@ -119,8 +119,11 @@ func TestFetchMetadataWithDependenciesEpisode(t *testing.T) {
 	t.Parallel()
 
 	cache := newTestMetadataCache()
-	showRequest := FetchRequest{MediaType: MediaTypeShow, Name: "Test Show"}
-	episodeRequest := FetchRequest{MediaType: MediaTypeEpisode, ID: "show-123", Name: "Test Show", Year: "2020", Season: 1, Episode: 5}
+	showRequest := FetchRequest{MediaType: MediaTypeShow, Name: "Test Show", Year: "2020"}
+	episodeRequest := FetchRequest{
+		MediaType: MediaTypeEpisode, ID: "show-123", Name: "Test Show", Year: "2020", Season: 1, Episode: 5,
+		Extra: map[string]interface{}{ExtraKnownIDs: map[string]string{"tmdb_id": "show-123"}},
+	}
 
 	calls := make([]FetchRequest, 0, 2)
 	stub := &stubProvider{
@@ -144,7 +147,7 @@ func TestFetchMetadataWithDependenciesEpisode(t *testing.T) {
 		},
 	}
 
-	got, err := FetchMetadataWithDependencies(context.Background(), stub, "Test Show", "2020", 1, 5, false, cache)
+	got, err := FetchMetadataWithDependencies(context.Background(), stub, "Test Show", "2020", 1, 5, false, cache, nil)
 	if err != nil {
 		t.Fatalf("FetchMetadataWithDependencies returned error %v, want nil", err)
 	}
@@ -163,7 +166,217 @@ func TestFetchMetadataWithDependenciesEpisode(t *testing.T) {
 		t.Fatalf("show metadata not cached")
 	}
 	wantCached := &Metadata{IDs: map[string]string{"tmdb_id": "show-123"}}
-	if diff := cmp.Diff(wantCached, cached); diff != "" {
+	if diff := cmp.Diff(wantCached, cached.Meta); diff != "" {
 		t.Errorf("cached show metadata (-want +got):\n%s", diff)
 	}
 }
+
+func TestFetchMetadataWithDependenciesCachesMissingShow(t *testing.T) {
+	t.Parallel()
+
+	searches := 0
+	stub := &stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		if req.MediaType == MediaTypeShow {
+			searches++
+		}
+		return nil, &ProviderError{Provider: "stub", Code: "NOT_FOUND"}
+	}}
+
+	// The show search and its fallbacks run once for the whole season
+	cache := newTestMetadataCache()
+	for episode := 1; episode <= 3; episode++ {
+		if _, err := FetchMetadataWithDependencies(context.Background(), stub, "The Office US", "", 1, episode, false, cache, nil, SearchStrategyStripCountry); err == nil {
+			t.Fatalf("episode %d: FetchMetadataWithDependencies() error = nil, want NOT_FOUND", episode)
+		}
+	}
+	if searches != 2 {
+		t.Errorf("show searches = %d, want 2 (the original query and one fallback)", searches)
+	}
+
+	// Rate limits aren't remembered
+	limited := &stubProvider{fetch: func(context.Context, FetchRequest) (*Metadata, error) {
+		return nil, &ProviderError{Provider: "stub", Code: "RATE_LIMITED", Retry: true}
+	}}
+	cache = newTestMetadataCache()
+	_, _ = FetchMetadataWithDependencies(context.Background(), limited, "Lost", "", 1, 1, false, cache, nil)
+	if _, ok := cache.Get(GenerateMetadataKey("show", "Lost", "", 0, 0)); ok {
+		t.Errorf("rate limited show lookup was cached")
+	}
+}
+
+func TestFetchMetadataWithDependenciesShowYear(t *testing.T) {
+	t.Parallel()
+
+	// Two shows share the title, so only the year tells them apart
+	shows := []MatchCandidate{
+		{Title: "Doctor Who", Year: "1963", MediaType: MediaTypeShow},
+		{Title: "Doctor Who", Year: "2005", MediaType: MediaTypeShow},
+	}
+	stub := &stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		best, score := BestMatch(req.Name, req.Year, MediaTypeShow, shows)
+		return &Metadata{Core: CoreMetadata{Title: shows[best].Title, Year: shows[best].Year}, Confidence: score}, nil
+	}}
+
+	for _, year := range []string{"1963", "2005"} {
+		meta, err := FetchMetadataWithDependencies(context.Background(), stub, "Doctor Who", year, 0, 0, false, nil, nil)
+		if err != nil {
+			t.Fatalf("FetchMetadataWithDependencies(%s) error = %v", year, err)
+		}
+		if meta.Core.Year != year {
+			t.Errorf("show for Doctor Who (%s) = %s (%s)", year, meta.Core.Title, meta.Core.Year)
+		}
+
+		// Episodes resolve their show the same way
+		cache := newTestMetadataCache()
+		if _, err := FetchMetadataWithDependencies(context.Background(), stub, "Doctor Who", year, 1, 1, false, cache, nil); err != nil {
+			t.Fatalf("FetchMetadataWithDependencies(%s, S01E01) error = %v", year, err)
+		}
+		lookup, _ := cache.Get(GenerateMetadataKey("show", "Doctor Who", year, 0, 0))
+		if lookup.Meta == nil || lookup.Meta.Core.Year != year {
+			t.Errorf("episode show for Doctor Who (%s) = %+v", year, lookup.Meta)
+		}
+	}
+
+	// A year the provider can't match falls back to a search without it
+	var years []string
+	strict := &stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		years = append(years, req.Year)
+		if req.Year != "" {
+			return nil, &ProviderError{Provider: "stub", Code: "NOT_FOUND"}
+		}
+		return &Metadata{Core: CoreMetadata{Title: "Doctor Who"}}, nil
+	}}
+	meta, err := FetchMetadataWithDependencies(context.Background(), strict, "Doctor Who", "2006", 0, 0, false, nil, nil, SearchStrategyDropYear)
+	if err != nil || meta == nil {
+		t.Fatalf("FetchMetadataWithDependencies(drop_year) = %v, %v, want the show", meta, err)
+	}
+	if diff := cmp.Diff([]string{"2006", ""}, years); diff != "" {
+		t.Errorf("searched years mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// idStubProvider reports its own IDs like the built-in providers do.
+type idStubProvider struct {
+	stubProvider
+}
+
+func (p *idStubProvider) ExactID(meta *Metadata) string {
+	return meta.IDs["stub_id"]
+}
+
+func TestFetchMetadataWithDependenciesPassesKnownIDs(t *testing.T) {
+	t.Parallel()
+
+	var got []FetchRequest
+	stub := &idStubProvider{stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		got = append(got, req)
+		return &Metadata{}, nil
+	}}}
+
+	known := map[string]string{"imdb_id": "tt0113277", "tmdb_id": ""}
+	if _, err := FetchMetadataWithDependencies(context.Background(), stub, "Heat", "1995", 0, 0, true, nil, known); err != nil {
+		t.Fatalf("FetchMetadataWithDependencies(movie) returned error %v", err)
+	}
+
+	// The merged show has another provider's ID, which must not be passed
+	// as this provider's own.
+	cache := newTestMetadataCache()
+	cache.Set(GenerateMetadataKey("show", "Lost", "2004", 0, 0), ShowLookup{Meta: &Metadata{IDs: map[string]string{"tmdb_id": "4607", "imdb_id": "tt0411008"}}})
+	if _, err := FetchMetadataWithDependencies(context.Background(), stub, "Lost", "2004", 1, 2, false, cache, nil); err != nil {
+		t.Fatalf("FetchMetadataWithDependencies(episode) returned error %v", err)
+	}
+
+	want := []FetchRequest{
+		{MediaType: MediaTypeMovie, Name: "Heat", Year: "1995", Extra: map[string]interface{}{ExtraKnownIDs: map[string]string{"imdb_id": "tt0113277"}}},
+		{MediaType: MediaTypeEpisode, Name: "Lost", Year: "2004", Season: 1, Episode: 2, Extra: map[string]interface{}{ExtraKnownIDs: map[string]string{"tmdb_id": "4607", "imdb_id": "tt0411008"}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+	if id := want[1].KnownID("imdb_id"); id != "tt0411008" {
+		t.Errorf("KnownID(imdb_id) = %q, want tt0411008", id)
+	}
+}
+
+func TestFetchMetadataWithDependenciesPassesParsedShowName(t *testing.T) {
+	t.Parallel()
+
+	var episodeRequest FetchRequest
+	stub := &stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		if req.MediaType == MediaTypeShow {
+			return &Metadata{Core: CoreMetadata{Title: "Money Heist", Year: "2017"}}, nil
+		}
+		episodeRequest = req
+		return &Metadata{}, nil
+	}}
+
+	if _, err := FetchMetadataWithDependencies(context.Background(), stub, "La casa de papel", "", 1, 2, false, newTestMetadataCache(), nil); err != nil {
+		t.Fatalf("FetchMetadataWithDependencies() returned error %v", err)
+	}
+	if episodeRequest.Name != "Money Heist" || episodeRequest.ShowName() != "La casa de papel" {
+		t.Errorf("episode request Name = %q, ShowName() = %q; want Money Heist, La casa de papel", episodeRequest.Name, episodeRequest.ShowName())
+	}
+	if CacheKey(episodeRequest) == CacheKey(FetchRequest{MediaType: MediaTypeEpisode, Name: "Money Heist", Year: "2017", Season: 1, Episode: 2}) {
+		t.Errorf("CacheKey() ignores the parsed show name")
+	}
+}
+
+// batchStubProvider fetches whole seasons like the built-in providers do.
+type batchStubProvider struct {
+	idStubProvider
+	batches []FetchRequest
+}
+
+func (p *batchStubProvider) FetchSeasonEpisodes(_ context.Context, req FetchRequest) (map[int]*Metadata, error) {
+	p.batches = append(p.batches, req)
+	return map[int]*Metadata{
+		1: {Core: CoreMetadata{EpisodeName: "Pilot"}, Confidence: 1},
+		2: {Core: CoreMetadata{EpisodeName: "Second"}, Confidence: 1},
+	}, nil
+}
+
+func (p *batchStubProvider) SeasonEpisodeVariables() []string { return []string{"episode_title"} }
+
+func TestFetchSeasonEpisodesWithDependencies(t *testing.T) {
+	t.Parallel()
+
+	stub := &batchStubProvider{idStubProvider: idStubProvider{stubProvider{fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+		return &Metadata{Core: CoreMetadata{Title: "Lost", Year: "2004"}, IDs: map[string]string{"stub_id": "42"}, Confidence: 0.8}, nil
+	}}}}
+
+	cache := newTestMetadataCache()
+	episodes, err := FetchSeasonEpisodesWithDependencies(context.Background(), stub, "lost", "", 1, cache, nil)
+	if err != nil {
+		t.Fatalf("FetchSeasonEpisodesWithDependencies() returned error %v", err)
+	}
+
+	want := []FetchRequest{{
+		MediaType: MediaTypeSeason,
+		ID:        "42",
+		Name:      "Lost",
+		Year:      "2004",
+		Season:    1,
+		Extra:     map[string]interface{}{ExtraKnownIDs: map[string]string{"stub_id": "42"}},
+	}}
+	if diff := cmp.Diff(want, stub.batches); diff != "" {
+		t.Errorf("season requests mismatch (-want +got):\n%s", diff)
+	}
+	if len(episodes) != 2 || episodes[2].Core.EpisodeName != "Second" {
+		t.Errorf("episodes = %+v, want episodes 1 and 2", episodes)
+	}
+	if got := episodes[1].Confidence; got != 0.8 {
+		t.Errorf("episode confidence = %v, want it capped at the show's 0.8", got)
+	}
+	if _, ok := cache.Get(GenerateMetadataKey("show", "lost", "", 0, 0)); !ok {
+		t.Errorf("show metadata was not cached")
+	}
+
+	// Wrapping a provider that can't batch doesn't make it batch.
+	plain := NewResilient(&stubProvider{}, DefaultPolicy())
+	if BatchesSeasons(plain) || !BatchesSeasons(NewResilient(stub, DefaultPolicy())) {
+		t.Errorf("BatchesSeasons() doesn't look through the Resilient wrapper")
+	}
+	if episodes, err := FetchSeasonEpisodesWithDependencies(context.Background(), plain, "lost", "", 1, nil, nil); episodes != nil || err != nil {
+		t.Errorf("FetchSeasonEpisodesWithDependencies(non-batching) = %v, %v; want nil, nil", episodes, err)
+	}
+}
@@ -2,6 +2,7 @@ package omdb
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -43,9 +44,9 @@ func (p *Provider) fetchMovie(ctx context.Context, request provider.FetchRequest
 
 	switch movie := result.(type) {
 	case omdb.MovieResult:
-		return p.movieResultToMetadata(movie), nil
+		return scoreResult(p.movieResultToMetadata(movie), request), nil
 	case *omdb.MovieResult:
-		return p.movieResultToMetadata(*movie), nil
+		return scoreResult(p.movieResultToMetadata(*movie), request), nil
 	default:
 		return nil, &provider.ProviderError{
 			Provider: providerName,
@@ -89,9 +90,9 @@ func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest)
 
 	switch series := result.(type) {
 	case omdb.SeriesResult:
-		return p.seriesResultToMetadata(series), nil
+		return scoreResult(p.seriesResultToMetadata(series), request), nil
 	case *omdb.SeriesResult:
-		return p.seriesResultToMetadata(*series), nil
+		return scoreResult(p.seriesResultToMetadata(*series), request), nil
 	default:
 		return nil, &provider.ProviderError{
 			Provider: providerName,
@@ -102,6 +103,40 @@ func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest)
 }
 
 func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	season, err := p.seasonResult(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return p.seasonResultToMetadata(season, request), nil
+}
+
+// FetchSeasonEpisodes fills every episode of a season from the season
+// listing. The listing has each episode's title, release date and rating.
+func (p *Provider) FetchSeasonEpisodes(ctx context.Context, request provider.FetchRequest) (map[int]*provider.Metadata, error) {
+	season, err := p.seasonResult(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	episodes := make(map[int]*provider.Metadata, len(season.Episodes))
+	for i := range season.Episodes {
+		num, err := strconv.Atoi(strings.TrimSpace(season.Episodes[i].Episode))
+		if err != nil || num <= 0 {
+			continue
+		}
+		episodes[num] = p.seasonEpisodeToMetadata(&season.Episodes[i], season, request, num)
+	}
+	return episodes, nil
+}
+
+// SeasonEpisodeVariables lists the episode variables the season listing
+// fills.
+func (p *Provider) SeasonEpisodeVariables() []string {
+	return []string{"episode_title", "rating"}
+}
+
+// seasonResult looks a season up by the series IMDb ID, or by title.
+func (p *Provider) seasonResult(ctx context.Context, request provider.FetchRequest) (*omdb.SeasonResult, error) {
 	if request.Season <= 0 {
 		return nil, &provider.ProviderError{
 			Provider: providerName,
@@ -145,9 +180,9 @@ func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchReques
 
 	switch season := result.(type) {
 	case omdb.SeasonResult:
-		return p.seasonResultToMetadata(&season, request), nil
+		return &season, nil
 	case *omdb.SeasonResult:
-		return p.seasonResultToMetadata(season, request), nil
+		return season, nil
 	default:
 		return nil, &provider.ProviderError{
 			Provider: providerName,
@@ -215,6 +250,82 @@ func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchReque
 	}
 }
 
+// ExactID returns the IMDb ID of the movie or series behind metadata from
+// this provider. Episodes report their series.
+func (p *Provider) ExactID(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	if id := meta.IDs["series_id"]; id != "" {
+		return id
+	}
+	return meta.IDs["imdb_id"]
+}
+
+// SearchCandidates lists the movie or series search results for a request,
+// best match first. Candidate IDs are IMDb IDs.
+func (p *Provider) SearchCandidates(ctx context.Context, request provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	if p.client == nil || p.apiKey == "" {
+		return nil, fmt.Errorf("provider not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := omdb.QueryData{Title: strings.TrimSpace(request.Name), Year: request.Year}
+	switch request.MediaType {
+	case provider.MediaTypeMovie:
+		query.SearchType = "movie"
+	case provider.MediaTypeShow:
+		query.SearchType = "series"
+		query.Year = ""
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", request.MediaType)
+	}
+	if query.Title == "" {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "INVALID_REQUEST",
+			Message:  "candidate search requires a title",
+		}
+	}
+
+	resp, err := p.client.SearchByText(query)
+	if err != nil {
+		return nil, p.mapError(err)
+	}
+
+	candidates := make([]provider.Candidate, 0, len(resp.Search))
+	for _, result := range resp.Search {
+		if result.ImdbID == "" {
+			continue
+		}
+		candidates = append(candidates, provider.Candidate{
+			ID:        result.ImdbID,
+			Title:     result.Title,
+			Year:      omdb.FirstYear(result.Year),
+			MediaType: request.MediaType,
+		})
+	}
+
+	return provider.RankCandidates(request.Name, request.Year, request.MediaType, candidates, limit), nil
+}
+
+// scoreResult sets the confidence of a title search from how well the single
+// result OMDb returns matches the request. IMDb ID lookups are exact.
+func scoreResult(meta *provider.Metadata, request provider.FetchRequest) *provider.Metadata {
+	if request.ID != "" {
+		meta.Confidence = 1.0
+		return meta
+	}
+	meta.Confidence = provider.ScoreMatch(request.Name, request.Year, request.MediaType, provider.MatchCandidate{
+		Title:     meta.Core.Title,
+		Year:      meta.Core.Year,
+		MediaType: meta.Core.MediaType,
+	})
+	return meta
+}
+
 func (p *Provider) movieResultToMetadata(result omdb.MovieResult) *provider.Metadata {
 	genres := omdb.SplitAndTrim(result.Genre)
 
@@ -335,6 +446,41 @@ func (p *Provider) seasonResultToMetadata(resp *omdb.SeasonResult, request provi
 	return meta
 }
 
+func (p *Provider) seasonEpisodeToMetadata(resp *omdb.SeasonEpisode, season *omdb.SeasonResult, request provider.FetchRequest, episode int) *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:       request.Name,
+			Year:        omdb.FirstYear(resp.Released),
+			SeasonNum:   request.Season,
+			EpisodeNum:  episode,
+			EpisodeName: resp.Title,
+			MediaType:   provider.MediaTypeEpisode,
+			Rating:      omdb.ParseRating(resp.ImdbRating),
+		},
+		Extended:   make(map[string]interface{}),
+		Sources:    make(map[string]string),
+		IDs:        make(map[string]string),
+		Confidence: 0.85,
+	}
+
+	if meta.Core.Title == "" {
+		meta.Core.Title = season.Title
+	}
+
+	if resp.ImdbID != "" {
+		meta.IDs["imdb_id"] = resp.ImdbID
+		meta.Sources["imdb_id"] = providerName
+	}
+	if request.ID != "" {
+		meta.IDs["series_id"] = request.ID
+	}
+
+	meta.Sources["episode_title"] = providerName
+	meta.Sources["rating"] = providerName
+
+	return meta
+}
+
 func (p *Provider) episodeResultToMetadata(resp *omdb.EpisodeResult, request provider.FetchRequest) *provider.Metadata {
 	genres := omdb.SplitAndTrim(resp.Genre)
 
@@ -2,6 +2,7 @@ package omdb
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -150,11 +151,43 @@ func (p *Provider) Configure(config map[string]interface{}) error {
 
 	p.apiKey = apiKey
 	p.config = config
-	p.client = omdb.NewClient(p.apiKey, p.httpClient)
+	p.client = omdb.NewClient(p.apiKey, withStatusErrors(p.httpClient))
 
 	return nil
 }
 
+// withStatusErrors returns a copy of client whose non-200 responses fail
+// with a provider.StatusError, since the omdb client reduces them to a
+// message with just the status code.
+func withStatusErrors(client *http.Client) *http.Client {
+	wrapped := *client
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	wrapped.Transport = statusTransport{next: transport}
+	return &wrapped
+}
+
+type statusTransport struct {
+	next http.RoundTripper
+}
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode == http.StatusOK {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	// OMDb explains failures in the body, such as a spent daily limit
+	var body struct {
+		Error string `json:"Error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return nil, provider.NewStatusError(resp, body.Error)
+}
+
 // Fetch retrieves metadata for the given request.
 func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
 	if p.client == nil || p.apiKey == "" {
@@ -165,6 +198,11 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 		return nil, err
 	}
 
+	// An IMDb ID another provider resolved makes the lookup exact
+	if request.ID == "" {
+		request.ID = request.KnownID("imdb_id")
+	}
+
 	switch request.MediaType {
 	case provider.MediaTypeMovie:
 		return p.fetchMovie(ctx, request)
@@ -179,6 +217,8 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 	}
 }
 
+// mapError maps OMDb errors to provider errors by their HTTP status, or by
+// the error OMDb reported in a 200 response.
 func (p *Provider) mapError(err error) error {
 	if err == nil {
 		return nil
@@ -187,40 +227,29 @@ func (p *Provider) mapError(err error) error {
 		return err
 	}
 
-	msg := err.Error()
-	lower := strings.ToLower(msg)
-
-	switch {
-	case strings.Contains(lower, "invalid api key"), strings.Contains(lower, "missing omdb api key"):
-		return &provider.ProviderError{
-			Provider: providerName,
-			Code:     "AUTH_FAILED",
-			Message:  "OMDb authentication failed: " + msg,
-			Retry:    false,
-		}
-	case strings.Contains(lower, "not found"):
-		return &provider.ProviderError{
-			Provider: providerName,
-			Code:     "NOT_FOUND",
-			Message:  msg,
-			Retry:    false,
-		}
-	case strings.Contains(lower, "limit reached"), strings.Contains(lower, "too many requests"):
-		return &provider.ProviderError{
-			Provider:   providerName,
-			Code:       "RATE_LIMITED",
-			Message:    msg,
-			Retry:      true,
-			RetryAfter: 5,
-		}
-	default:
-		return &provider.ProviderError{
-			Provider: providerName,
-			Code:     "UNKNOWN",
-			Message:  msg,
-			Retry:    false,
-		}
+	var statusErr *provider.StatusError
+	if errors.As(err, &statusErr) && statusErr.Status == http.StatusUnauthorized && statusErr.Message == dailyLimitError {
+		// OMDb answers a spent daily limit with 401 too
+		return &provider.ProviderError{Provider: providerName, Code: "RATE_LIMITED", Message: "OMDb daily request limit reached", Retry: true, RetryAfter: statusErr.RetryAfter}
 	}
+	if provErr := provider.MapStatusError(providerName, "OMDb", err); provErr != nil {
+		return provErr
+	}
+
+	if apiErr, ok := responseError(err); ok && strings.HasSuffix(strings.TrimRight(apiErr, "!."), "not found") {
+		return &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: apiErr, Retry: false}
+	}
+	return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: "OMDb error: " + err.Error(), Retry: false}
+}
+
+// dailyLimitError is the error OMDb reports once the API key's daily
+// requests are used up.
+const dailyLimitError = "Request limit reached!"
+
+// responseError returns the error OMDb reported in a response with
+// "Response": "False", which the omdb client passes on as a plain error.
+func responseError(err error) (string, bool) {
+	return strings.CutPrefix(strings.TrimPrefix(err.Error(), "omdb: "), "Error from OMDB API: ")
 }
 
 // parseRuntime attempts to convert runtime strings (e.g., "136 min") to integer minutes.
@@ -2,6 +2,7 @@ package omdb
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -88,6 +89,80 @@ func TestFetchMovie(t *testing.T) {
 	if meta.Extended["runtime"].(int) != 169 {
 		t.Fatalf("runtime = %v, want 169", meta.Extended["runtime"])
 	}
+
+	if meta.Confidence != 1.0 {
+		t.Fatalf("Confidence = %v, want 1", meta.Confidence)
+	}
+}
+
+func TestFetchMovieScoresMismatchedResult(t *testing.T) {
+	prov := New()
+	prov.httpClient = newTestClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(200, `{
+            "Title": "Heathers",
+            "Year": "1988",
+            "Type": "movie",
+            "Response": "True"
+        }`), nil
+	})
+
+	if err := prov.Configure(map[string]interface{}{"api_key": "testing"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Name:      "Heat",
+		Year:      "1995",
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if meta.Confidence >= provider.DefaultMatchThreshold {
+		t.Fatalf("Confidence = %v, want below %v", meta.Confidence, provider.DefaultMatchThreshold)
+	}
+}
+
+func TestSearchCandidates(t *testing.T) {
+	prov := New()
+	prov.httpClient = newTestClient(func(req *http.Request) (*http.Response, error) {
+		if got := req.URL.Query().Get("s"); got != "Heat" {
+			t.Errorf("search query = %q, want Heat", got)
+		}
+		return jsonResponse(200, `{
+            "Search": [
+                {"Title": "Heathers", "Year": "1988", "imdbID": "tt0097493", "Type": "movie"},
+                {"Title": "Heat", "Year": "1995", "imdbID": "tt0113277", "Type": "movie"},
+                {"Title": "Heat", "Year": "1986", "imdbID": "tt0093164", "Type": "movie"}
+            ],
+            "totalResults": "3",
+            "Response": "True"
+        }`), nil
+	})
+
+	if err := prov.Configure(map[string]interface{}{"api_key": "testing"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	candidates, err := prov.SearchCandidates(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Name:      "Heat",
+		Year:      "1995",
+	}, 2)
+	if err != nil {
+		t.Fatalf("SearchCandidates() error = %v", err)
+	}
+
+	if len(candidates) != 2 {
+		t.Fatalf("len(candidates) = %d, want 2", len(candidates))
+	}
+	if candidates[0].ID != "tt0113277" || candidates[1].ID != "tt0093164" {
+		t.Fatalf("candidate IDs = %q, %q, want tt0113277, tt0093164", candidates[0].ID, candidates[1].ID)
+	}
+	if candidates[0].Confidence != 1.0 {
+		t.Fatalf("Confidence = %v, want 1", candidates[0].Confidence)
+	}
 }
 
 func TestFetchEpisode(t *testing.T) {
@@ -144,4 +219,132 @@ func TestFetchEpisode(t *testing.T) {
 	if got := meta.IDs["imdb_id"]; got != "tt1480055" {
 		t.Fatalf("imdb_id = %q, want tt1480055", got)
 	}
+
+	// Overrides pin the series, not the episode.
+	if got := prov.ExactID(meta); got != "tt0944947" {
+		t.Fatalf("ExactID() = %q, want tt0944947", got)
+	}
+}
+
+func TestFetchMovieByKnownIMDbID(t *testing.T) {
+	var query string
+	prov := New()
+	prov.httpClient = newTestClient(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return jsonResponse(200, `{
+            "Title": "Heat",
+            "Year": "1995",
+            "imdbID": "tt0113277",
+            "Type": "movie",
+            "Response": "True"
+        }`), nil
+	})
+
+	if err := prov.Configure(map[string]interface{}{"api_key": "testing"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeMovie,
+		Name:      "Heat 1995 Remastered",
+		Extra:     map[string]interface{}{provider.ExtraKnownIDs: map[string]string{"imdb_id": "tt0113277"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if !strings.Contains(query, "i=tt0113277") || strings.Contains(query, "t=") {
+		t.Errorf("query = %q, want an exact i=tt0113277 lookup", query)
+	}
+	if meta.Confidence != 1.0 {
+		t.Errorf("Confidence = %v, want 1", meta.Confidence)
+	}
+}
+
+func TestFetchSeasonEpisodes(t *testing.T) {
+	calls := 0
+	prov := New()
+	prov.httpClient = newTestClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if q := req.URL.Query(); q.Get("Season") != "1" || q.Get("Episode") != "" {
+			t.Errorf("query = %q, want a season listing", req.URL.RawQuery)
+		}
+		return jsonResponse(200, `{
+            "Title": "Game of Thrones",
+            "Season": "1",
+            "Episodes": [
+                {"Title": "Winter Is Coming", "Released": "2011-04-17", "Episode": "1", "imdbRating": "8.9", "imdbID": "tt1480055"},
+                {"Title": "The Kingsroad", "Released": "2011-04-24", "Episode": "2", "imdbRating": "8.6", "imdbID": "tt1668746"}
+            ],
+            "Response": "True"
+        }`), nil
+	})
+
+	if err := prov.Configure(map[string]interface{}{"api_key": "testing"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	episodes, err := prov.FetchSeasonEpisodes(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeSeason,
+		Name:      "Game of Thrones",
+		Season:    1,
+		ID:        "tt0944947",
+	})
+	if err != nil {
+		t.Fatalf("FetchSeasonEpisodes() error = %v", err)
+	}
+
+	if calls != 1 || len(episodes) != 2 {
+		t.Fatalf("got %d episodes from %d requests, want 2 from 1", len(episodes), calls)
+	}
+	meta := episodes[2]
+	if meta == nil || meta.Core.EpisodeName != "The Kingsroad" || meta.Core.EpisodeNum != 2 || meta.Core.SeasonNum != 1 {
+		t.Fatalf("episode 2 = %+v, want The Kingsroad S01E02", meta)
+	}
+	if meta.Core.Rating == 0 || meta.IDs["imdb_id"] != "tt1668746" {
+		t.Errorf("episode 2 rating %v, imdb_id %q; want both parsed", meta.Core.Rating, meta.IDs["imdb_id"])
+	}
+	if got := prov.ExactID(meta); got != "tt0944947" {
+		t.Errorf("ExactID() = %q, want tt0944947", got)
+	}
+}
+
+func TestFetchMapsErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		retryAfter     string
+		body           string
+		wantCode       string
+		wantRetryAfter int
+	}{
+		{name: "invalid_key", status: 401, body: `{"Response": "False", "Error": "Invalid API key!"}`, wantCode: "AUTH_FAILED"},
+		{name: "daily_limit", status: 401, body: `{"Response": "False", "Error": "Request limit reached!"}`, wantCode: "RATE_LIMITED"},
+		{name: "unavailable", status: 503, retryAfter: "20", wantCode: "UNAVAILABLE", wantRetryAfter: 20},
+		{name: "not_found", status: 200, body: `{"Response": "False", "Error": "Movie not found!"}`, wantCode: "NOT_FOUND"},
+		{name: "server_error", status: 500, wantCode: "UNKNOWN"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			prov := New()
+			prov.httpClient = newTestClient(func(req *http.Request) (*http.Response, error) {
+				resp := jsonResponse(tc.status, tc.body)
+				if tc.retryAfter != "" {
+					resp.Header.Set("Retry-After", tc.retryAfter)
+				}
+				return resp, nil
+			})
+			if err := prov.Configure(map[string]interface{}{"api_key": "testing"}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			// The title holds digits that look like status codes
+			_, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Room 401 503"})
+			var provErr *provider.ProviderError
+			if !errors.As(err, &provErr) || provErr.Code != tc.wantCode || provErr.RetryAfter != tc.wantRetryAfter {
+				t.Errorf("Fetch() error = %#v, want %s after %ds", err, tc.wantCode, tc.wantRetryAfter)
+			}
+		})
+	}
 }
@@ -0,0 +1,168 @@
+package opensubtitles
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// userAgent identifies the app, as the OpenSubtitles API requires.
+const userAgent = "title-tidy"
+
+type searchResponse struct {
+	Data []struct {
+		Attributes struct {
+			MovieHashMatch bool           `json:"moviehash_match"`
+			FeatureDetails featureDetails `json:"feature_details"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// featureDetails describes the movie or episode a subtitle belongs to.
+// Episodes name their show through the parent fields.
+type featureDetails struct {
+	FeatureID     int    `json:"feature_id"`
+	FeatureType   string `json:"feature_type"`
+	Year          int    `json:"year"`
+	Title         string `json:"title"`
+	IMDBID        int    `json:"imdb_id"`
+	TMDBID        int    `json:"tmdb_id"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	ParentTitle   string `json:"parent_title"`
+	ParentIMDBID  int    `json:"parent_imdb_id"`
+	ParentTMDBID  int    `json:"parent_tmdb_id"`
+}
+
+// lookup asks the API which movie or episode has subtitles matched to hash.
+// Subtitles matched by name rather than hash are ignored, and when the
+// matches disagree the feature most of them name wins.
+func (p *Provider) lookup(ctx context.Context, hash string) (*provider.Metadata, error) {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var resp searchResponse
+	if err := p.getJSON(ctx, "/subtitles", url.Values{"moviehash": {hash}}, &resp); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, p.mapError(err)
+	}
+
+	counts := make(map[int]int)
+	var best *featureDetails
+	for i := range resp.Data {
+		attrs := &resp.Data[i].Attributes
+		if !attrs.MovieHashMatch || attrs.FeatureDetails.Title == "" {
+			continue
+		}
+		feature := &attrs.FeatureDetails
+		counts[feature.FeatureID]++
+		if best == nil || counts[feature.FeatureID] > counts[best.FeatureID] {
+			best = feature
+		}
+	}
+	if best == nil {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "NOT_FOUND",
+			Message:  fmt.Sprintf("no movie or episode matches hash %s", hash),
+			Retry:    false,
+		}
+	}
+	return featureMetadata(best, hash), nil
+}
+
+// featureMetadata converts a feature to metadata. Episodes carry the IDs of
+// their show, like the other providers' episode results.
+func featureMetadata(feature *featureDetails, hash string) *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:     strings.TrimSpace(feature.Title),
+			MediaType: provider.MediaTypeMovie,
+		},
+		Extended:   map[string]interface{}{"movie_hash": hash},
+		Sources:    make(map[string]string),
+		IDs:        make(map[string]string),
+		Confidence: 1.0,
+	}
+	if feature.Year > 0 {
+		meta.Core.Year = strconv.Itoa(feature.Year)
+	}
+
+	imdbID, tmdbID := feature.IMDBID, feature.TMDBID
+	if strings.EqualFold(feature.FeatureType, "episode") {
+		meta.Core.MediaType = provider.MediaTypeEpisode
+		meta.Core.Title = strings.TrimSpace(feature.ParentTitle)
+		meta.Core.EpisodeName = strings.TrimSpace(feature.Title)
+		meta.Core.SeasonNum = feature.SeasonNumber
+		meta.Core.EpisodeNum = feature.EpisodeNumber
+		meta.Extended["episode_title"] = meta.Core.EpisodeName
+		imdbID, tmdbID = feature.ParentIMDBID, feature.ParentTMDBID
+	}
+	if imdbID > 0 {
+		meta.IDs["imdb_id"] = fmt.Sprintf("tt%07d", imdbID)
+	}
+	if tmdbID > 0 {
+		meta.IDs["tmdb_id"] = strconv.Itoa(tmdbID)
+	}
+
+	credit(meta)
+	return meta
+}
+
+// credit records this provider as the source of every populated field.
+func credit(meta *provider.Metadata) {
+	populated := map[string]bool{
+		"title":         meta.Core.Title != "",
+		"year":          meta.Core.Year != "",
+		"episode_title": meta.Core.EpisodeName != "",
+	}
+	for field, ok := range populated {
+		if ok {
+			meta.Sources[field] = providerName
+		}
+	}
+	for key := range meta.Extended {
+		meta.Sources[key] = providerName
+	}
+	for key := range meta.IDs {
+		meta.Sources[key] = providerName
+	}
+}
+
+func (p *Provider) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := p.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Api-Key", p.apiKey)
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return provider.NewStatusError(resp, "GET "+path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: invalid response: %w", path, err)
+	}
+	return nil
+}
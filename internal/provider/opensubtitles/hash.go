@@ -0,0 +1,43 @@
+package opensubtitles
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// hashChunkSize is how much of each end of the file the hash reads.
+const hashChunkSize = 64 * 1024
+
+// Hash computes the OpenSubtitles movie hash of the file at path: the file
+// size plus the sum of the little-endian 64-bit words in its first and last
+// 64 KB, as 16 hex digits. Files smaller than one chunk can't be hashed.
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+	if size < hashChunkSize {
+		return "", fmt.Errorf("%s is too small to hash (%d bytes)", path, size)
+	}
+
+	sum := uint64(size)
+	buf := make([]byte, hashChunkSize)
+	for _, offset := range []int64{0, size - hashChunkSize} {
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return "", err
+		}
+		for i := 0; i < hashChunkSize; i += 8 {
+			sum += binary.LittleEndian.Uint64(buf[i:])
+		}
+	}
+	return fmt.Sprintf("%016x", sum), nil
+}
@@ -0,0 +1,237 @@
+package opensubtitles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+const (
+	providerName = "opensubtitles"
+	filePathKey  = "path"
+
+	// DefaultURL is the OpenSubtitles REST API.
+	DefaultURL = "https://api.opensubtitles.com/api/v1"
+)
+
+// Provider identifies movies and episodes by the OpenSubtitles hash of the
+// file instead of its name, for files named like DSC0001.mp4.
+type Provider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	limiter    *provider.RateLimiter
+	cache      *provider.DiskCache
+	offline    bool
+}
+
+// New creates a new OpenSubtitles provider instance.
+func New() *Provider {
+	return &Provider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    DefaultURL,
+		// OpenSubtitles allows 5 requests a second
+		limiter: provider.NewRateLimiter(5, time.Second),
+	}
+}
+
+// SetCache attaches the cache lookups are kept in. Results are keyed by the
+// file hash, so renamed or moved files aren't looked up again.
+func (p *Provider) SetCache(cache *provider.DiskCache) {
+	p.cache = cache
+}
+
+// SetOffline limits the provider to cached lookups.
+func (p *Provider) SetOffline(offline bool) {
+	p.offline = offline
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// Description returns a human readable description of the provider.
+func (p *Provider) Description() string {
+	return "Identifies movies and episodes by the OpenSubtitles hash of the file"
+}
+
+// Capabilities returns what this provider can handle.
+func (p *Provider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{
+		MediaTypes: []provider.MediaType{
+			provider.MediaTypeMovie,
+			provider.MediaTypeEpisode,
+		},
+		RequiresAuth: true,
+		RequiresFile: true,
+		Priority:     75,
+	}
+}
+
+// SupportedVariables returns the template variables supplied by this provider.
+func (p *Provider) SupportedVariables() []provider.TemplateVariable {
+	mediaTypes := []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeEpisode}
+
+	return []provider.TemplateVariable{
+		{
+			Name:        "episode_title",
+			DisplayName: "Episode Title",
+			Description: "Title of the episode",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeEpisode},
+			Example:     "Pilot",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "imdb_id",
+			DisplayName: "IMDB ID",
+			Description: "Internet Movie Database ID of the movie or show",
+			MediaTypes:  mediaTypes,
+			Example:     "tt0113277",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "tmdb_id",
+			DisplayName: "TMDB ID",
+			Description: "The Movie Database ID of the movie or show",
+			MediaTypes:  mediaTypes,
+			Example:     "949",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "movie_hash",
+			DisplayName: "Movie Hash",
+			Description: "OpenSubtitles hash of the file",
+			MediaTypes:  mediaTypes,
+			Example:     "8e245d9679d31e12",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+	}
+}
+
+// ConfigSchema returns the configuration schema for this provider.
+func (p *Provider) ConfigSchema() provider.ConfigSchema {
+	return provider.ConfigSchema{
+		Fields: []provider.ConfigField{
+			{
+				Name:        "api_key",
+				DisplayName: "API Key",
+				Type:        provider.ConfigFieldTypePassword,
+				Required:    true,
+				Description: "OpenSubtitles API consumer key",
+				Sensitive:   true,
+			},
+			{
+				Name:        "base_url",
+				DisplayName: "Base URL",
+				Type:        provider.ConfigFieldTypeString,
+				Required:    false,
+				Default:     DefaultURL,
+				Description: "Hash lookup API address; point it at a local stand-in for testing",
+			},
+		},
+	}
+}
+
+// Configure applies configuration to the provider.
+func (p *Provider) Configure(config map[string]interface{}) error {
+	apiKey, _ := config["api_key"].(string)
+	if strings.TrimSpace(apiKey) == "" {
+		return fmt.Errorf("api_key is required")
+	}
+
+	baseURL := DefaultURL
+	if raw, ok := config["base_url"]; ok {
+		value, isString := raw.(string)
+		if !isString {
+			return fmt.Errorf("base_url must be a string")
+		}
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			baseURL = trimmed
+		}
+	}
+
+	if p.httpClient == nil {
+		p.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	p.apiKey = strings.TrimSpace(apiKey)
+	p.baseURL = strings.TrimRight(baseURL, "/")
+	return nil
+}
+
+// Fetch hashes the file in Extra["path"] and looks the hash up.
+func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if request.MediaType != provider.MediaTypeMovie && request.MediaType != provider.MediaTypeEpisode {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "UNSUPPORTED_MEDIA_TYPE",
+			Message:  fmt.Sprintf("opensubtitles does not handle media type %s", request.MediaType),
+			Retry:    false,
+		}
+	}
+
+	path, _ := request.Extra[filePathKey].(string)
+	if path == "" {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "MISSING_PATH",
+			Message:  "opensubtitles requires a non-empty file path",
+			Retry:    false,
+		}
+	}
+	hash, err := Hash(path)
+	if err != nil {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "INVALID_INPUT",
+			Message:  fmt.Sprintf("hash %s: %v", path, err),
+			Retry:    false,
+		}
+	}
+
+	if p.cache != nil {
+		get := p.cache.Get
+		if p.offline {
+			get = p.cache.GetStale
+		}
+		if meta, ok := get(providerName, hash); ok {
+			return meta, nil
+		}
+	}
+	if p.offline {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     provider.CacheMissCode,
+			Message:  "no cached response while offline",
+		}
+	}
+
+	meta, err := p.lookup(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if p.cache != nil {
+		p.cache.Set(providerName, hash, meta)
+	}
+	return meta, nil
+}
+
+// mapError maps HTTP errors from the hash API to provider errors by their
+// status code.
+func (p *Provider) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if provErr := provider.MapStatusError(providerName, "OpenSubtitles", err); provErr != nil {
+		return provErr
+	}
+	return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: "OpenSubtitles error: " + err.Error(), Retry: false}
+}
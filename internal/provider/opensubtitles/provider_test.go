@@ -0,0 +1,245 @@
+package opensubtitles
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+const heatHash = "0000000000020003"
+
+const heatResponse = `{"data": [
+	{"attributes": {"moviehash_match": false, "feature_details": {"feature_id": 7, "feature_type": "Movie", "title": "Heat Wave", "year": 2011}}},
+	{"attributes": {"moviehash_match": true, "feature_details": {"feature_id": 1, "feature_type": "Movie", "title": "Heat", "year": 1995, "imdb_id": 113277, "tmdb_id": 949}}},
+	{"attributes": {"moviehash_match": true, "feature_details": {"feature_id": 1, "feature_type": "Movie", "title": "Heat", "year": 1995, "imdb_id": 113277, "tmdb_id": 949}}}
+]}`
+
+const episodeResponse = `{"data": [
+	{"attributes": {"moviehash_match": true, "feature_details": {"feature_id": 9, "feature_type": "Episode", "title": "Pilot", "year": 2008, "season_number": 1, "episode_number": 1, "parent_title": "Breaking Bad", "parent_imdb_id": 903747, "parent_tmdb_id": 1396}}}
+]}`
+
+// writeVideo writes a two chunk file whose first and last words are 1 and 2,
+// so its hash is its size plus 3.
+func writeVideo(t *testing.T, name string) string {
+	t.Helper()
+	data := make([]byte, 2*hashChunkSize)
+	binary.LittleEndian.PutUint64(data, 1)
+	binary.LittleEndian.PutUint64(data[hashChunkSize:], 2)
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+// newStandIn starts a local server answering hash lookups with the response
+// for each hash and returns a provider pointed at it and a request counter.
+func newStandIn(t *testing.T, responses map[string]string) (*Provider, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.URL.Query().Get("moviehash")]
+		if r.URL.Path != "/api/v1/subtitles" || !ok {
+			body = `{"data": []}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"api_key": "secret", "base_url": srv.URL + "/api/v1/"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	return prov, &requests
+}
+
+func fetchFile(p *Provider, mediaType provider.MediaType, path string) (*provider.Metadata, error) {
+	return p.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: mediaType,
+		Name:      filepath.Base(path),
+		Extra:     map[string]interface{}{"path": path},
+	})
+}
+
+func TestHash(t *testing.T) {
+	path := writeVideo(t, "video.mkv")
+	got, err := Hash(path)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if got != heatHash {
+		t.Errorf("Hash() = %q, want %q", got, heatHash)
+	}
+
+	small := filepath.Join(t.TempDir(), "small.mkv")
+	if err := os.WriteFile(small, make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Hash(small); err == nil {
+		t.Errorf("Hash(small) succeeded, want error")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{}); err == nil {
+		t.Errorf("Configure() without api_key succeeded, want error")
+	}
+	if err := prov.Configure(map[string]interface{}{"api_key": "secret"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	if prov.baseURL != DefaultURL {
+		t.Errorf("baseURL = %q, want %q", prov.baseURL, DefaultURL)
+	}
+	if err := prov.Configure(map[string]interface{}{"api_key": "secret", "base_url": 42}); err == nil {
+		t.Errorf("Configure(base_url: 42) succeeded, want error")
+	}
+}
+
+func TestFetchMovie(t *testing.T) {
+	prov, _ := newStandIn(t, map[string]string{heatHash: heatResponse})
+
+	meta, err := fetchFile(prov, provider.MediaTypeMovie, writeVideo(t, "DSC0001.mp4"))
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	want := provider.CoreMetadata{Title: "Heat", Year: "1995", MediaType: provider.MediaTypeMovie}
+	if diff := cmp.Diff(want, meta.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"imdb_id": "tt0113277", "tmdb_id": "949"}, meta.IDs); diff != "" {
+		t.Errorf("IDs mismatch (-want +got):\n%s", diff)
+	}
+	if got := meta.Extended["movie_hash"]; got != heatHash {
+		t.Errorf("movie_hash = %v, want %s", got, heatHash)
+	}
+	if got := meta.Sources["title"]; got != providerName {
+		t.Errorf("Sources[title] = %q, want %q", got, providerName)
+	}
+}
+
+func TestFetchEpisode(t *testing.T) {
+	prov, _ := newStandIn(t, map[string]string{heatHash: episodeResponse})
+
+	meta, err := fetchFile(prov, provider.MediaTypeEpisode, writeVideo(t, "video_final_2.mkv"))
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	want := provider.CoreMetadata{
+		Title:       "Breaking Bad",
+		Year:        "2008",
+		MediaType:   provider.MediaTypeEpisode,
+		SeasonNum:   1,
+		EpisodeNum:  1,
+		EpisodeName: "Pilot",
+	}
+	if diff := cmp.Diff(want, meta.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"imdb_id": "tt0903747", "tmdb_id": "1396"}, meta.IDs); diff != "" {
+		t.Errorf("IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	prov, _ := newStandIn(t, nil)
+	path := writeVideo(t, "video.mkv")
+
+	_, err := fetchFile(prov, provider.MediaTypeMovie, path)
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "NOT_FOUND" {
+		t.Errorf("Fetch(unknown hash) error = %v, want NOT_FOUND", err)
+	}
+
+	prov.apiKey = "wrong"
+	_, err = fetchFile(prov, provider.MediaTypeMovie, path)
+	if !errors.As(err, &provErr) || provErr.Code != "AUTH_FAILED" {
+		t.Errorf("Fetch(bad key) error = %v, want AUTH_FAILED", err)
+	}
+
+	_, err = fetchFile(prov, provider.MediaTypeShow, path)
+	if !errors.As(err, &provErr) || provErr.Code != "UNSUPPORTED_MEDIA_TYPE" {
+		t.Errorf("Fetch(show) error = %v, want UNSUPPORTED_MEDIA_TYPE", err)
+	}
+}
+
+func TestFetchCachesByHash(t *testing.T) {
+	prov, requests := newStandIn(t, map[string]string{heatHash: heatResponse})
+	cache, err := provider.OpenDiskCache(filepath.Join(t.TempDir(), "metadata.json"))
+	if err != nil {
+		t.Fatalf("OpenDiskCache() error = %v", err)
+	}
+	prov.SetCache(cache)
+
+	// The same content under another name is the same hash
+	for _, name := range []string{"DSC0001.mp4", "heat.mp4"} {
+		meta, err := fetchFile(prov, provider.MediaTypeMovie, writeVideo(t, name))
+		if err != nil {
+			t.Fatalf("Fetch(%s) unexpected error: %v", name, err)
+		}
+		if meta.Core.Title != "Heat" {
+			t.Errorf("Fetch(%s) title = %q, want Heat", name, meta.Core.Title)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+
+	// Offline, cached hashes still resolve and others are cache misses
+	prov.SetOffline(true)
+	if _, err := fetchFile(prov, provider.MediaTypeMovie, writeVideo(t, "again.mp4")); err != nil {
+		t.Errorf("offline Fetch(cached) error = %v", err)
+	}
+	other := filepath.Join(t.TempDir(), "other.mp4")
+	if err := os.WriteFile(other, make([]byte, hashChunkSize), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := fetchFile(prov, provider.MediaTypeMovie, other); !provider.IsCacheMiss(err) {
+		t.Errorf("offline Fetch(uncached) error = %v, want cache miss", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests after offline fetches = %d, want 1", got)
+	}
+}
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unauthorized", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusUnauthorized}, "AUTH_FAILED"},
+		{"forbidden", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusForbidden}, "AUTH_FAILED"},
+		{"too_many_requests", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusTooManyRequests}, "RATE_LIMITED"},
+		{"unavailable", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusServiceUnavailable}, "UNAVAILABLE"},
+		{"bad_gateway", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusBadGateway}, "UNAVAILABLE"},
+		{"server_error", &provider.StatusError{Message: "GET /subtitles", Status: http.StatusInternalServerError}, "UNKNOWN"},
+		// Hashes and URLs can hold digits that look like status codes
+		{"network_error", errors.New(`Get "https://api.opensubtitles.com/api/v1/subtitles?moviehash=8e245d9679d31e12": dial tcp: 429 connection refused`), "UNKNOWN"},
+	}
+
+	prov := New()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var provErr *provider.ProviderError
+			if err := prov.mapError(tc.err); !errors.As(err, &provErr) || provErr.Code != tc.want {
+				t.Errorf("mapError(%v) = %v, want %s", tc.err, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// DefaultDir returns the directory plugins are loaded from.
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".title-tidy", "plugins"), nil
+}
+
+// LoadDir registers every executable in dir with reg under the name the
+// plugin reports. Plugins stay disabled until configured. A missing dir is
+// not an error; a plugin that fails to load is skipped and its error
+// returned alongside the others.
+func LoadDir(ctx context.Context, dir string, reg *provider.Registry) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var (
+		loaded []string
+		errs   []error
+	)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") || !isExecutable(path) {
+			continue
+		}
+
+		prov, err := New(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := reg.Register(prov.Name(), prov, prov.Capabilities().Priority); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", entry.Name(), err))
+			continue
+		}
+		loaded = append(loaded, prov.Name())
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// isExecutable reports whether path is a file the plugin loader should run.
+// Symlinks are followed so plugins can be linked into the directory.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+// Methods sent to a plugin executable. Each call starts the executable, writes
+// one request to stdin, and reads one response from stdout.
+const (
+	methodDescribe  = "describe"
+	methodConfigure = "configure"
+	methodFetch     = "fetch"
+)
+
+// request is the JSON object written to a plugin's stdin. Config carries the
+// provider settings on every call, so plugins can stay stateless.
+type request struct {
+	Method  string                 `json:"method"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+	Request *fetchRequest          `json:"request,omitempty"`
+}
+
+// response is the JSON object a plugin writes to stdout. A fetch with a null
+// result means nothing matched.
+type response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *errorPayload   `json:"error,omitempty"`
+}
+
+type errorPayload struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Retry      bool   `json:"retry,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// description answers the describe method and mirrors the identification and
+// discovery half of provider.Provider.
+type description struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	Capabilities capabilities  `json:"capabilities"`
+	Variables    []variable    `json:"variables"`
+	ConfigSchema []configField `json:"config_schema"`
+}
+
+type capabilities struct {
+	MediaTypes   []provider.MediaType `json:"media_types"`
+	RequiresAuth bool                 `json:"requires_auth"`
+	RequiresFile bool                 `json:"requires_file"`
+	Priority     int                  `json:"priority"`
+}
+
+type variable struct {
+	Name        string               `json:"name"`
+	DisplayName string               `json:"display_name"`
+	Description string               `json:"description"`
+	MediaTypes  []provider.MediaType `json:"media_types"`
+	Example     string               `json:"example"`
+	Category    string               `json:"category"`
+	Format      string               `json:"format"`
+}
+
+type configField struct {
+	Name        string                   `json:"name"`
+	DisplayName string                   `json:"display_name"`
+	Type        provider.ConfigFieldType `json:"type"`
+	Required    bool                     `json:"required"`
+	Default     interface{}              `json:"default"`
+	Description string                   `json:"description"`
+	Sensitive   bool                     `json:"sensitive"`
+}
+
+type fetchRequest struct {
+	MediaType provider.MediaType     `json:"media_type"`
+	Name      string                 `json:"name"`
+	Year      string                 `json:"year,omitempty"`
+	Season    int                    `json:"season,omitempty"`
+	Episode   int                    `json:"episode,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	Language  string                 `json:"language,omitempty"`
+	Extra     map[string]interface{} `json:"extra,omitempty"`
+}
+
+type metadata struct {
+	Core       coreMetadata           `json:"core"`
+	Extended   map[string]interface{} `json:"extended"`
+	Sources    map[string]string      `json:"sources"`
+	IDs        map[string]string      `json:"ids"`
+	Confidence float64                `json:"confidence"`
+}
+
+type coreMetadata struct {
+	Title        string             `json:"title"`
+	Year         string             `json:"year"`
+	MediaType    provider.MediaType `json:"media_type"`
+	Season       int                `json:"season"`
+	EpisodeTitle string             `json:"episode_title"`
+	Episode      int                `json:"episode"`
+	Overview     string             `json:"overview"`
+	Rating       float32            `json:"rating"`
+	Genres       []string           `json:"genres"`
+	Language     string             `json:"language"`
+	Country      string             `json:"country"`
+}
+
+func newFetchRequest(req provider.FetchRequest) *fetchRequest {
+	return &fetchRequest{
+		MediaType: req.MediaType,
+		Name:      req.Name,
+		Year:      req.Year,
+		Season:    req.Season,
+		Episode:   req.Episode,
+		ID:        req.ID,
+		Language:  req.Language,
+		Extra:     req.Extra,
+	}
+}
+
+func (m *metadata) toProvider() *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:       m.Core.Title,
+			Year:        m.Core.Year,
+			MediaType:   m.Core.MediaType,
+			SeasonNum:   m.Core.Season,
+			EpisodeName: m.Core.EpisodeTitle,
+			EpisodeNum:  m.Core.Episode,
+			Overview:    m.Core.Overview,
+			Rating:      m.Core.Rating,
+			Genres:      m.Core.Genres,
+			Language:    m.Core.Language,
+			Country:     m.Core.Country,
+		},
+		Extended:   m.Extended,
+		Sources:    m.Sources,
+		IDs:        m.IDs,
+		Confidence: m.Confidence,
+	}
+	if meta.Extended == nil {
+		meta.Extended = make(map[string]interface{})
+	}
+	if meta.Sources == nil {
+		meta.Sources = make(map[string]string)
+	}
+	if meta.IDs == nil {
+		meta.IDs = make(map[string]string)
+	}
+	return meta
+}
@@ -0,0 +1,247 @@
+// Package plugin runs metadata providers shipped as external executables.
+// A plugin speaks a small JSON protocol over stdin and stdout that mirrors
+// provider.Provider, so sources that will never be built in can still feed
+// template variables.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+const (
+	// defaultPriority places plugins between the search providers and
+	// ffprobe when they don't ask for a priority of their own.
+	defaultPriority = 60
+
+	describeTimeout = 10 * time.Second
+	callTimeout     = 30 * time.Second
+)
+
+// Provider implements the provider.Provider interface by running a plugin
+// executable once per call.
+type Provider struct {
+	path    string
+	args    []string
+	desc    description
+	config  map[string]interface{}
+	timeout time.Duration
+}
+
+// New starts the executable at path to describe itself and returns the
+// resulting provider.
+func New(ctx context.Context, path string) (*Provider, error) {
+	return newProvider(ctx, path)
+}
+
+func newProvider(ctx context.Context, path string, args ...string) (*Provider, error) {
+	p := &Provider{
+		path:    path,
+		args:    args,
+		config:  make(map[string]interface{}),
+		timeout: callTimeout,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	if err := p.call(ctx, request{Method: methodDescribe}, &p.desc); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+	if p.desc.Name == "" {
+		p.desc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := provider.ValidateCapabilities(p.Capabilities()); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.desc.Name, err)
+	}
+	return p, nil
+}
+
+// Name returns the name the plugin reported, or its file name.
+func (p *Provider) Name() string {
+	return p.desc.Name
+}
+
+// Description returns the plugin's description.
+func (p *Provider) Description() string {
+	if p.desc.Description == "" {
+		return "External plugin " + p.path
+	}
+	return p.desc.Description
+}
+
+// Capabilities returns what the plugin reported it can do.
+func (p *Provider) Capabilities() provider.ProviderCapabilities {
+	caps := provider.ProviderCapabilities{
+		MediaTypes:   p.desc.Capabilities.MediaTypes,
+		RequiresAuth: p.desc.Capabilities.RequiresAuth,
+		RequiresFile: p.desc.Capabilities.RequiresFile,
+		Priority:     p.desc.Capabilities.Priority,
+	}
+	if caps.Priority == 0 {
+		caps.Priority = defaultPriority
+	}
+	return caps
+}
+
+// SupportedVariables returns the template variables the plugin supplies.
+func (p *Provider) SupportedVariables() []provider.TemplateVariable {
+	vars := make([]provider.TemplateVariable, 0, len(p.desc.Variables))
+	for _, v := range p.desc.Variables {
+		vars = append(vars, provider.TemplateVariable{
+			Name:        v.Name,
+			DisplayName: v.DisplayName,
+			Description: v.Description,
+			MediaTypes:  v.MediaTypes,
+			Example:     v.Example,
+			Provider:    p.desc.Name,
+			Category:    v.Category,
+			Format:      v.Format,
+		})
+	}
+	return vars
+}
+
+// ConfigSchema returns the configuration fields the plugin reported.
+func (p *Provider) ConfigSchema() provider.ConfigSchema {
+	schema := provider.ConfigSchema{Fields: make([]provider.ConfigField, 0, len(p.desc.ConfigSchema))}
+	for _, field := range p.desc.ConfigSchema {
+		schema.Fields = append(schema.Fields, provider.ConfigField{
+			Name:        field.Name,
+			DisplayName: field.DisplayName,
+			Type:        field.Type,
+			Required:    field.Required,
+			Default:     field.Default,
+			Description: field.Description,
+			Sensitive:   field.Sensitive,
+		})
+	}
+	return schema
+}
+
+// Configure checks required fields, lets the plugin validate the settings,
+// and keeps them for later calls.
+func (p *Provider) Configure(config map[string]interface{}) error {
+	for _, field := range p.desc.ConfigSchema {
+		if !field.Required {
+			continue
+		}
+		if value, ok := config[field.Name]; !ok || value == nil || value == "" {
+			return fmt.Errorf("%s is required", field.Name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	if err := p.call(ctx, request{Method: methodConfigure, Config: config}, nil); err != nil {
+		return err
+	}
+	p.config = config
+	return nil
+}
+
+// Fetch asks the plugin for metadata. A null result is reported as NOT_FOUND.
+func (p *Provider) Fetch(ctx context.Context, req provider.FetchRequest) (*provider.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var result *metadata
+	if err := p.call(ctx, request{Method: methodFetch, Config: p.config, Request: newFetchRequest(req)}, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, &provider.ProviderError{
+			Provider: p.desc.Name,
+			Code:     "NOT_FOUND",
+			Message:  fmt.Sprintf("%s returned no match for %q", p.desc.Name, req.Name),
+			Retry:    false,
+		}
+	}
+	return result.toProvider(), nil
+}
+
+// call runs the plugin with req on stdin and decodes the result into out.
+// Errors reported by the plugin become provider errors.
+func (p *Provider) call(ctx context.Context, req request, out interface{}) error {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", req.Method, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path, p.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return &provider.ProviderError{
+				Provider: p.desc.Name,
+				Code:     "UNAVAILABLE",
+				Message:  fmt.Sprintf("plugin timed out during %s", req.Method),
+				Retry:    true,
+			}
+		}
+		return ctxErr
+	}
+
+	var resp response
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &resp); decodeErr != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" && runErr != nil {
+			message = runErr.Error()
+		}
+		if message == "" {
+			message = decodeErr.Error()
+		}
+		return &provider.ProviderError{
+			Provider: p.desc.Name,
+			Code:     "PARSE_ERROR",
+			Message:  fmt.Sprintf("invalid %s response: %s", req.Method, message),
+			Retry:    false,
+		}
+	}
+
+	if resp.Error != nil {
+		code := resp.Error.Code
+		if code == "" {
+			code = "UNKNOWN"
+		}
+		return &provider.ProviderError{
+			Provider:   p.desc.Name,
+			Code:       code,
+			Message:    resp.Error.Message,
+			Retry:      resp.Error.Retry,
+			RetryAfter: resp.Error.RetryAfter,
+		}
+	}
+
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return &provider.ProviderError{
+			Provider: p.desc.Name,
+			Code:     "PARSE_ERROR",
+			Message:  fmt.Sprintf("invalid %s result: %v", req.Method, err),
+			Retry:    false,
+		}
+	}
+	return nil
+}
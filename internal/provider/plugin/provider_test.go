@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+const helperEnv = "TITLE_TIDY_TEST_PLUGIN"
+
+// TestHelperPlugin is not a real test. When helperEnv is set the test binary
+// acts as a plugin executable for the tests below.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(helperEnv) != "1" {
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var resp interface{}
+	switch req.Method {
+	case methodDescribe:
+		resp = map[string]interface{}{"result": map[string]interface{}{
+			"name":         "studio",
+			"description":  "Studio catalogue",
+			"capabilities": map[string]interface{}{"media_types": []string{"movie"}, "requires_auth": true},
+			"variables": []map[string]interface{}{{
+				"name": "studio_code", "display_name": "Studio Code", "description": "Catalogue code",
+				"media_types": []string{"movie"}, "example": "WB-0042",
+			}},
+			"config_schema": []map[string]interface{}{{"name": "api_key", "type": "password", "required": true, "sensitive": true}},
+		}}
+	case methodConfigure:
+		if req.Config["api_key"] == "bad" {
+			resp = map[string]interface{}{"error": map[string]interface{}{"code": "AUTH_FAILED", "message": "bad key"}}
+		} else {
+			resp = map[string]interface{}{}
+		}
+	case methodFetch:
+		if req.Request.Name == "Missing" {
+			resp = map[string]interface{}{"result": nil}
+			break
+		}
+		resp = map[string]interface{}{"result": map[string]interface{}{
+			"core":       map[string]interface{}{"title": req.Request.Name, "year": "1999", "media_type": req.Request.MediaType},
+			"extended":   map[string]interface{}{"studio_code": "WB-0042", "key_seen": req.Config["api_key"]},
+			"ids":        map[string]string{"studio_id": "42"},
+			"confidence": 1.0,
+		}}
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+	os.Exit(0)
+}
+
+func newHelperProvider(t *testing.T) *Provider {
+	t.Helper()
+	t.Setenv(helperEnv, "1")
+
+	prov, err := newProvider(context.Background(), os.Args[0], "-test.run=^TestHelperPlugin$")
+	if err != nil {
+		t.Fatalf("newProvider() unexpected error: %v", err)
+	}
+	return prov
+}
+
+func TestProviderDescribe(t *testing.T) {
+	prov := newHelperProvider(t)
+
+	if prov.Name() != "studio" {
+		t.Errorf("Name() = %q, want studio", prov.Name())
+	}
+	wantCaps := provider.ProviderCapabilities{
+		MediaTypes:   []provider.MediaType{provider.MediaTypeMovie},
+		RequiresAuth: true,
+		Priority:     defaultPriority,
+	}
+	if diff := cmp.Diff(wantCaps, prov.Capabilities()); diff != "" {
+		t.Errorf("Capabilities() mismatch (-want +got):\n%s", diff)
+	}
+	wantVars := []provider.TemplateVariable{{
+		Name:        "studio_code",
+		DisplayName: "Studio Code",
+		Description: "Catalogue code",
+		MediaTypes:  []provider.MediaType{provider.MediaTypeMovie},
+		Example:     "WB-0042",
+		Provider:    "studio",
+	}}
+	if diff := cmp.Diff(wantVars, prov.SupportedVariables()); diff != "" {
+		t.Errorf("SupportedVariables() mismatch (-want +got):\n%s", diff)
+	}
+	if fields := prov.ConfigSchema().Fields; len(fields) != 1 || !fields[0].Sensitive || fields[0].Type != provider.ConfigFieldTypePassword {
+		t.Errorf("ConfigSchema().Fields = %+v, want one sensitive password field", fields)
+	}
+}
+
+func TestProviderConfigureAndFetch(t *testing.T) {
+	prov := newHelperProvider(t)
+
+	if err := prov.Configure(map[string]interface{}{}); err == nil {
+		t.Errorf("Configure() without api_key succeeded, want error")
+	}
+	var provErr *provider.ProviderError
+	if err := prov.Configure(map[string]interface{}{"api_key": "bad"}); !errors.As(err, &provErr) || provErr.Code != "AUTH_FAILED" {
+		t.Errorf("Configure(bad) error = %v, want AUTH_FAILED", err)
+	}
+	if err := prov.Configure(map[string]interface{}{"api_key": "secret"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "The Matrix"})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	want := &provider.Metadata{
+		Core:       provider.CoreMetadata{Title: "The Matrix", Year: "1999", MediaType: provider.MediaTypeMovie},
+		Extended:   map[string]interface{}{"studio_code": "WB-0042", "key_seen": "secret"},
+		Sources:    map[string]string{},
+		IDs:        map[string]string{"studio_id": "42"},
+		Confidence: 1,
+	}
+	if diff := cmp.Diff(want, meta); diff != "" {
+		t.Errorf("Fetch() mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Missing"})
+	if !errors.As(err, &provErr) || provErr.Code != "NOT_FOUND" || provErr.Provider != "studio" {
+		t.Errorf("Fetch(Missing) error = %v, want studio NOT_FOUND", err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	t.Setenv(helperEnv, "1")
+
+	dir := t.TempDir()
+	scripts := map[string]struct {
+		body string
+		mode os.FileMode
+	}{
+		"studio":  {fmt.Sprintf("#!/bin/sh\nexec %q -test.run='^TestHelperPlugin$'\n", os.Args[0]), 0755},
+		"broken":  {"#!/bin/sh\necho not json\n", 0755},
+		"notes":   {"not a plugin\n", 0644},
+		".hidden": {"#!/bin/sh\nexit 1\n", 0755},
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script.body), script.mode); err != nil {
+			t.Fatalf("WriteFile(%s) unexpected error: %v", name, err)
+		}
+	}
+
+	reg := provider.NewRegistry()
+	loaded, err := LoadDir(context.Background(), dir, reg)
+	if err == nil {
+		t.Errorf("LoadDir() error = nil, want the broken plugin reported")
+	}
+	if diff := cmp.Diff([]string{"studio"}, loaded); diff != "" {
+		t.Errorf("LoadDir() loaded mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"studio"}, reg.List()); diff != "" {
+		t.Errorf("registry mismatch (-want +got):\n%s", diff)
+	}
+	if enabled := reg.Enabled(); len(enabled) != 0 {
+		t.Errorf("Enabled() = %v, want plugins disabled until configured", enabled)
+	}
+
+	if loaded, err := LoadDir(context.Background(), filepath.Join(dir, "missing"), reg); err != nil || loaded != nil {
+		t.Errorf("LoadDir(missing) = (%v, %v), want (nil, nil)", loaded, err)
+	}
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter implements a simple sliding window rate limiter
+type RateLimiter struct {
+	mu          sync.Mutex
+	requests    []time.Time
+	maxRequests int
+	window      time.Duration
+}
+
+// NewRateLimiter creates a rate limiter allowing maxRequests per window
+func NewRateLimiter(maxRequests int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		maxRequests: maxRequests,
+		window:      window,
+		requests:    make([]time.Time, 0, maxRequests),
+	}
+}
+
+// Wait blocks until a request can be made within rate limits. It only
+// returns an error when ctx is done before a slot frees up.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		r.prune(now)
+
+		// If we're under the limit, allow the request immediately
+		if len(r.requests) < r.maxRequests {
+			r.requests = append(r.requests, now)
+			r.mu.Unlock()
+			return nil
+		}
+
+		// We need to wait. Calculate when the oldest request will expire,
+		// plus a small buffer to ensure it actually has
+		waitTime := r.window - now.Sub(r.requests[0]) + 10*time.Millisecond
+		r.mu.Unlock()
+
+		timer := time.NewTimer(waitTime)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// prune drops requests that have left the window. Callers hold r.mu.
+func (r *RateLimiter) prune(now time.Time) {
+	cutoff := now.Add(-r.window)
+	valid := r.requests[:0]
+	for _, req := range r.requests {
+		if req.After(cutoff) {
+			valid = append(valid, req)
+		}
+	}
+	r.requests = valid
+}
@@ -1,6 +1,7 @@
-package tmdb
+package provider
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -8,13 +9,13 @@ import (
 
 func TestRateLimiter(t *testing.T) {
 	t.Run("AllowsRequestsWithinLimit", func(t *testing.T) {
-		rl := newRateLimiter(5, 1*time.Second)
+		rl := NewRateLimiter(5, 1*time.Second)
 
 		// Should allow 5 requests immediately
 		start := time.Now()
 		for i := 0; i < 5; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() request %d error = %v, want nil", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() request %d error = %v, want nil", i+1, err)
 			}
 		}
 		elapsed := time.Since(start)
@@ -26,19 +27,19 @@ func TestRateLimiter(t *testing.T) {
 	})
 
 	t.Run("BlocksExcessRequests", func(t *testing.T) {
-		rl := newRateLimiter(2, 500*time.Millisecond)
+		rl := NewRateLimiter(2, 500*time.Millisecond)
 
 		// First 2 requests should be immediate
 		start := time.Now()
 		for i := 0; i < 2; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() request %d error = %v, want nil", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() request %d error = %v, want nil", i+1, err)
 			}
 		}
 
 		// 3rd request should be delayed until window allows it
-		if err := rl.wait(); err != nil {
-			t.Errorf("wait() request 3 error = %v, want nil", err)
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Errorf("Wait() request 3 error = %v, want nil", err)
 		}
 
 		elapsed := time.Since(start)
@@ -49,12 +50,12 @@ func TestRateLimiter(t *testing.T) {
 	})
 
 	t.Run("CleansUpOldRequests", func(t *testing.T) {
-		rl := newRateLimiter(3, 200*time.Millisecond)
+		rl := NewRateLimiter(3, 200*time.Millisecond)
 
 		// Make 3 requests to fill the limit
 		for i := 0; i < 3; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() initial request %d error = %v", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() initial request %d error = %v", i+1, err)
 			}
 		}
 
@@ -64,8 +65,8 @@ func TestRateLimiter(t *testing.T) {
 		// Should be able to make 3 more requests quickly
 		start := time.Now()
 		for i := 0; i < 3; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() after window request %d error = %v", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() after window request %d error = %v", i+1, err)
 			}
 		}
 
@@ -77,7 +78,7 @@ func TestRateLimiter(t *testing.T) {
 	})
 
 	t.Run("ConcurrentRequests", func(t *testing.T) {
-		rl := newRateLimiter(10, 1*time.Second)
+		rl := NewRateLimiter(10, 1*time.Second)
 
 		var wg sync.WaitGroup
 		successCount := 0
@@ -88,7 +89,7 @@ func TestRateLimiter(t *testing.T) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				if err := rl.wait(); err == nil {
+				if err := rl.Wait(context.Background()); err == nil {
 					mu.Lock()
 					successCount++
 					mu.Unlock()
@@ -105,35 +106,35 @@ func TestRateLimiter(t *testing.T) {
 	})
 
 	t.Run("NeverReturnsRateLimitError", func(t *testing.T) {
-		rl := newRateLimiter(1, 100*time.Millisecond)
+		rl := NewRateLimiter(1, 100*time.Millisecond)
 
 		// Fill the limit
-		if err := rl.wait(); err != nil {
-			t.Errorf("wait() first request error = %v", err)
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Errorf("Wait() first request error = %v", err)
 		}
 
 		// Additional requests should wait but never return error
 		for i := 0; i < 5; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() request %d returned error %v, should never return error", i+2, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() request %d returned error %v, should never return error", i+2, err)
 			}
 		}
 	})
 
 	t.Run("RespectsSlidingWindow", func(t *testing.T) {
-		rl := newRateLimiter(3, 300*time.Millisecond)
+		rl := NewRateLimiter(3, 300*time.Millisecond)
 
 		// Make 3 requests quickly
 		start := time.Now()
 		for i := 0; i < 3; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() request %d error = %v", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() request %d error = %v", i+1, err)
 			}
 		}
 
 		// 4th request should wait for window
-		if err := rl.wait(); err != nil {
-			t.Errorf("wait() 4th request error = %v", err)
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Errorf("Wait() 4th request error = %v", err)
 		}
 
 		elapsed := time.Since(start)
@@ -149,15 +150,15 @@ func TestRateLimiter(t *testing.T) {
 	})
 
 	t.Run("TMDBRealWorldScenario", func(t *testing.T) {
-		// Test with TMDB-like limits: 38 requests per 10 seconds
-		rl := newRateLimiter(38, 10*time.Second)
+		// Test with TMDB's default limits: 38 requests per 10 seconds
+		rl := NewRateLimiter(38, 10*time.Second)
 
 		start := time.Now()
 
 		// Make 38 requests - should all go through quickly
 		for i := 0; i < 38; i++ {
-			if err := rl.wait(); err != nil {
-				t.Errorf("wait() request %d error = %v", i+1, err)
+			if err := rl.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() request %d error = %v", i+1, err)
 			}
 		}
 
@@ -169,8 +170,8 @@ func TestRateLimiter(t *testing.T) {
 
 		// 39th request should wait for window
 		start = time.Now()
-		if err := rl.wait(); err != nil {
-			t.Errorf("wait() 39th request error = %v", err)
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Errorf("Wait() 39th request error = %v", err)
 		}
 		waitTime := time.Since(start)
 
@@ -179,4 +180,21 @@ func TestRateLimiter(t *testing.T) {
 			t.Errorf("39th request waited %v, expected ~10s", waitTime)
 		}
 	})
+
+	t.Run("StopsWaitingWhenContextDone", func(t *testing.T) {
+		rl := NewRateLimiter(1, time.Minute)
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() first request error = %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := rl.Wait(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Wait() returned after %v, want it to stop with the context", elapsed)
+		}
+	})
 }
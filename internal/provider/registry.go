@@ -117,3 +117,26 @@ func (r *Registry) Configure(name string, config map[string]interface{}) error {
 
 	return nil
 }
+
+// Enabled returns the names of enabled providers, highest priority first.
+// Providers sharing a priority are ordered by name.
+func (r *Registry) Enabled() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		if r.enabledStatus[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if r.priorities[names[i]] != r.priorities[names[j]] {
+			return r.priorities[names[i]] > r.priorities[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	return names
+}
@@ -159,6 +159,26 @@ func TestRegistry_Enable(t *testing.T) {
 	}
 }
 
+func TestRegistry_Enabled(t *testing.T) {
+	registry := NewRegistry()
+
+	caps := ProviderCapabilities{MediaTypes: []MediaType{MediaTypeMovie}}
+	registry.Register("omdb", &MockProvider{name: "omdb", capabilities: caps}, 90)
+	registry.Register("tmdb", &MockProvider{name: "tmdb", capabilities: caps}, 100)
+	registry.Register("extra", &MockProvider{name: "extra", capabilities: caps}, 90)
+	registry.Register("disabled", &MockProvider{name: "disabled", capabilities: caps}, 200)
+
+	for _, name := range []string{"omdb", "tmdb", "extra"} {
+		if err := registry.Enable(name); err != nil {
+			t.Fatalf("Enable(%s) error = %v", name, err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"tmdb", "extra", "omdb"}, registry.Enabled()); diff != "" {
+		t.Errorf("Enabled() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestRegistry_Configure(t *testing.T) {
 	registry := NewRegistry()
 
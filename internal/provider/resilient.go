@@ -0,0 +1,383 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy controls how a Resilient provider paces and retries its calls.
+type Policy struct {
+	Requests   int           // Calls, or requests for a RequestLimiter, allowed per Window; 0 disables rate limiting
+	Window     time.Duration // Length of the rate limit window
+	MaxRetries int           // Extra attempts for errors marked Retry
+	BaseDelay  time.Duration // First backoff delay, doubled on each retry
+	MaxDelay   time.Duration // Upper bound on any single wait between attempts
+}
+
+// DefaultPolicy retries twice with backoff starting at half a second and
+// applies no rate limit.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: 2,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// CacheMissCode is the ProviderError code returned for requests an offline
+// provider has no cached response for.
+const CacheMissCode = "CACHE_MISS"
+
+// OfflineFetcher is implemented by providers that can be limited to
+// answering from cached responses.
+type OfflineFetcher interface {
+	SetOffline(offline bool)
+}
+
+// IsCacheMiss reports whether err is an offline cache miss.
+func IsCacheMiss(err error) bool {
+	var provErr *ProviderError
+	return errors.As(err, &provErr) && provErr.Code == CacheMissCode
+}
+
+// RequestLimiter is implemented by providers that make several requests for
+// one call. Resilient hands them its rate limiter to wait on before each
+// request, so the policy limits requests rather than calls.
+type RequestLimiter interface {
+	SetRateLimiter(limiter *RateLimiter)
+}
+
+// Resilient wraps a provider so every Fetch and SearchCandidates call waits
+// for the rate limiter and retries errors marked Retry with exponential
+// backoff and jitter. A ProviderError's RetryAfter is honored as the minimum
+// wait before the next attempt. With a cache attached, fetches are answered
+// from it when possible and successful results are stored in it. Offline,
+// the wrapped provider is never called, expired entries are still used, and
+// cache misses are reported with CacheMissCode.
+type Resilient struct {
+	Provider
+
+	mu      sync.RWMutex
+	policy  Policy
+	limiter *RateLimiter // nil when the provider waits on it per request
+	cache   *DiskCache
+	variant string // fingerprint of the configuration, part of every cache key
+	offline bool
+
+	// Overridable for tests
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewResilient wraps p with the given policy.
+func NewResilient(p Provider, policy Policy) *Resilient {
+	r := &Resilient{
+		Provider: p,
+		sleep:    sleepContext,
+		jitter:   equalJitter,
+	}
+	r.SetPolicy(policy)
+	return r
+}
+
+// Unwrap returns the wrapped provider.
+func (r *Resilient) Unwrap() Provider {
+	return r.Provider
+}
+
+// Policy returns the policy currently applied.
+func (r *Resilient) Policy() Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// SetPolicy replaces the policy. The rate limit window starts over.
+func (r *Resilient) SetPolicy(policy Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = policy
+	var limiter *RateLimiter
+	if policy.Requests > 0 && policy.Window > 0 {
+		limiter = NewRateLimiter(policy.Requests, policy.Window)
+	}
+	r.limiter = limiter
+	if limited, ok := r.Provider.(RequestLimiter); ok {
+		limited.SetRateLimiter(limiter)
+		r.limiter = nil
+	}
+}
+
+// SetCache attaches the cache fetches are read from and written to. A nil
+// cache turns caching off.
+func (r *Resilient) SetCache(cache *DiskCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = cache
+}
+
+// SetOffline limits the provider to cached responses.
+func (r *Resilient) SetOffline(offline bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offline = offline
+}
+
+// Configure configures the wrapped provider. Cached results are keyed by the
+// configuration too, so changing a setting such as the language doesn't
+// serve results fetched under the old one.
+func (r *Resilient) Configure(config map[string]interface{}) error {
+	if err := r.Provider.Configure(config); err != nil {
+		return err
+	}
+
+	variant := ""
+	if data, err := json.Marshal(config); err == nil && len(config) > 0 {
+		sum := sha256.Sum256(data)
+		variant = hex.EncodeToString(sum[:4])
+	}
+	r.mu.Lock()
+	r.variant = variant
+	r.mu.Unlock()
+	return nil
+}
+
+// Fetch retrieves metadata from the cache, or from the wrapped provider
+// under the policy.
+func (r *Resilient) Fetch(ctx context.Context, request FetchRequest) (*Metadata, error) {
+	r.mu.RLock()
+	cache, key, offline := r.cache, CacheKey(request), r.offline
+	if r.variant != "" {
+		key += "|" + r.variant
+	}
+	r.mu.RUnlock()
+
+	if cache != nil {
+		get := cache.Get
+		if offline {
+			// Stale data beats none when the provider can't be reached.
+			get = cache.GetStale
+		}
+		if meta, ok := get(r.Name(), key); ok {
+			return meta, nil
+		}
+	}
+	if offline {
+		return nil, &ProviderError{
+			Provider: r.Name(),
+			Code:     CacheMissCode,
+			Message:  "no cached response while offline",
+		}
+	}
+
+	var meta *Metadata
+	err := r.do(ctx, func() error {
+		var err error
+		meta, err = r.Provider.Fetch(ctx, request)
+		return err
+	})
+	if err == nil && cache != nil {
+		cache.Set(r.Name(), key, meta)
+	}
+	return meta, err
+}
+
+// SearchCandidates lists candidates from the wrapped provider under the
+// policy. Providers without candidate search report an error.
+func (r *Resilient) SearchCandidates(ctx context.Context, request FetchRequest, limit int) ([]Candidate, error) {
+	searcher, ok := r.Provider.(CandidateSearcher)
+	if !ok {
+		return nil, fmt.Errorf("%s provider does not support candidate search", r.Name())
+	}
+	r.mu.RLock()
+	offline := r.offline
+	r.mu.RUnlock()
+	if offline {
+		return nil, fmt.Errorf("%s candidate search is unavailable offline", r.Name())
+	}
+
+	var candidates []Candidate
+	err := r.do(ctx, func() error {
+		var err error
+		candidates, err = searcher.SearchCandidates(ctx, request, limit)
+		return err
+	})
+	return candidates, err
+}
+
+// FetchSeasonEpisodes fetches a season of episodes from the cache, or from
+// the wrapped provider under the policy. A batch is cached as an entry
+// listing the season's episode numbers plus one entry per episode, apart
+// from single episode fetches since a season payload may describe episodes
+// only partly. Providers that can't batch seasons report an error.
+func (r *Resilient) FetchSeasonEpisodes(ctx context.Context, request FetchRequest) (map[int]*Metadata, error) {
+	batcher, ok := r.Provider.(SeasonBatcher)
+	if !ok {
+		return nil, fmt.Errorf("%s provider does not support season batches", r.Name())
+	}
+	r.mu.RLock()
+	cache, key, offline := r.cache, CacheKey(request)+"|episodes", r.offline
+	if r.variant != "" {
+		key += "|" + r.variant
+	}
+	r.mu.RUnlock()
+
+	if cache != nil {
+		get := cache.Get
+		if offline {
+			get = cache.GetStale
+		}
+		if episodes, ok := cachedSeasonEpisodes(get, r.Name(), key); ok {
+			return episodes, nil
+		}
+	}
+	if offline {
+		return nil, &ProviderError{
+			Provider: r.Name(),
+			Code:     CacheMissCode,
+			Message:  "no cached season while offline",
+		}
+	}
+
+	var episodes map[int]*Metadata
+	err := r.do(ctx, func() error {
+		var err error
+		episodes, err = batcher.FetchSeasonEpisodes(ctx, request)
+		return err
+	})
+	if err == nil && cache != nil {
+		numbers := make([]string, 0, len(episodes))
+		for num, meta := range episodes {
+			cache.Set(r.Name(), key+"|"+strconv.Itoa(num), meta)
+			numbers = append(numbers, strconv.Itoa(num))
+		}
+		cache.Set(r.Name(), key, &Metadata{
+			Extended: map[string]interface{}{"episodes": strings.Join(numbers, ",")},
+		})
+	}
+	return episodes, err
+}
+
+// SeasonEpisodeVariables forwards to the wrapped provider when it batches
+// seasons.
+func (r *Resilient) SeasonEpisodeVariables() []string {
+	if batcher, ok := r.Provider.(SeasonBatcher); ok {
+		return batcher.SeasonEpisodeVariables()
+	}
+	return nil
+}
+
+// cachedSeasonEpisodes reassembles a season batch from the cache. It misses
+// unless the batch entry and every episode it lists are cached.
+func cachedSeasonEpisodes(get func(providerName, key string) (*Metadata, bool), providerName, key string) (map[int]*Metadata, bool) {
+	batch, ok := get(providerName, key)
+	if !ok {
+		return nil, false
+	}
+	list, _ := batch.Extended["episodes"].(string)
+	episodes := make(map[int]*Metadata)
+	for _, field := range strings.Split(list, ",") {
+		if field == "" {
+			continue
+		}
+		num, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		meta, ok := get(providerName, key+"|"+field)
+		if !ok {
+			return nil, false
+		}
+		episodes[num] = meta
+	}
+	return episodes, true
+}
+
+// ExactID forwards to the wrapped provider when it can report IDs.
+func (r *Resilient) ExactID(meta *Metadata) string {
+	if ider, ok := r.Provider.(ExactIDer); ok {
+		return ider.ExactID(meta)
+	}
+	return ""
+}
+
+// do runs call until it succeeds, fails with an error that shouldn't be
+// retried, or runs out of retries.
+func (r *Resilient) do(ctx context.Context, call func() error) error {
+	r.mu.RLock()
+	policy, limiter := r.policy, r.limiter
+	r.mu.RUnlock()
+
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := call()
+		var provErr *ProviderError
+		if err == nil || attempt >= policy.MaxRetries || !errors.As(err, &provErr) || !provErr.Retry {
+			return err
+		}
+
+		if err := r.sleep(ctx, r.backoff(policy, attempt, provErr)); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns how long to wait after the given failed attempt: a jittered
+// exponential delay, raised to RetryAfter when the provider asked for more,
+// and capped at MaxDelay.
+func (r *Resilient) backoff(policy Policy, attempt int, provErr *ProviderError) time.Duration {
+	delay := policy.BaseDelay << attempt
+	if delay < 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	delay = r.jitter(delay)
+
+	if after := time.Duration(provErr.RetryAfter) * time.Second; after > delay {
+		delay = after
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	return delay
+}
+
+// equalJitter picks a random delay between half of d and d, so workers that
+// failed together don't retry together.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// searchingMockProvider adds candidate search and exact IDs to MockProvider.
+type searchingMockProvider struct {
+	MockProvider
+	candidates []Candidate
+}
+
+func (m *searchingMockProvider) SearchCandidates(ctx context.Context, req FetchRequest, limit int) ([]Candidate, error) {
+	return m.candidates, nil
+}
+
+func (m *searchingMockProvider) ExactID(meta *Metadata) string {
+	return meta.IDs["mock_id"]
+}
+
+// newTestResilient wraps a provider whose Fetch returns errs in order before
+// succeeding, and records the waits between attempts instead of sleeping.
+func newTestResilient(policy Policy, errs ...error) (*Resilient, *int, *[]time.Duration) {
+	calls := 0
+	mock := &MockProvider{name: "mock", fetchFunc: func(ctx context.Context, req FetchRequest) (*Metadata, error) {
+		calls++
+		if calls <= len(errs) {
+			return nil, errs[calls-1]
+		}
+		return &Metadata{Core: CoreMetadata{Title: "Found"}}, nil
+	}}
+
+	var waits []time.Duration
+	r := NewResilient(mock, policy)
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	r.jitter = func(d time.Duration) time.Duration { return d }
+	return r, &calls, &waits
+}
+
+func TestResilientRetriesWithBackoff(t *testing.T) {
+	unavailable := &ProviderError{Provider: "mock", Code: "UNAVAILABLE", Retry: true}
+	r, calls, waits := newTestResilient(Policy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, unavailable, unavailable)
+
+	meta, err := r.Fetch(context.Background(), FetchRequest{Name: "Test"})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Core.Title != "Found" || *calls != 3 {
+		t.Errorf("Fetch() = %q after %d calls, want Found after 3", meta.Core.Title, *calls)
+	}
+	if diff := cmp.Diff([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits); diff != "" {
+		t.Errorf("backoff waits mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResilientHonorsRetryAfter(t *testing.T) {
+	limited := &ProviderError{Provider: "mock", Code: "RATE_LIMITED", Retry: true, RetryAfter: 5}
+	r, _, waits := newTestResilient(Policy{MaxRetries: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: 8 * time.Second}, limited, limited)
+
+	if _, err := r.Fetch(context.Background(), FetchRequest{}); err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]time.Duration{5 * time.Second, 5 * time.Second}, *waits); diff != "" {
+		t.Errorf("waits mismatch (-want +got):\n%s", diff)
+	}
+
+	long := &ProviderError{Provider: "mock", Code: "UNAVAILABLE", Retry: true, RetryAfter: 60}
+	r, _, waits = newTestResilient(Policy{MaxRetries: 1, BaseDelay: 100 * time.Millisecond, MaxDelay: 8 * time.Second}, long)
+	if _, err := r.Fetch(context.Background(), FetchRequest{}); err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]time.Duration{8 * time.Second}, *waits); diff != "" {
+		t.Errorf("capped waits mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResilientStopsRetrying(t *testing.T) {
+	retryable := &ProviderError{Provider: "mock", Code: "UNAVAILABLE", Retry: true}
+	notFound := &ProviderError{Provider: "mock", Code: "NOT_FOUND"}
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{"out of retries", Policy{MaxRetries: 1}, []error{retryable, retryable, retryable}, retryable, 2},
+		{"retries disabled", Policy{}, []error{retryable}, retryable, 1},
+		{"not retryable", Policy{MaxRetries: 3}, []error{notFound}, notFound, 1},
+		{"plain error", Policy{MaxRetries: 3}, []error{context.DeadlineExceeded}, context.DeadlineExceeded, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, calls, _ := newTestResilient(tt.policy, tt.errs...)
+			_, err := r.Fetch(context.Background(), FetchRequest{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Fetch() error = %v, want %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("Fetch() made %d calls, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientRateLimits(t *testing.T) {
+	r, calls, _ := newTestResilient(Policy{Requests: 2, Window: 200 * time.Millisecond})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := r.Fetch(context.Background(), FetchRequest{}); err != nil {
+			t.Fatalf("Fetch() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("3 fetches took %v, want the third held for the 200ms window", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Fetch(ctx, FetchRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch(cancelled) error = %v, want context.Canceled", err)
+	}
+	if *calls != 3 {
+		t.Errorf("provider called %d times, want 3", *calls)
+	}
+}
+
+// limitingMockProvider waits on the rate limiter it's handed itself.
+type limitingMockProvider struct {
+	MockProvider
+	limiter *RateLimiter
+}
+
+func (m *limitingMockProvider) SetRateLimiter(limiter *RateLimiter) {
+	m.limiter = limiter
+}
+
+func TestResilientHandsLimiterToProvider(t *testing.T) {
+	limiting := &limitingMockProvider{MockProvider: MockProvider{name: "limiting"}}
+	r := NewResilient(limiting, Policy{Requests: 1, Window: time.Hour})
+	if limiting.limiter == nil {
+		t.Fatal("SetPolicy() didn't hand the rate limiter to the provider")
+	}
+
+	// The provider's requests take the slots, so calls don't wait for one
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := r.Fetch(context.Background(), FetchRequest{}); err != nil {
+			t.Fatalf("Fetch() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("3 fetches took %v, want no wait between calls", elapsed)
+	}
+
+	r.SetPolicy(DefaultPolicy())
+	if limiting.limiter != nil {
+		t.Errorf("SetPolicy() without a rate limit left the provider limited")
+	}
+}
+
+func TestResilientForwardsOptionalInterfaces(t *testing.T) {
+	searching := &searchingMockProvider{
+		MockProvider: MockProvider{name: "search"},
+		candidates:   []Candidate{{ID: "1", Title: "One"}},
+	}
+	r := NewResilient(searching, DefaultPolicy())
+
+	candidates, err := r.SearchCandidates(context.Background(), FetchRequest{}, 5)
+	if err != nil {
+		t.Fatalf("SearchCandidates() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(searching.candidates, candidates); diff != "" {
+		t.Errorf("SearchCandidates() mismatch (-want +got):\n%s", diff)
+	}
+	if id := r.ExactID(&Metadata{IDs: map[string]string{"mock_id": "42"}}); id != "42" {
+		t.Errorf("ExactID() = %q, want 42", id)
+	}
+	if r.Unwrap() != Provider(searching) {
+		t.Errorf("Unwrap() did not return the wrapped provider")
+	}
+
+	plain := NewResilient(&MockProvider{name: "plain"}, DefaultPolicy())
+	if _, err := plain.SearchCandidates(context.Background(), FetchRequest{}, 5); err == nil {
+		t.Errorf("SearchCandidates() on a provider without search succeeded, want error")
+	}
+	if id := plain.ExactID(&Metadata{}); id != "" {
+		t.Errorf("ExactID() = %q, want empty", id)
+	}
+}
+
+func TestResilientOffline(t *testing.T) {
+	r, calls, _ := newTestResilient(DefaultPolicy())
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestDiskCache(t, filepath.Join(t.TempDir(), "metadata.json"), now.Add(-30*24*time.Hour))
+	cached := FetchRequest{MediaType: MediaTypeMovie, Name: "Heat", Year: "1995"}
+	cache.Set("mock", CacheKey(cached), &Metadata{Core: CoreMetadata{Title: "Heat"}})
+	cache.now = func() time.Time { return now }
+	r.SetCache(cache)
+	r.SetOffline(true)
+
+	meta, err := r.Fetch(context.Background(), cached)
+	if err != nil {
+		t.Fatalf("Fetch(cached) unexpected error: %v", err)
+	}
+	if meta.Core.Title != "Heat" {
+		t.Errorf("Fetch(cached) title = %q, want the expired entry's Heat", meta.Core.Title)
+	}
+
+	_, err = r.Fetch(context.Background(), FetchRequest{MediaType: MediaTypeMovie, Name: "Ronin"})
+	if !IsCacheMiss(err) {
+		t.Errorf("Fetch(uncached) error = %v, want a cache miss", err)
+	}
+	if *calls != 0 {
+		t.Errorf("provider called %d times offline, want 0", *calls)
+	}
+
+	searching := NewResilient(&searchingMockProvider{MockProvider: MockProvider{name: "search"}}, DefaultPolicy())
+	searching.SetOffline(true)
+	if _, err := searching.SearchCandidates(context.Background(), cached, 5); err == nil {
+		t.Errorf("SearchCandidates() offline succeeded, want error")
+	}
+}
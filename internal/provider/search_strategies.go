@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SearchStrategy names a rewrite applied to a search query after the
+// provider failed to find a match for it.
+type SearchStrategy string
+
+const (
+	// SearchStrategyOriginal marks the unmodified query in recorded attempts.
+	SearchStrategyOriginal SearchStrategy = "original"
+	// SearchStrategyManual marks a query entered by the user in recorded attempts.
+	SearchStrategyManual SearchStrategy = "manual"
+	// SearchStrategyID marks a lookup by a provider ID picked by the user.
+	SearchStrategyID SearchStrategy = "id"
+	// SearchStrategyDropYear retries without the release year.
+	SearchStrategyDropYear SearchStrategy = "drop_year"
+	// SearchStrategyStripArticle removes a leading "The", "A" or "An".
+	SearchStrategyStripArticle SearchStrategy = "strip_article"
+	// SearchStrategyStripCountry removes a trailing country code such as "US".
+	SearchStrategyStripCountry SearchStrategy = "strip_country"
+	// SearchStrategySplitSubtitle tries each side of a " - " separator.
+	SearchStrategySplitSubtitle SearchStrategy = "split_subtitle"
+	// SearchStrategyAmpersand swaps "and" with "&" (and back).
+	SearchStrategyAmpersand SearchStrategy = "ampersand"
+	// SearchStrategyPunctuation tries dotted acronyms and punctuation-free names.
+	SearchStrategyPunctuation SearchStrategy = "punctuation"
+)
+
+// DefaultSearchStrategies is the fallback chain used when none is configured.
+var DefaultSearchStrategies = []SearchStrategy{
+	SearchStrategyDropYear,
+	SearchStrategyStripArticle,
+	SearchStrategyStripCountry,
+	SearchStrategySplitSubtitle,
+	SearchStrategyAmpersand,
+	SearchStrategyPunctuation,
+}
+
+var (
+	leadingArticleRe  = regexp.MustCompile(`(?i)^(the|a|an)\s+`)
+	trailingArticleRe = regexp.MustCompile(`(?i),\s*(the|a|an)$`)
+	trailingCountryRe = regexp.MustCompile(`\s*(?:[\(\[](?i:us|uk|gb|au|nz|ca|ie)[\)\]]|\b(?:US|UK|GB|AU|NZ|CA|IE))$`)
+	andWordRe         = regexp.MustCompile(`(?i)\band\b`)
+	whitespaceRe      = regexp.MustCompile(`\s+`)
+)
+
+// ParseSearchStrategies converts configured strategy names into strategies,
+// ignoring unknown entries.
+func ParseSearchStrategies(names []string) []SearchStrategy {
+	known := make(map[SearchStrategy]struct{}, len(DefaultSearchStrategies))
+	for _, strategy := range DefaultSearchStrategies {
+		known[strategy] = struct{}{}
+	}
+
+	result := make([]SearchStrategy, 0, len(names))
+	for _, name := range names {
+		strategy := SearchStrategy(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := known[strategy]; ok {
+			result = append(result, strategy)
+		}
+	}
+	return result
+}
+
+// SearchAttempt records a single query sent to a provider.
+type SearchAttempt struct {
+	Strategy SearchStrategy
+	Name     string
+	Year     string
+}
+
+// String formats the attempt for display.
+func (a SearchAttempt) String() string {
+	if a.Year != "" {
+		return fmt.Sprintf("%s (%s) [%s]", a.Name, a.Year, a.Strategy)
+	}
+	return fmt.Sprintf("%s [%s]", a.Name, a.Strategy)
+}
+
+// SearchError is returned when every query in a fallback chain failed. It
+// wraps the last provider error so errors.As still finds the ProviderError.
+type SearchError struct {
+	Attempts []SearchAttempt
+	Err      error
+}
+
+func (e *SearchError) Error() string {
+	msg := "no metadata found"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if len(e.Attempts) <= 1 {
+		return msg
+	}
+	return fmt.Sprintf("%s (tried %d queries)", msg, len(e.Attempts))
+}
+
+func (e *SearchError) Unwrap() error {
+	return e.Err
+}
+
+// SearchAttemptsFromError returns the queries recorded on a SearchError.
+func SearchAttemptsFromError(err error) []SearchAttempt {
+	var searchErr *SearchError
+	if errors.As(err, &searchErr) {
+		return searchErr.Attempts
+	}
+	return nil
+}
+
+// rewriteQuery applies a strategy to a query and returns the variants it
+// produces. An empty result means the strategy does not apply.
+func rewriteQuery(strategy SearchStrategy, name, year string) [][2]string {
+	switch strategy {
+	case SearchStrategyDropYear:
+		if year == "" {
+			return nil
+		}
+		return [][2]string{{name, ""}}
+	case SearchStrategyStripArticle:
+		stripped := leadingArticleRe.ReplaceAllString(name, "")
+		stripped = trailingArticleRe.ReplaceAllString(stripped, "")
+		return [][2]string{{stripped, year}}
+	case SearchStrategyStripCountry:
+		return [][2]string{{trailingCountryRe.ReplaceAllString(name, ""), year}}
+	case SearchStrategySplitSubtitle:
+		before, after, found := strings.Cut(name, " - ")
+		if !found {
+			return nil
+		}
+		return [][2]string{{before, year}, {after, year}}
+	case SearchStrategyAmpersand:
+		if andWordRe.MatchString(name) {
+			return [][2]string{{andWordRe.ReplaceAllString(name, "&"), year}}
+		}
+		if strings.Contains(name, "&") {
+			return [][2]string{{strings.ReplaceAll(name, "&", "and"), year}}
+		}
+		return nil
+	case SearchStrategyPunctuation:
+		return [][2]string{{dotAcronyms(name), year}, {stripPunctuation(name), year}}
+	default:
+		return nil
+	}
+}
+
+// dotAcronyms joins runs of three or more single letters into a dotted
+// acronym, turning "S H I E L D" into "S.H.I.E.L.D.".
+func dotAcronyms(name string) string {
+	words := strings.Fields(name)
+	result := make([]string, 0, len(words))
+	for i := 0; i < len(words); {
+		j := i
+		for j < len(words) && len([]rune(words[j])) == 1 && unicode.IsLetter([]rune(words[j])[0]) {
+			j++
+		}
+		if j-i >= 3 {
+			result = append(result, strings.Join(words[i:j], ".")+".")
+			i = j
+			continue
+		}
+		result = append(result, words[i])
+		i++
+	}
+	return strings.Join(result, " ")
+}
+
+// stripPunctuation removes everything except letters, digits and spaces.
+func stripPunctuation(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return -1
+	}, name)
+	return cleaned
+}
+
+// isSearchMiss reports whether a fetch result should trigger the next
+// fallback query. Only "not found" outcomes and offline cache misses, which
+// may have been cached under a fallback query, qualify; rate limits and auth
+// failures are returned to the caller immediately.
+func isSearchMiss(meta *Metadata, err error) bool {
+	if err == nil {
+		return meta == nil
+	}
+	return IsNotFound(err) || IsCacheMiss(err)
+}
+
+// searchWithFallbacks runs fetch for the original query and then for each
+// rewrite produced by the strategy chain until one returns metadata. Each
+// strategy builds on the first variant of the previous one.
+func searchWithFallbacks(ctx context.Context, name, year string, strategies []SearchStrategy, fetch func(name, year string) (*Metadata, error)) (*Metadata, error) {
+	attempts := []SearchAttempt{{Strategy: SearchStrategyOriginal, Name: name, Year: year}}
+	meta, err := fetch(name, year)
+	if !isSearchMiss(meta, err) || len(strategies) == 0 {
+		return meta, err
+	}
+
+	seen := map[string]struct{}{strings.ToLower(name) + "\x00" + year: {}}
+	baseName, baseYear := name, year
+	lastErr := err
+
+	for _, strategy := range strategies {
+		variants := rewriteQuery(strategy, baseName, baseYear)
+		for i, variant := range variants {
+			candidate := strings.TrimSpace(whitespaceRe.ReplaceAllString(variant[0], " "))
+			candidateYear := variant[1]
+			if candidate == "" {
+				continue
+			}
+			if i == 0 {
+				baseName, baseYear = candidate, candidateYear
+			}
+			key := strings.ToLower(candidate) + "\x00" + candidateYear
+			if _, tried := seen[key]; tried {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			attempts = append(attempts, SearchAttempt{Strategy: strategy, Name: candidate, Year: candidateYear})
+			meta, err = fetch(candidate, candidateYear)
+			if !isSearchMiss(meta, err) {
+				return meta, err
+			}
+			if err != nil {
+				lastErr = err
+			}
+		}
+	}
+
+	if lastErr == nil {
+		return nil, nil
+	}
+	return nil, &SearchError{Attempts: attempts, Err: lastErr}
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRewriteQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy SearchStrategy
+		query    string
+		year     string
+		want     [][2]string
+	}{
+		{name: "drop year", strategy: SearchStrategyDropYear, query: "Heat", year: "1995", want: [][2]string{{"Heat", ""}}},
+		{name: "drop missing year", strategy: SearchStrategyDropYear, query: "Heat", want: nil},
+		{name: "leading article", strategy: SearchStrategyStripArticle, query: "The Office", want: [][2]string{{"Office", ""}}},
+		{name: "trailing article", strategy: SearchStrategyStripArticle, query: "Office, The", want: [][2]string{{"Office", ""}}},
+		{name: "bare country", strategy: SearchStrategyStripCountry, query: "The Office US", want: [][2]string{{"The Office", ""}}},
+		{name: "wrapped country", strategy: SearchStrategyStripCountry, query: "Shameless (us)", want: [][2]string{{"Shameless", ""}}},
+		{name: "lowercase word kept", strategy: SearchStrategyStripCountry, query: "Among us", want: [][2]string{{"Among us", ""}}},
+		{name: "split subtitle", strategy: SearchStrategySplitSubtitle, query: "Star Wars - A New Hope", year: "1977", want: [][2]string{{"Star Wars", "1977"}, {"A New Hope", "1977"}}},
+		{name: "and to ampersand", strategy: SearchStrategyAmpersand, query: "Law and Order", want: [][2]string{{"Law & Order", ""}}},
+		{name: "ampersand to and", strategy: SearchStrategyAmpersand, query: "Rick & Morty", want: [][2]string{{"Rick and Morty", ""}}},
+		{name: "punctuation", strategy: SearchStrategyPunctuation, query: "Marvel's Agents of S H I E L D", want: [][2]string{{"Marvel's Agents of S.H.I.E.L.D.", ""}, {"Marvels Agents of S H I E L D", ""}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rewriteQuery(tc.strategy, tc.query, tc.year)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("rewriteQuery(%s) mismatch (-want +got):\n%s", tc.strategy, diff)
+			}
+		})
+	}
+}
+
+func TestParseSearchStrategies(t *testing.T) {
+	t.Parallel()
+
+	got := ParseSearchStrategies([]string{"Drop_Year", "bogus", " punctuation "})
+	want := []SearchStrategy{SearchStrategyDropYear, SearchStrategyPunctuation}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseSearchStrategies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchMetadataWithDependenciesFallbackQueries(t *testing.T) {
+	t.Parallel()
+
+	notFound := &ProviderError{Provider: "stub", Code: "NOT_FOUND", Message: "missing"}
+	var names []string
+	stub := &stubProvider{
+		fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+			names = append(names, req.Name)
+			if req.Name == "Marvels Agents of S.H.I.E.L.D." {
+				return &Metadata{Core: CoreMetadata{Title: "Marvel's Agents of S.H.I.E.L.D."}}, nil
+			}
+			return nil, notFound
+		},
+	}
+
+	got, err := FetchMetadataWithDependencies(context.Background(), stub, "Marvels Agents of S H I E L D", "", 0, 0, false, nil, nil, DefaultSearchStrategies...)
+	if err != nil {
+		t.Fatalf("FetchMetadataWithDependencies returned error %v, want nil", err)
+	}
+	if diff := cmp.Diff("Marvel's Agents of S.H.I.E.L.D.", got.Core.Title); diff != "" {
+		t.Errorf("title mismatch (-want +got):\n%s", diff)
+	}
+	wantNames := []string{"Marvels Agents of S H I E L D", "Marvels Agents of S.H.I.E.L.D."}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("queries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchMetadataWithDependenciesRecordsAttempts(t *testing.T) {
+	t.Parallel()
+
+	notFound := &ProviderError{Provider: "stub", Code: "NOT_FOUND", Message: "missing"}
+	stub := &stubProvider{
+		fetch: func(context.Context, FetchRequest) (*Metadata, error) {
+			return nil, notFound
+		},
+	}
+
+	_, err := FetchMetadataWithDependencies(context.Background(), stub, "The Movie", "2001", 0, 0, true, nil, nil, SearchStrategyDropYear, SearchStrategyStripArticle)
+
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "NOT_FOUND" {
+		t.Fatalf("error = %v, want wrapped NOT_FOUND ProviderError", err)
+	}
+	want := []SearchAttempt{
+		{Strategy: SearchStrategyOriginal, Name: "The Movie", Year: "2001"},
+		{Strategy: SearchStrategyDropYear, Name: "The Movie"},
+		{Strategy: SearchStrategyStripArticle, Name: "Movie"},
+	}
+	if diff := cmp.Diff(want, SearchAttemptsFromError(err)); diff != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchMetadataWithDependenciesStopsOnOtherErrors(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := &ProviderError{Provider: "stub", Code: "RATE_LIMITED", Message: "slow down", Retry: true}
+	calls := 0
+	stub := &stubProvider{
+		fetch: func(context.Context, FetchRequest) (*Metadata, error) {
+			calls++
+			return nil, rateLimited
+		},
+	}
+
+	_, err := FetchMetadataWithDependencies(context.Background(), stub, "The Movie", "2001", 0, 0, true, nil, nil, DefaultSearchStrategies...)
+	if !errors.Is(err, rateLimited) {
+		t.Fatalf("error = %v, want rate limit error", err)
+	}
+	if calls != 1 {
+		t.Errorf("fetch calls = %d, want 1", calls)
+	}
+}
+
+func TestFetchMetadataWithDependenciesFallsBackOnCacheMiss(t *testing.T) {
+	t.Parallel()
+
+	// Offline, only the query that found the movie online is cached.
+	miss := &ProviderError{Provider: "stub", Code: CacheMissCode, Message: "not cached"}
+	stub := &stubProvider{
+		fetch: func(_ context.Context, req FetchRequest) (*Metadata, error) {
+			if req.Year == "" {
+				return &Metadata{Core: CoreMetadata{Title: req.Name}}, nil
+			}
+			return nil, miss
+		},
+	}
+
+	got, err := FetchMetadataWithDependencies(context.Background(), stub, "The Movie", "2001", 0, 0, true, nil, nil, SearchStrategyDropYear)
+	if err != nil {
+		t.Fatalf("FetchMetadataWithDependencies returned error %v, want nil", err)
+	}
+	if got == nil || got.Core.Title != "The Movie" {
+		t.Errorf("FetchMetadataWithDependencies = %+v, want the cached fallback result", got)
+	}
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError is an API response with an unexpected HTTP status. Providers
+// return it from their HTTP layer so MapStatusError can classify failures by
+// status code instead of by what an error message happens to contain.
+type StatusError struct {
+	Status     int
+	RetryAfter int    // Seconds the Retry-After header asked for; 0 without one
+	Message    string // The failed request or the API's explanation, if any
+}
+
+// NewStatusError describes resp, which came back with an unexpected status.
+func NewStatusError(resp *http.Response, message string) *StatusError {
+	return &StatusError{
+		Status:     resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Message:    message,
+	}
+}
+
+func (e *StatusError) Error() string {
+	status := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	if e.Message == "" {
+		return status
+	}
+	return e.Message + ": " + status
+}
+
+// MapStatusError converts the StatusError in err's chain to a ProviderError:
+// 401 and 403 fail authentication, 404 is NOT_FOUND, 429 and the gateway
+// errors are retried after the server's Retry-After, and any other status is
+// UNKNOWN. service names the API in messages. It returns nil when err holds
+// no StatusError.
+func MapStatusError(providerName, service string, err error) *ProviderError {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+
+	switch statusErr.Status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &ProviderError{Provider: providerName, Code: "AUTH_FAILED", Message: service + " authentication failed: " + err.Error(), Retry: false}
+	case http.StatusNotFound:
+		return &ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: err.Error(), Retry: false}
+	case http.StatusTooManyRequests:
+		return &ProviderError{Provider: providerName, Code: "RATE_LIMITED", Message: service + " rate limit exceeded", Retry: true, RetryAfter: statusErr.RetryAfter}
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return &ProviderError{Provider: providerName, Code: "UNAVAILABLE", Message: service + " service unavailable", Retry: true, RetryAfter: statusErr.RetryAfter}
+	default:
+		return &ProviderError{Provider: providerName, Code: "UNKNOWN", Message: service + " error: " + err.Error(), Retry: false}
+	}
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date, as whole seconds after now.
+func parseRetryAfter(value string, now time.Time) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(seconds, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return int(math.Ceil(at.Sub(now).Seconds()))
+	}
+	return 0
+}
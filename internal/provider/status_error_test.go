@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMapStatusError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantCode       string
+		wantRetry      bool
+		wantRetryAfter int
+	}{
+		{name: "unauthorized", err: &StatusError{Status: http.StatusUnauthorized}, wantCode: "AUTH_FAILED"},
+		{name: "forbidden", err: &StatusError{Status: http.StatusForbidden}, wantCode: "AUTH_FAILED"},
+		{name: "not_found", err: &StatusError{Status: http.StatusNotFound}, wantCode: "NOT_FOUND"},
+		{name: "rate_limited", err: &StatusError{Status: http.StatusTooManyRequests, RetryAfter: 7}, wantCode: "RATE_LIMITED", wantRetry: true, wantRetryAfter: 7},
+		{name: "unavailable", err: &StatusError{Status: http.StatusServiceUnavailable, RetryAfter: 30}, wantCode: "UNAVAILABLE", wantRetry: true, wantRetryAfter: 30},
+		{name: "gateway_timeout", err: &StatusError{Status: http.StatusGatewayTimeout}, wantCode: "UNAVAILABLE", wantRetry: true},
+		{name: "server_error", err: &StatusError{Status: http.StatusInternalServerError}, wantCode: "UNKNOWN"},
+		// Titles, IDs and URLs can hold digits that look like status codes
+		{name: "wrapped_with_title", err: fmt.Errorf("search Room 503: %w", &StatusError{Status: http.StatusUnauthorized}), wantCode: "AUTH_FAILED"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MapStatusError("mock", "Mock", tc.err)
+			if got == nil {
+				t.Fatalf("MapStatusError(%v) = nil, want %s", tc.err, tc.wantCode)
+			}
+			if got.Code != tc.wantCode || got.Retry != tc.wantRetry || got.RetryAfter != tc.wantRetryAfter {
+				t.Errorf("MapStatusError(%v) = %s retry %v after %d, want %s retry %v after %d",
+					tc.err, got.Code, got.Retry, got.RetryAfter, tc.wantCode, tc.wantRetry, tc.wantRetryAfter)
+			}
+		})
+	}
+
+	if got := MapStatusError("mock", "Mock", errors.New("dial tcp: 503 connection refused")); got != nil {
+		t.Errorf("MapStatusError(plain error) = %v, want nil", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 0},
+		{value: "12", want: 12},
+		{value: " 3 ", want: 3},
+		{value: "-5", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tc := range tests {
+		if got := parseRetryAfter(tc.value, now); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %d, want %d", tc.value, got, tc.want)
+		}
+	}
+}
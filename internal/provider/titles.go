@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"strings"
+)
+
+// OriginalTitleLanguage is the pseudo language code that selects the title in
+// the media's original language when used in a language priority list.
+const OriginalTitleLanguage = "original"
+
+// iso6392Languages maps common three letter ISO 639-2 codes (used by TVDB) to
+// their two letter ISO 639-1 equivalents (used by TMDB and most users).
+var iso6392Languages = map[string]string{
+	"ara": "ar",
+	"ces": "cs",
+	"chi": "zh",
+	"cze": "cs",
+	"dan": "da",
+	"deu": "de",
+	"dut": "nl",
+	"ell": "el",
+	"eng": "en",
+	"fin": "fi",
+	"fra": "fr",
+	"fre": "fr",
+	"ger": "de",
+	"gre": "el",
+	"heb": "he",
+	"hin": "hi",
+	"hun": "hu",
+	"ita": "it",
+	"jpn": "ja",
+	"kor": "ko",
+	"nld": "nl",
+	"nor": "no",
+	"pol": "pl",
+	"por": "pt",
+	"rus": "ru",
+	"spa": "es",
+	"swe": "sv",
+	"tha": "th",
+	"tur": "tr",
+	"ukr": "uk",
+	"zho": "zh",
+}
+
+// NormalizeLanguage lowercases a language tag and maps three letter codes to
+// their two letter form so "de-DE", "DE" and "deu" compare equal by base.
+func NormalizeLanguage(code string) string {
+	code = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(code, "_", "-")))
+	if code == "" {
+		return ""
+	}
+	base, region, _ := strings.Cut(code, "-")
+	if mapped, ok := iso6392Languages[base]; ok {
+		base = mapped
+	}
+	if region == "" {
+		return base
+	}
+	return base + "-" + region
+}
+
+// ParseLanguagePriority converts a configured language priority into a clean
+// list. It accepts a []string, []interface{} or a comma separated string.
+func ParseLanguagePriority(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case string:
+		raw = strings.Split(v, ",")
+	}
+
+	result := make([]string, 0, len(raw))
+	for _, entry := range raw {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		result = append(result, entry)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// TitleVariants collects the titles a provider knows for a single movie or
+// show so a language priority list can pick the one used for {title}.
+type TitleVariants struct {
+	Localized    string            // Title returned for the provider's request language
+	Original     string            // Title in the original language
+	Translations map[string]string // Translated titles keyed by normalized language
+	Alternates   []string          // Alternate titles and aliases
+}
+
+// AddTranslation records a translated title. The first title seen for a
+// language wins, and the base language is filled in when missing.
+func (t *TitleVariants) AddTranslation(language, title string) {
+	title = strings.TrimSpace(title)
+	lang := NormalizeLanguage(language)
+	if title == "" || lang == "" {
+		return
+	}
+	if t.Translations == nil {
+		t.Translations = make(map[string]string)
+	}
+	if _, exists := t.Translations[lang]; !exists {
+		t.Translations[lang] = title
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		if _, exists := t.Translations[base]; !exists {
+			t.Translations[base] = title
+		}
+	}
+}
+
+// AddAlternate records an alternate title, ignoring blanks and duplicates.
+func (t *TitleVariants) AddAlternate(title string) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return
+	}
+	for _, existing := range t.Alternates {
+		if strings.EqualFold(existing, title) {
+			return
+		}
+	}
+	t.Alternates = append(t.Alternates, title)
+}
+
+// translation looks up a title by language, falling back to the base language.
+func (t TitleVariants) translation(language string) string {
+	lang := NormalizeLanguage(language)
+	if lang == "" || t.Translations == nil {
+		return ""
+	}
+	if title := t.Translations[lang]; title != "" {
+		return title
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		return t.Translations[base]
+	}
+	return ""
+}
+
+// Select walks the language priority list and returns the title to use for
+// {title} along with the best localized title. With an empty priority list
+// both values are the provider's localized title.
+func (t TitleVariants) Select(priority []string) (title, localized string) {
+	for _, lang := range priority {
+		if strings.EqualFold(strings.TrimSpace(lang), OriginalTitleLanguage) {
+			if title == "" && t.Original != "" {
+				title = t.Original
+			}
+			continue
+		}
+		candidate := t.translation(lang)
+		if candidate == "" {
+			continue
+		}
+		if localized == "" {
+			localized = candidate
+		}
+		if title == "" {
+			title = candidate
+		}
+	}
+
+	if localized == "" {
+		localized = firstNonBlank(t.Localized, t.Original)
+	}
+	if title == "" {
+		title = localized
+	}
+	return title, localized
+}
+
+// Apply selects the title for meta using the priority list and records the
+// original, localized and alternate titles in the extended metadata.
+func (t TitleVariants) Apply(meta *Metadata, priority []string) {
+	if meta == nil {
+		return
+	}
+	title, localized := t.Select(priority)
+	if title == "" {
+		return
+	}
+	if meta.Extended == nil {
+		meta.Extended = make(map[string]interface{})
+	}
+
+	meta.Core.Title = title
+	meta.Extended["localized_title"] = localized
+	if t.Original != "" {
+		meta.Extended["original_title"] = t.Original
+	}
+
+	alternates := make([]string, 0, len(t.Alternates))
+	for _, alt := range t.Alternates {
+		if strings.EqualFold(alt, title) || strings.EqualFold(alt, t.Original) {
+			continue
+		}
+		alternates = append(alternates, alt)
+	}
+	if len(alternates) > 0 {
+		meta.Extended["alternate_titles"] = strings.Join(alternates, ", ")
+	}
+}
+
+func firstNonBlank(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
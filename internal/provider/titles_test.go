@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"de-DE": "de-de",
+		"DE":    "de",
+		"deu":   "de",
+		"ger":   "de",
+		"pt_BR": "pt-br",
+		"eng":   "en",
+		" ":     "",
+	}
+	for input, want := range tests {
+		if got := NormalizeLanguage(input); got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseLanguagePriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  []string
+	}{
+		{name: "string", input: "de, en ,original", want: []string{"de", "en", "original"}},
+		{name: "slice", input: []string{"fr", "", "original"}, want: []string{"fr", "original"}},
+		{name: "interface slice", input: []interface{}{"ja", 3, "en"}, want: []string{"ja", "en"}},
+		{name: "empty", input: "", want: nil},
+		{name: "nil", input: nil, want: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ParseLanguagePriority(tc.input)); diff != "" {
+				t.Errorf("ParseLanguagePriority() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTitleVariantsSelect(t *testing.T) {
+	t.Parallel()
+
+	variants := TitleVariants{Localized: "The Boat", Original: "Das Boot"}
+	variants.AddTranslation("en-US", "The Boat")
+	variants.AddTranslation("fr-FR", "Le Bateau")
+	variants.AddTranslation("de", "Das Boot")
+
+	tests := []struct {
+		name          string
+		priority      []string
+		wantTitle     string
+		wantLocalized string
+	}{
+		{name: "no priority keeps localized", priority: nil, wantTitle: "The Boat", wantLocalized: "The Boat"},
+		{name: "first available language", priority: []string{"es", "fr", "en"}, wantTitle: "Le Bateau", wantLocalized: "Le Bateau"},
+		{name: "base language match", priority: []string{"fr-CA"}, wantTitle: "Le Bateau", wantLocalized: "Le Bateau"},
+		{name: "original before language", priority: []string{"original", "en"}, wantTitle: "Das Boot", wantLocalized: "The Boat"},
+		{name: "missing languages fall back", priority: []string{"ko", "ja"}, wantTitle: "The Boat", wantLocalized: "The Boat"},
+		{name: "three letter codes", priority: []string{"fra"}, wantTitle: "Le Bateau", wantLocalized: "Le Bateau"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			title, localized := variants.Select(tc.priority)
+			if diff := cmp.Diff(tc.wantTitle, title); diff != "" {
+				t.Errorf("title mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLocalized, localized); diff != "" {
+				t.Errorf("localized mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTitleVariantsApply(t *testing.T) {
+	t.Parallel()
+
+	variants := TitleVariants{Localized: "Spirited Away", Original: "千と千尋の神隠し"}
+	variants.AddTranslation("ja", "千と千尋の神隠し")
+	variants.AddAlternate("Sen to Chihiro no Kamikakushi")
+	variants.AddAlternate("sen to chihiro no kamikakushi")
+	variants.AddAlternate("Spirited Away")
+
+	meta := &Metadata{Core: CoreMetadata{Title: "Spirited Away"}}
+	variants.Apply(meta, []string{"original"})
+
+	if diff := cmp.Diff("千と千尋の神隠し", meta.Core.Title); diff != "" {
+		t.Errorf("Core.Title mismatch (-want +got):\n%s", diff)
+	}
+	want := map[string]interface{}{
+		"original_title":   "千と千尋の神隠し",
+		"localized_title":  "Spirited Away",
+		"alternate_titles": "Sen to Chihiro no Kamikakushi, Spirited Away",
+	}
+	if diff := cmp.Diff(want, meta.Extended); diff != "" {
+		t.Errorf("Extended mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,113 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+// httpClient calls the TMDB v3 API directly. It decodes into go-tmdb's types
+// where those exist, and every request waits for the rate limiter, since one
+// lookup can take several requests.
+type httpClient struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	limiter *provider.RateLimiter
+}
+
+func newHTTPClient(apiKey string, limiter *provider.RateLimiter) *httpClient {
+	return &httpClient{
+		apiKey:  apiKey,
+		baseURL: "https://api.themoviedb.org/3",
+		client:  &http.Client{Timeout: 30 * time.Second},
+		limiter: limiter,
+	}
+}
+
+func (c *httpClient) get(path string, options map[string]string, out interface{}) error {
+	if c.limiter != nil {
+		// A slot frees up within the window, so the wait is bounded
+		if err := c.limiter.Wait(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	query := url.Values{"api_key": {c.apiKey}}
+	for key, value := range options {
+		query.Set(key, value)
+	}
+	resp, err := c.client.Get(c.baseURL + path + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return provider.NewStatusError(resp, "GET "+path)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// search runs a search with name as the query.
+func (c *httpClient) search(path, name string, options map[string]string, out interface{}) error {
+	query := map[string]string{"query": name}
+	for key, value := range options {
+		query[key] = value
+	}
+	return c.get(path, query, out)
+}
+
+func (c *httpClient) SearchMovie(name string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	var results tmdb.MovieSearchResults
+	if err := c.search("/search/movie", name, options, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+func (c *httpClient) SearchTv(name string, options map[string]string) (*tmdb.TvSearchResults, error) {
+	var results tmdb.TvSearchResults
+	if err := c.search("/search/tv", name, options, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+func (c *httpClient) GetMovieInfo(id int, options map[string]string) (*tmdb.Movie, error) {
+	var movie tmdb.Movie
+	if err := c.get(fmt.Sprintf("/movie/%d", id), options, &movie); err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
+
+func (c *httpClient) GetTvInfo(id int, options map[string]string) (*tmdb.TV, error) {
+	var show tmdb.TV
+	if err := c.get(fmt.Sprintf("/tv/%d", id), options, &show); err != nil {
+		return nil, err
+	}
+	return &show, nil
+}
+
+func (c *httpClient) GetTvSeasonInfo(showID, seasonID int, options map[string]string) (*tmdb.TvSeason, error) {
+	var season tmdb.TvSeason
+	if err := c.get(fmt.Sprintf("/tv/%d/season/%d", showID, seasonID), options, &season); err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+func (c *httpClient) GetTvEpisodeInfo(showID, seasonNum, episodeNum int, options map[string]string) (*tmdb.TvEpisode, error) {
+	var episode tmdb.TvEpisode
+	if err := c.get(fmt.Sprintf("/tv/%d/season/%d/episode/%d", showID, seasonNum, episodeNum), options, &episode); err != nil {
+		return nil, err
+	}
+	return &episode, nil
+}
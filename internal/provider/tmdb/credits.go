@@ -0,0 +1,177 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+// castLimit is how many top-billed actors fill {cast}.
+const castLimit = 3
+
+// ContentRatingClient fetches the content ratings of a show, one per country.
+// go-tmdb has no type for this endpoint.
+type ContentRatingClient interface {
+	GetTvContentRatings(showID int) (*ContentRatings, error)
+}
+
+// ContentRatings is the list of a show's content ratings.
+type ContentRatings struct {
+	Results []ContentRating `json:"results"`
+}
+
+// ContentRating is the rating a show carries in one country, such as "TV-MA".
+type ContentRating struct {
+	Country string `json:"iso_3166_1"`
+	Rating  string `json:"rating"`
+}
+
+func (c *httpClient) GetTvContentRatings(showID int) (*ContentRatings, error) {
+	var ratings ContentRatings
+	if err := c.get(fmt.Sprintf("/tv/%d/content_ratings", showID), nil, &ratings); err != nil {
+		return nil, err
+	}
+	return &ratings, nil
+}
+
+// movieAppend lists the extra responses fetched with movie details. Credits
+// and release certifications are only requested when a template uses them.
+func (p *Provider) movieAppend() string {
+	parts := []string{"alternative_titles", "translations"}
+	if p.credits {
+		parts = append(parts, "credits")
+	}
+	if p.certificationCountry != "" {
+		parts = append(parts, "releases")
+	}
+	return strings.Join(parts, ",")
+}
+
+// showAppend lists the extra responses fetched with show details.
+func (p *Provider) showAppend() string {
+	if p.credits {
+		return "external_ids,alternative_titles,translations,credits"
+	}
+	return "external_ids,alternative_titles,translations"
+}
+
+// showCertification looks up the show's content rating in the configured
+// country. Failures leave {certification} empty rather than failing the
+// lookup.
+func (p *Provider) showCertification(showID int) string {
+	if p.certificationCountry == "" || p.ratings == nil {
+		return ""
+	}
+	ratings, err := p.ratings.GetTvContentRatings(showID)
+	if err != nil || ratings == nil {
+		return ""
+	}
+	for _, r := range ratings.Results {
+		if strings.EqualFold(r.Country, p.certificationCountry) && r.Rating != "" {
+			return r.Rating
+		}
+	}
+	return ""
+}
+
+// withShowCertification adds {certification} to a show's metadata.
+func (p *Provider) withShowCertification(meta *provider.Metadata, showID int) *provider.Metadata {
+	return withCertification(meta, p.showCertification(showID))
+}
+
+// withCertification sets {certification} unless certification is empty.
+func withCertification(meta *provider.Metadata, certification string) *provider.Metadata {
+	if certification != "" {
+		meta.Extended["certification"] = certification
+	}
+	return meta
+}
+
+// episodeShow fetches the show details its episodes share: the series name
+// and IDs, plus the cast and certification when templates use them. Both
+// are looked up once per season batch. Failures leave them out rather than
+// failing the episodes.
+func (p *Provider) episodeShow(showID int, request provider.FetchRequest) (*tmdb.TV, string) {
+	show, _ := p.client.GetTvInfo(showID, map[string]string{
+		"language":           p.getLanguage(request),
+		"append_to_response": p.showAppend(),
+	})
+	return show, p.showCertification(showID)
+}
+
+// movieCertification picks the movie's certification in the configured
+// country from its release list.
+func (p *Provider) movieCertification(releases *tmdb.MovieReleases) string {
+	if p.certificationCountry == "" || releases == nil {
+		return ""
+	}
+	for _, c := range releases.Countries {
+		if strings.EqualFold(c.Iso3166_1, p.certificationCountry) && c.Certification != "" {
+			return c.Certification
+		}
+	}
+	return ""
+}
+
+// billedName is a cast member with their billing position.
+type billedName struct {
+	name  string
+	order int
+}
+
+// topBilled returns up to castLimit names in billing order.
+func topBilled(cast []billedName) string {
+	sort.SliceStable(cast, func(i, j int) bool { return cast[i].order < cast[j].order })
+	names := []string{}
+	for _, c := range cast {
+		if c.name == "" {
+			continue
+		}
+		names = append(names, c.name)
+		if len(names) == castLimit {
+			break
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyMovieCredits adds {director} and {cast} from the movie's credits.
+func applyMovieCredits(extended map[string]interface{}, credits *tmdb.MovieCredits) {
+	if credits == nil {
+		return
+	}
+	directors := []string{}
+	for _, c := range credits.Crew {
+		if c.Job == "Director" {
+			directors = append(directors, c.Name)
+		}
+	}
+	if len(directors) > 0 {
+		extended["director"] = strings.Join(directors, ", ")
+	}
+
+	cast := make([]billedName, 0, len(credits.Cast))
+	for _, c := range credits.Cast {
+		cast = append(cast, billedName{name: c.Name, order: c.Order})
+	}
+	if names := topBilled(cast); names != "" {
+		extended["cast"] = names
+	}
+}
+
+// applyShowCredits adds {cast} from the show's credits.
+func applyShowCredits(extended map[string]interface{}, credits *tmdb.TvCredits) {
+	if credits == nil {
+		return
+	}
+	cast := make([]billedName, 0, len(credits.Cast))
+	for _, c := range credits.Cast {
+		cast = append(cast, billedName{name: c.Name, order: c.Order})
+	}
+	if names := topBilled(cast); names != "" {
+		extended["cast"] = names
+	}
+}
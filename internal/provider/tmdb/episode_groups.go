@@ -0,0 +1,214 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+// TMDB episode group types for the alternate orders
+const (
+	groupTypeAbsolute = 2
+	groupTypeDVD      = 3
+	groupTypeStory    = 5
+)
+
+// EpisodeGroupClient fetches TMDB episode groups, which list a show's
+// episodes in an alternate order. go-tmdb has no types for these endpoints.
+type EpisodeGroupClient interface {
+	GetTvEpisodeGroups(showID int) (*EpisodeGroupList, error)
+	GetEpisodeGroup(groupID string, options map[string]string) (*EpisodeGroup, error)
+}
+
+// EpisodeGroupList is the list of a show's episode groups.
+type EpisodeGroupList struct {
+	Results []EpisodeGroupSummary `json:"results"`
+}
+
+// EpisodeGroupSummary describes one episode group of a show.
+type EpisodeGroupSummary struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Type         int    `json:"type"`
+	EpisodeCount int    `json:"episode_count"`
+}
+
+// EpisodeGroup is an alternate order split into seasons, such as the
+// volumes of a DVD release.
+type EpisodeGroup struct {
+	ID     string               `json:"id"`
+	Name   string               `json:"name"`
+	Type   int                  `json:"type"`
+	Groups []EpisodeGroupSeason `json:"groups"`
+}
+
+// EpisodeGroupSeason is one season of an episode group.
+type EpisodeGroupSeason struct {
+	Name     string              `json:"name"`
+	Order    int                 `json:"order"`
+	Episodes []EpisodeGroupEntry `json:"episodes"`
+}
+
+// EpisodeGroupEntry is an episode with its aired season and episode numbers
+// and its 0-based position in the group season.
+type EpisodeGroupEntry struct {
+	tmdb.TvEpisode
+	Order int `json:"order"`
+}
+
+func (c *httpClient) GetTvEpisodeGroups(showID int) (*EpisodeGroupList, error) {
+	var list EpisodeGroupList
+	if err := c.get(fmt.Sprintf("/tv/%d/episode_groups", showID), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *httpClient) GetEpisodeGroup(groupID string, options map[string]string) (*EpisodeGroup, error) {
+	var group EpisodeGroup
+	if err := c.get("/tv/episode_group/"+url.PathEscape(groupID), options, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// episodeGroups caches the episode group picked for each show and order.
+type episodeGroups struct {
+	mu     sync.Mutex
+	groups map[string]*EpisodeGroup
+}
+
+// episodeGroup returns the show's episode group for order. It returns nil
+// for the aired order, and when TMDB has no group of that type the show is
+// numbered in aired order.
+func (p *Provider) episodeGroup(showID int, order provider.EpisodeOrder, language string) (*EpisodeGroup, error) {
+	groupType := 0
+	switch order {
+	case provider.EpisodeOrderDVD:
+		groupType = groupTypeDVD
+	case provider.EpisodeOrderAbsolute:
+		groupType = groupTypeAbsolute
+	case provider.EpisodeOrderStory:
+		groupType = groupTypeStory
+	}
+	if groupType == 0 || p.groups == nil {
+		return nil, nil
+	}
+
+	key := fmt.Sprintf("%d|%s|%s", showID, order, language)
+	p.groupCache.mu.Lock()
+	group, ok := p.groupCache.groups[key]
+	p.groupCache.mu.Unlock()
+	if ok {
+		return group, nil
+	}
+
+	// Fetched without the lock so lookups of other shows don't wait on the
+	// requests. Concurrent misses for one show may fetch it twice.
+	list, err := p.groups.GetTvEpisodeGroups(showID)
+	if err != nil {
+		return nil, p.mapError(err)
+	}
+	var best *EpisodeGroupSummary
+	for i, summary := range list.Results {
+		if summary.Type == groupType && (best == nil || summary.EpisodeCount > best.EpisodeCount) {
+			best = &list.Results[i]
+		}
+	}
+
+	if best != nil {
+		group, err = p.groups.GetEpisodeGroup(best.ID, map[string]string{"language": language})
+		if err != nil {
+			return nil, p.mapError(err)
+		}
+	}
+
+	p.groupCache.mu.Lock()
+	defer p.groupCache.mu.Unlock()
+	if p.groupCache.groups == nil {
+		p.groupCache.groups = make(map[string]*EpisodeGroup)
+	}
+	p.groupCache.groups[key] = group
+	return group, nil
+}
+
+// groupSeasonEpisodes returns the episodes numbered season in group, keyed
+// by episode number. Absolute groups number every episode outside specials
+// in one run and ignore season.
+func groupSeasonEpisodes(group *EpisodeGroup, order provider.EpisodeOrder, season int) (map[int]*EpisodeGroupEntry, bool) {
+	seasons := slices.Clone(group.Groups)
+	slices.SortStableFunc(seasons, func(a, b EpisodeGroupSeason) int { return a.Order - b.Order })
+
+	var entries []*EpisodeGroupEntry
+	found := false
+	if order == provider.EpisodeOrderAbsolute {
+		for i := range seasons {
+			if isSpecials(seasons[i].Name) {
+				continue
+			}
+			entries = append(entries, sortedEntries(&seasons[i])...)
+			found = true
+		}
+	} else {
+		// Groups order specials first at 0; without them the first season
+		// is 0 too.
+		offset := 0
+		if len(seasons) > 0 && seasons[0].Order == 0 && !isSpecials(seasons[0].Name) {
+			offset = 1
+		}
+		for i := range seasons {
+			if seasons[i].Order+offset == season {
+				entries = sortedEntries(&seasons[i])
+				found = true
+				break
+			}
+		}
+	}
+
+	episodes := make(map[int]*EpisodeGroupEntry, len(entries))
+	for i, entry := range entries {
+		episodes[i+1] = entry
+	}
+	return episodes, found
+}
+
+func sortedEntries(season *EpisodeGroupSeason) []*EpisodeGroupEntry {
+	entries := make([]*EpisodeGroupEntry, len(season.Episodes))
+	for i := range season.Episodes {
+		entries[i] = &season.Episodes[i]
+	}
+	slices.SortStableFunc(entries, func(a, b *EpisodeGroupEntry) int { return a.Order - b.Order })
+	return entries
+}
+
+func isSpecials(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "special") || strings.Contains(name, "extra")
+}
+
+// orderedEpisodes returns the episodes of the requested season in the show's
+// configured order, keyed by episode number. It returns nil when the show
+// is numbered in aired order.
+func (p *Provider) orderedEpisodes(showID int, request provider.FetchRequest) (map[int]*EpisodeGroupEntry, error) {
+	order := p.episodeOrders.For(request.ShowName(), request.Name)
+	group, err := p.episodeGroup(showID, order, p.getLanguage(request))
+	if err != nil || group == nil {
+		return nil, err
+	}
+
+	episodes, ok := groupSeasonEpisodes(group, order, request.Season)
+	if !ok {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "NOT_FOUND",
+			Message:  fmt.Sprintf("season %d not found in %s order", request.Season, order),
+			Retry:    false,
+		}
+	}
+	return episodes, nil
+}
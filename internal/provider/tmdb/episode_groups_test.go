@@ -0,0 +1,174 @@
+package tmdb
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+func groupEntry(season, episode, order int) EpisodeGroupEntry {
+	return EpisodeGroupEntry{TvEpisode: tmdb.TvEpisode{SeasonNumber: season, EpisodeNumber: episode}, Order: order}
+}
+
+func TestGroupSeasonEpisodes(t *testing.T) {
+	t.Parallel()
+
+	// A DVD order without specials, so its first volume sits at order 0
+	dvd := &EpisodeGroup{Groups: []EpisodeGroupSeason{
+		{Name: "Volume 2", Order: 1, Episodes: []EpisodeGroupEntry{groupEntry(1, 4, 0)}},
+		{Name: "Volume 1", Order: 0, Episodes: []EpisodeGroupEntry{groupEntry(1, 3, 1), groupEntry(1, 1, 0)}},
+	}}
+	withSpecials := &EpisodeGroup{Groups: []EpisodeGroupSeason{
+		{Name: "Specials", Order: 0, Episodes: []EpisodeGroupEntry{groupEntry(0, 1, 0)}},
+		{Name: "Season 1", Order: 1, Episodes: []EpisodeGroupEntry{groupEntry(1, 2, 0)}},
+		{Name: "Season 2", Order: 2, Episodes: []EpisodeGroupEntry{groupEntry(2, 1, 0)}},
+	}}
+
+	tests := []struct {
+		name    string
+		group   *EpisodeGroup
+		order   provider.EpisodeOrder
+		season  int
+		episode int
+		want    [2]int // aired season and episode
+		found   bool
+	}{
+		{name: "first volume", group: dvd, order: provider.EpisodeOrderDVD, season: 1, episode: 2, want: [2]int{1, 3}, found: true},
+		{name: "second volume", group: dvd, order: provider.EpisodeOrderDVD, season: 2, episode: 1, want: [2]int{1, 4}, found: true},
+		{name: "missing volume", group: dvd, order: provider.EpisodeOrderDVD, season: 3},
+		{name: "specials at zero", group: withSpecials, order: provider.EpisodeOrderStory, season: 0, episode: 1, want: [2]int{0, 1}, found: true},
+		{name: "season after specials", group: withSpecials, order: provider.EpisodeOrderStory, season: 1, episode: 1, want: [2]int{1, 2}, found: true},
+		{name: "absolute skips specials", group: withSpecials, order: provider.EpisodeOrderAbsolute, season: 1, episode: 2, want: [2]int{2, 1}, found: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			episodes, found := groupSeasonEpisodes(tc.group, tc.order, tc.season)
+			if found != tc.found {
+				t.Fatalf("groupSeasonEpisodes() found = %v, want %v", found, tc.found)
+			}
+			if !found {
+				return
+			}
+			entry := episodes[tc.episode]
+			if entry == nil {
+				t.Fatalf("episode %d missing from %d episodes", tc.episode, len(episodes))
+			}
+			if got := [2]int{entry.SeasonNumber, entry.EpisodeNumber}; got != tc.want {
+				t.Errorf("episode %d = S%02dE%02d, want S%02dE%02d", tc.episode, got[0], got[1], tc.want[0], tc.want[1])
+			}
+		})
+	}
+}
+
+// stubGroups serves one DVD episode group. Group list lookups of the show in
+// block wait until block is closed.
+type stubGroups struct {
+	lists   atomic.Int32
+	blocked int
+	block   chan struct{}
+}
+
+func (s *stubGroups) GetTvEpisodeGroups(showID int) (*EpisodeGroupList, error) {
+	s.lists.Add(1)
+	if showID == s.blocked {
+		<-s.block
+	}
+	return &EpisodeGroupList{Results: []EpisodeGroupSummary{{ID: "dvd", Type: groupTypeDVD, EpisodeCount: 2}}}, nil
+}
+
+func (s *stubGroups) GetEpisodeGroup(groupID string, options map[string]string) (*EpisodeGroup, error) {
+	return &EpisodeGroup{ID: groupID, Type: groupTypeDVD, Groups: []EpisodeGroupSeason{
+		{Name: "Volume 1", Order: 0, Episodes: []EpisodeGroupEntry{groupEntry(1, 2, 0), groupEntry(1, 1, 1)}},
+	}}, nil
+}
+
+func TestOrderedEpisodesUsesParsedShowName(t *testing.T) {
+	t.Parallel()
+
+	orders, err := provider.ParseEpisodeOrders(map[string]interface{}{
+		"episode_orders": map[string]string{"Haus des Geldes": "dvd"},
+	})
+	if err != nil {
+		t.Fatalf("ParseEpisodeOrders() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		request provider.FetchRequest
+		want    bool
+	}{
+		{
+			name: "parsed title",
+			request: provider.FetchRequest{Name: "Money Heist", Season: 1,
+				Extra: map[string]interface{}{provider.ExtraShowName: "Haus des Geldes"}},
+			want: true,
+		},
+		{name: "matched title", request: provider.FetchRequest{Name: "Haus des Geldes", Season: 1}, want: true},
+		{
+			name: "no override",
+			request: provider.FetchRequest{Name: "Money Heist", Season: 1,
+				Extra: map[string]interface{}{provider.ExtraShowName: "La casa de papel"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{language: "en-US", episodeOrders: orders, groups: &stubGroups{}}
+			episodes, err := p.orderedEpisodes(71446, tc.request)
+			if err != nil {
+				t.Fatalf("orderedEpisodes() unexpected error: %v", err)
+			}
+			if got := episodes != nil; got != tc.want {
+				t.Fatalf("orderedEpisodes() reordered = %v, want %v", got, tc.want)
+			}
+			if tc.want && episodes[1].EpisodeNumber != 2 {
+				t.Errorf("DVD episode 1 = aired episode %d, want 2", episodes[1].EpisodeNumber)
+			}
+		})
+	}
+}
+
+func TestEpisodeGroupFetchesOutsideLock(t *testing.T) {
+	t.Parallel()
+
+	groups := &stubGroups{blocked: 2, block: make(chan struct{})}
+	p := &Provider{groups: groups}
+	if _, err := p.episodeGroup(1, provider.EpisodeOrderDVD, "en-US"); err != nil {
+		t.Fatalf("episodeGroup(1) unexpected error: %v", err)
+	}
+
+	// A slow fetch of show 2 doesn't hold up show 1, cached or not
+	fetched := make(chan error, 1)
+	go func() {
+		_, err := p.episodeGroup(2, provider.EpisodeOrderDVD, "en-US")
+		fetched <- err
+	}()
+	for groups.lists.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.episodeGroup(1, provider.EpisodeOrderDVD, "en-US")
+		p.episodeGroup(3, provider.EpisodeOrderDVD, "en-US")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("episodeGroup() waited on another show's fetch")
+	}
+
+	close(groups.block)
+	if err := <-fetched; err != nil {
+		t.Fatalf("episodeGroup(2) unexpected error: %v", err)
+	}
+	if got := groups.lists.Load(); got != 3 {
+		t.Errorf("GetTvEpisodeGroups called %d times, want 3 with show 1 cached", got)
+	}
+}
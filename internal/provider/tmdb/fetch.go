@@ -3,11 +3,11 @@ package tmdb
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
-	"github.com/patrickmn/go-cache"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
@@ -17,16 +17,9 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 		return nil, fmt.Errorf("provider not configured")
 	}
 
-	// Build cache key
-	cacheKey := p.buildCacheKey(request)
-
-	// Check cache first
-	if p.cache != nil {
-		if cached, found := p.cache.Get(cacheKey); found {
-			if meta, ok := cached.(*provider.Metadata); ok {
-				return meta, nil
-			}
-		}
+	// A TMDB ID another provider resolved makes the lookup exact
+	if request.ID == "" {
+		request.ID = request.KnownID("tmdb_id")
 	}
 
 	// Fetch based on media type
@@ -50,11 +43,6 @@ func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*p
 		return nil, err
 	}
 
-	// Cache the result
-	if p.cache != nil && metadata != nil {
-		p.cache.Set(cacheKey, metadata, cache.DefaultExpiration)
-	}
-
 	return metadata, nil
 }
 
@@ -67,9 +55,22 @@ func (p *Provider) fetchMovie(ctx context.Context, request provider.FetchRequest
 		options["year"] = request.Year
 	}
 
-	// Apply rate limiting
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
+	// An exact ID skips the search entirely
+	if id, err := strconv.Atoi(request.ID); err == nil {
+		options["append_to_response"] = p.movieAppend()
+		fullMovie, err := p.client.GetMovieInfo(id, options)
+		if err != nil {
+			return nil, p.mapError(err)
+		}
+		if fullMovie == nil {
+			return nil, &provider.ProviderError{
+				Provider: providerName,
+				Code:     "NOT_FOUND",
+				Message:  fmt.Sprintf("movie %d not found", id),
+				Retry:    false,
+			}
+		}
+		return p.movieToMetadata(fullMovie), nil
 	}
 
 	// Search for the movie
@@ -87,37 +88,60 @@ func (p *Provider) fetchMovie(ctx context.Context, request provider.FetchRequest
 		}
 	}
 
-	// Take the first result
-	movie := results.Results[0]
+	// Take the best scoring result
+	candidates := make([]provider.MatchCandidate, len(results.Results))
+	for i, result := range results.Results {
+		candidates[i] = provider.MatchCandidate{
+			Title:     result.Title,
+			Titles:    []string{result.OriginalTitle},
+			Year:      yearFromDate(result.ReleaseDate),
+			MediaType: provider.MediaTypeMovie,
+		}
+	}
+	best, score := provider.BestMatch(request.Name, request.Year, provider.MediaTypeMovie, candidates)
+	movie := results.Results[best]
 
 	// Always get full movie details for complete metadata
 	var fullMovie *tmdb.Movie
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
+	detailOptions := map[string]string{
+		"language":           options["language"],
+		"append_to_response": p.movieAppend(),
 	}
+	fullMovie, err = p.client.GetMovieInfo(movie.ID, detailOptions)
 
-	fullMovie, err = p.client.GetMovieInfo(movie.ID, options)
-	if err != nil {
+	var metadata *provider.Metadata
+	if err == nil && fullMovie != nil {
+		metadata = p.movieToMetadata(fullMovie)
+	} else {
 		// Fall back to search result data
-		return p.movieSearchResultToMetadata(&movie), nil
+		metadata = p.movieSearchResultToMetadata(&movie)
 	}
-
-	if fullMovie != nil {
-		return p.movieToMetadata(fullMovie), nil
-	}
-	return p.movieSearchResultToMetadata(&movie), nil
+	metadata.Confidence = score
+	return metadata, nil
 }
 
 // fetchShow fetches TV show metadata
 func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
 	options := map[string]string{
 		"language":           p.getLanguage(request),
-		"append_to_response": "external_ids",
+		"append_to_response": p.showAppend(),
 	}
 
-	// Apply rate limiting
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
+	// An exact ID skips the search entirely
+	if id, err := strconv.Atoi(request.ID); err == nil {
+		fullShow, err := p.client.GetTvInfo(id, options)
+		if err != nil {
+			return nil, p.mapError(err)
+		}
+		if fullShow == nil {
+			return nil, &provider.ProviderError{
+				Provider: providerName,
+				Code:     "NOT_FOUND",
+				Message:  fmt.Sprintf("show %d not found", id),
+				Retry:    false,
+			}
+		}
+		return p.withShowCertification(p.tvToMetadata(fullShow), id), nil
 	}
 
 	// Search for the show
@@ -135,31 +159,30 @@ func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest)
 		}
 	}
 
-	// Take the first result
-	show := results.Results[0]
+	// Take the best scoring result
+	best, score := provider.BestMatch(request.Name, request.Year, provider.MediaTypeShow, tvMatchCandidates(results))
+	show := results.Results[best]
 
 	// Always get full show details for complete metadata
 	var fullShow *tmdb.TV
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
-	}
-
 	fullShow, err = p.client.GetTvInfo(show.ID, options)
-	if err != nil {
-		// Fall back to search result data
-		return p.tvSearchResultToMetadata(&show), nil
-	}
 
-	if fullShow != nil {
-		return p.tvToMetadata(fullShow), nil
+	var metadata *provider.Metadata
+	if err == nil && fullShow != nil {
+		metadata = p.tvToMetadata(fullShow)
+	} else {
+		// Fall back to search result data
+		metadata = p.tvSearchResultToMetadata(&show)
 	}
-	return p.tvSearchResultToMetadata(&show), nil
+	p.withShowCertification(metadata, show.ID)
+	metadata.Confidence = score
+	return metadata, nil
 }
 
 // fetchSeason fetches season metadata
 func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
 	// First need to get show ID
-	showID, err := p.getShowID(ctx, request)
+	showID, score, err := p.getShowID(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -168,11 +191,6 @@ func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchReques
 		"language": p.getLanguage(request),
 	}
 
-	// Apply rate limiting
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
-	}
-
 	season, err := p.client.GetTvSeasonInfo(showID, request.Season, options)
 	if err != nil {
 		return nil, p.mapError(err)
@@ -187,13 +205,15 @@ func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchReques
 		}
 	}
 
-	return p.seasonToMetadata(season, showID), nil
+	metadata := p.seasonToMetadata(season, showID)
+	metadata.Confidence = score
+	return metadata, nil
 }
 
 // fetchEpisode fetches episode metadata
 func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
 	// First need to get show ID
-	showID, err := p.getShowID(ctx, request)
+	showID, score, err := p.getShowID(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -202,12 +222,26 @@ func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchReque
 		"language": p.getLanguage(request),
 	}
 
-	// Apply rate limiting
-	if err := p.rateLimiter.wait(); err != nil {
-		return nil, p.mapError(err)
+	// Alternate orders map the requested numbers to the aired episode
+	seasonNum, episodeNum := request.Season, request.Episode
+	ordered, err := p.orderedEpisodes(showID, request)
+	if err != nil {
+		return nil, err
+	}
+	if ordered != nil {
+		entry, ok := ordered[request.Episode]
+		if !ok {
+			return nil, &provider.ProviderError{
+				Provider: providerName,
+				Code:     "NOT_FOUND",
+				Message:  fmt.Sprintf("episode S%02dE%02d not found", request.Season, request.Episode),
+				Retry:    false,
+			}
+		}
+		seasonNum, episodeNum = entry.SeasonNumber, entry.EpisodeNumber
 	}
 
-	episode, err := p.client.GetTvEpisodeInfo(showID, request.Season, request.Episode, options)
+	episode, err := p.client.GetTvEpisodeInfo(showID, seasonNum, episodeNum, options)
 	if err != nil {
 		return nil, p.mapError(err)
 	}
@@ -222,25 +256,211 @@ func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchReque
 	}
 
 	// Also get show info for the series name (with external IDs)
-	var show *tmdb.TV
-	if err := p.rateLimiter.wait(); err == nil {
-		optionsWithExternal := map[string]string{
-			"language":           p.getLanguage(request),
-			"append_to_response": "external_ids",
+	show, certification := p.episodeShow(showID, request)
+
+	metadata := p.episodeToMetadata(episode, show, showID)
+	withCertification(metadata, certification)
+	metadata.Core.SeasonNum, metadata.Core.EpisodeNum = request.Season, request.Episode
+	metadata.Confidence = score
+	return metadata, nil
+}
+
+// FetchSeasonEpisodes fetches every episode of a season from the season
+// details, with one show lookup for the series name shared by all of them.
+func (p *Provider) FetchSeasonEpisodes(ctx context.Context, request provider.FetchRequest) (map[int]*provider.Metadata, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("provider not configured")
+	}
+
+	showID, score, err := p.getShowID(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered, err := p.orderedEpisodes(showID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Alternate orders take the season's episodes from the episode group
+	if ordered != nil {
+		show, certification := p.episodeShow(showID, request)
+		episodes := make(map[int]*provider.Metadata, len(ordered))
+		for num, entry := range ordered {
+			metadata := p.episodeToMetadata(&entry.TvEpisode, show, showID)
+			withCertification(metadata, certification)
+			metadata.Core.SeasonNum, metadata.Core.EpisodeNum = request.Season, num
+			metadata.Confidence = score
+			episodes[num] = metadata
 		}
-		show, _ = p.client.GetTvInfo(showID, optionsWithExternal)
+		return episodes, nil
 	}
 
-	return p.episodeToMetadata(episode, show, showID), nil
+	options := map[string]string{
+		"language": p.getLanguage(request),
+	}
+	season, err := p.client.GetTvSeasonInfo(showID, request.Season, options)
+	if err != nil {
+		return nil, p.mapError(err)
+	}
+	if season == nil {
+		return nil, &provider.ProviderError{
+			Provider: providerName,
+			Code:     "NOT_FOUND",
+			Message:  fmt.Sprintf("season %d not found", request.Season),
+			Retry:    false,
+		}
+	}
+
+	show, certification := p.episodeShow(showID, request)
+
+	episodes := make(map[int]*provider.Metadata, len(season.Episodes))
+	for i := range season.Episodes {
+		metadata := p.episodeToMetadata(&season.Episodes[i], show, showID)
+		withCertification(metadata, certification)
+		metadata.Confidence = score
+		episodes[season.Episodes[i].EpisodeNumber] = metadata
+	}
+	return episodes, nil
+}
+
+// SeasonEpisodeVariables lists every episode variable, as the season details
+// carry the same episode records as the episode endpoint.
+func (p *Provider) SeasonEpisodeVariables() []string {
+	var names []string
+	for _, v := range p.SupportedVariables() {
+		if slices.Contains(v.MediaTypes, provider.MediaTypeEpisode) {
+			names = append(names, v.Name)
+		}
+	}
+	return names
 }
 
-// getShowID gets the TMDB show ID from a request
-func (p *Provider) getShowID(ctx context.Context, request provider.FetchRequest) (int, error) {
+// ExactID returns the TMDB ID of the movie or show behind metadata from this
+// provider. Seasons and episodes report their show.
+func (p *Provider) ExactID(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	if id := meta.IDs["tmdb_show_id"]; id != "" {
+		return id
+	}
+	return meta.IDs["tmdb_id"]
+}
+
+// SearchCandidates lists the movie or show search results for a request,
+// best match first, so the user can choose one.
+func (p *Provider) SearchCandidates(ctx context.Context, request provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("provider not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	options := map[string]string{
+		"language": p.getLanguage(request),
+	}
+	var candidates []provider.Candidate
+	switch request.MediaType {
+	case provider.MediaTypeMovie:
+		if request.Year != "" {
+			options["year"] = request.Year
+		}
+		results, err := p.client.SearchMovie(request.Name, options)
+		if err != nil {
+			return nil, p.mapError(err)
+		}
+		if results == nil {
+			break
+		}
+		for _, result := range results.Results {
+			candidates = append(candidates, provider.Candidate{
+				ID:        strconv.Itoa(result.ID),
+				Title:     result.Title,
+				Year:      yearFromDate(result.ReleaseDate),
+				MediaType: provider.MediaTypeMovie,
+				Overview:  result.Overview,
+			})
+		}
+	case provider.MediaTypeShow:
+		results, err := p.searchShows(request.Name, options)
+		if err != nil {
+			return nil, p.mapError(err)
+		}
+		for _, result := range results {
+			candidates = append(candidates, provider.Candidate{
+				ID:        strconv.Itoa(result.ID),
+				Title:     result.Name,
+				Year:      yearFromDate(result.FirstAirDate),
+				MediaType: provider.MediaTypeShow,
+				Overview:  result.Overview,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", request.MediaType)
+	}
+
+	return provider.RankCandidates(request.Name, request.Year, request.MediaType, candidates, limit), nil
+}
+
+// ShowSearchClient searches TMDB for shows. go-tmdb's TV search results
+// leave out the overview the candidate list shows.
+type ShowSearchClient interface {
+	SearchTvShows(name string, options map[string]string) (*ShowSearchResults, error)
+}
+
+// ShowSearchResults is a page of TV search results.
+type ShowSearchResults struct {
+	Results []ShowSearchResult `json:"results"`
+}
+
+// ShowSearchResult is one show found by a TV search.
+type ShowSearchResult struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	FirstAirDate string `json:"first_air_date"`
+	Overview     string `json:"overview"`
+}
+
+func (c *httpClient) SearchTvShows(name string, options map[string]string) (*ShowSearchResults, error) {
+	var results ShowSearchResults
+	if err := c.search("/search/tv", name, options, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// searchShows runs a TV search through the show search client, falling
+// back to the TMDB client without overviews when there is none.
+func (p *Provider) searchShows(name string, options map[string]string) ([]ShowSearchResult, error) {
+	if p.shows != nil {
+		results, err := p.shows.SearchTvShows(name, options)
+		if err != nil || results == nil {
+			return nil, err
+		}
+		return results.Results, nil
+	}
+
+	results, err := p.client.SearchTv(name, options)
+	if err != nil || results == nil {
+		return nil, err
+	}
+	shows := make([]ShowSearchResult, 0, len(results.Results))
+	for _, result := range results.Results {
+		shows = append(shows, ShowSearchResult{ID: result.ID, Name: result.Name, FirstAirDate: result.FirstAirDate})
+	}
+	return shows, nil
+}
+
+// getShowID gets the TMDB show ID from a request along with the confidence
+// of the match. A provided ID is trusted completely.
+func (p *Provider) getShowID(ctx context.Context, request provider.FetchRequest) (int, float64, error) {
 	// Check if ID is provided
 	if request.ID != "" {
 		id, err := strconv.Atoi(request.ID)
 		if err == nil {
-			return id, nil
+			return id, 1.0, nil
 		}
 	}
 
@@ -249,17 +469,13 @@ func (p *Provider) getShowID(ctx context.Context, request provider.FetchRequest)
 		"language": p.getLanguage(request),
 	}
 
-	if err := p.rateLimiter.wait(); err != nil {
-		return 0, p.mapError(err)
-	}
-
 	results, err := p.client.SearchTv(request.Name, options)
 	if err != nil {
-		return 0, p.mapError(err)
+		return 0, 0, p.mapError(err)
 	}
 
 	if results == nil || len(results.Results) == 0 {
-		return 0, &provider.ProviderError{
+		return 0, 0, &provider.ProviderError{
 			Provider: providerName,
 			Code:     "NOT_FOUND",
 			Message:  fmt.Sprintf("show not found: %s", request.Name),
@@ -267,7 +483,8 @@ func (p *Provider) getShowID(ctx context.Context, request provider.FetchRequest)
 		}
 	}
 
-	return results.Results[0].ID, nil
+	best, score := provider.BestMatch(request.Name, request.Year, provider.MediaTypeShow, tvMatchCandidates(results))
+	return results.Results[best].ID, score, nil
 }
 
 // Conversion functions
@@ -278,7 +495,7 @@ func (p *Provider) movieSearchResultToMetadata(movie *tmdb.MovieShort) *provider
 		releaseYear = movie.ReleaseDate[:4]
 	}
 
-	return &provider.Metadata{
+	meta := &provider.Metadata{
 		Core: provider.CoreMetadata{
 			Title:     movie.Title,
 			Year:      releaseYear,
@@ -301,6 +518,10 @@ func (p *Provider) movieSearchResultToMetadata(movie *tmdb.MovieShort) *provider
 		},
 		Confidence: 0.8, // Lower confidence for search results
 	}
+
+	variants := provider.TitleVariants{Localized: movie.Title, Original: movie.OriginalTitle}
+	variants.Apply(meta, p.languagePriority)
+	return meta
 }
 
 func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
@@ -320,6 +541,9 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 		"tagline":    movie.Tagline,
 	}
 
+	if movie.Runtime > 0 {
+		extended["runtime"] = int(movie.Runtime)
+	}
 	if movie.Budget > 0 {
 		extended["budget"] = movie.Budget
 	}
@@ -329,6 +553,10 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 	if movie.Homepage != "" {
 		extended["homepage"] = movie.Homepage
 	}
+	if collection := movie.BelongsToCollection; collection.ID > 0 && collection.Name != "" {
+		extended["collection"] = collection.Name
+		extended["collection_id"] = fmt.Sprintf("%d", collection.ID)
+	}
 
 	// Add production companies
 	if len(movie.ProductionCompanies) > 0 {
@@ -337,7 +565,11 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 			companies = append(companies, c.Name)
 		}
 		extended["production_companies"] = strings.Join(companies, ", ")
-		extended["studios"] = companies[0] // First one as primary studio
+		extended["studio"] = companies[0] // First one as primary studio
+	}
+	applyMovieCredits(extended, movie.Credits)
+	if certification := p.movieCertification(movie.Releases); certification != "" {
+		extended["certification"] = certification
 	}
 
 	ids := map[string]string{
@@ -347,7 +579,7 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 		ids["imdb_id"] = movie.ImdbID
 	}
 
-	return &provider.Metadata{
+	meta := &provider.Metadata{
 		Core: provider.CoreMetadata{
 			Title:     movie.Title,
 			Year:      releaseYear,
@@ -355,6 +587,7 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 			Overview:  movie.Overview,
 			Rating:    movie.VoteAverage,
 			Genres:    genres,
+			Language:  movie.OriginalLanguage,
 		},
 		Extended: extended,
 		IDs:      ids,
@@ -368,6 +601,9 @@ func (p *Provider) movieToMetadata(movie *tmdb.Movie) *provider.Metadata {
 		},
 		Confidence: 1.0, // Full confidence for detailed results
 	}
+
+	p.movieTitleVariants(movie).Apply(meta, p.languagePriority)
+	return meta
 }
 
 func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata {
@@ -379,6 +615,7 @@ func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata
 	var popularity float32
 	var voteCount uint32
 	var originCountry []string
+	var originalName string
 
 	// Use type assertion to handle different types
 	switch s := show.(type) {
@@ -390,6 +627,7 @@ func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata
 		popularity = s.Popularity
 		voteCount = s.VoteCount
 		originCountry = s.OriginCountry
+		originalName = s.OriginalName
 	case *struct {
 		BackdropPath  string `json:"backdrop_path"`
 		ID            int
@@ -409,6 +647,7 @@ func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata
 		popularity = s.Popularity
 		voteCount = s.VoteCount
 		originCountry = s.OriginCountry
+		originalName = s.OriginalName
 	default:
 		// If we can't determine the type, return empty metadata
 		return &provider.Metadata{
@@ -422,7 +661,7 @@ func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata
 		firstAirYear = firstAirDate[:4]
 	}
 
-	return &provider.Metadata{
+	meta := &provider.Metadata{
 		Core: provider.CoreMetadata{
 			Title:     name,
 			Year:      firstAirYear,
@@ -444,6 +683,10 @@ func (p *Provider) tvSearchResultToMetadata(show interface{}) *provider.Metadata
 		},
 		Confidence: 0.8,
 	}
+
+	variants := provider.TitleVariants{Localized: name, Original: originalName}
+	variants.Apply(meta, p.languagePriority)
+	return meta
 }
 
 func (p *Provider) tvToMetadata(show *tmdb.TV) *provider.Metadata {
@@ -486,7 +729,9 @@ func (p *Provider) tvToMetadata(show *tmdb.TV) *provider.Metadata {
 			companies = append(companies, c.Name)
 		}
 		extended["production_companies"] = strings.Join(companies, ", ")
+		extended["studio"] = companies[0]
 	}
+	applyShowCredits(extended, show.Credits)
 
 	ids := map[string]string{
 		"tmdb_id": fmt.Sprintf("%d", show.ID),
@@ -497,7 +742,7 @@ func (p *Provider) tvToMetadata(show *tmdb.TV) *provider.Metadata {
 		ids["imdb_id"] = show.ExternalIDs.ImdbID
 	}
 
-	return &provider.Metadata{
+	meta := &provider.Metadata{
 		Core: provider.CoreMetadata{
 			Title:     show.Name,
 			Year:      firstAirYear,
@@ -505,6 +750,7 @@ func (p *Provider) tvToMetadata(show *tmdb.TV) *provider.Metadata {
 			Overview:  show.Overview,
 			Rating:    show.VoteAverage,
 			Genres:    genres,
+			Language:  show.OriginalLanguage,
 		},
 		Extended: extended,
 		IDs:      ids,
@@ -518,6 +764,9 @@ func (p *Provider) tvToMetadata(show *tmdb.TV) *provider.Metadata {
 		},
 		Confidence: 1.0,
 	}
+
+	p.tvTitleVariants(show).Apply(meta, p.languagePriority)
+	return meta
 }
 
 func (p *Provider) seasonToMetadata(season *tmdb.TvSeason, showID int) *provider.Metadata {
@@ -571,9 +820,12 @@ func (p *Provider) episodeToMetadata(episode *tmdb.TvEpisode, show *tmdb.TV, sho
 
 	if show != nil {
 		meta.Core.Title = show.Name
+		p.tvTitleVariants(show).Apply(meta, p.languagePriority)
 		if show.FirstAirDate != "" && len(show.FirstAirDate) >= 4 {
 			meta.Core.Year = show.FirstAirDate[:4]
 		}
+		// Episodes share the show's regular cast
+		applyShowCredits(meta.Extended, show.Credits)
 	}
 
 	// Add guest stars if available
@@ -598,7 +850,7 @@ func (p *Provider) episodeToMetadata(episode *tmdb.TvEpisode, show *tmdb.TV, sho
 			}
 		}
 		if len(directors) > 0 {
-			meta.Extended["directors"] = strings.Join(directors, ", ")
+			meta.Extended["director"] = strings.Join(directors, ", ")
 		}
 		if len(writers) > 0 {
 			meta.Extended["writers"] = strings.Join(writers, ", ")
@@ -610,16 +862,63 @@ func (p *Provider) episodeToMetadata(episode *tmdb.TvEpisode, show *tmdb.TV, sho
 
 // Helper functions
 
-func (p *Provider) buildCacheKey(request provider.FetchRequest) string {
-	parts := []string{
-		string(request.MediaType),
-		request.Name,
-		request.Year,
-		fmt.Sprintf("%d", request.Season),
-		fmt.Sprintf("%d", request.Episode),
-		request.Language,
+// movieTitleVariants gathers the original, translated and alternate titles
+// appended to a movie details response.
+func (p *Provider) movieTitleVariants(movie *tmdb.Movie) provider.TitleVariants {
+	variants := provider.TitleVariants{Localized: movie.Title, Original: movie.OriginalTitle}
+	if movie.Translations != nil {
+		for _, tr := range movie.Translations.Translations {
+			variants.AddTranslation(tr.Iso639_1+"-"+tr.Iso3166_1, tr.Data.Title)
+		}
+	}
+	// TMDB leaves the original language translation blank, so fill it in
+	variants.AddTranslation(movie.OriginalLanguage, movie.OriginalTitle)
+	if movie.AlternativeTitles != nil {
+		for _, alt := range movie.AlternativeTitles.Titles {
+			variants.AddAlternate(alt.Title)
+		}
+	}
+	return variants
+}
+
+// tvTitleVariants gathers the original, translated and alternate titles
+// appended to a TV details response.
+func (p *Provider) tvTitleVariants(show *tmdb.TV) provider.TitleVariants {
+	variants := provider.TitleVariants{Localized: show.Name, Original: show.OriginalName}
+	if show.Translations != nil {
+		for _, tr := range show.Translations.Translations {
+			variants.AddTranslation(tr.Iso639_1+"-"+tr.Iso3166_1, tr.Data.Name)
+		}
+	}
+	variants.AddTranslation(show.OriginalLanguage, show.OriginalName)
+	if show.AlternativeTitles != nil {
+		for _, alt := range show.AlternativeTitles.Results {
+			variants.AddAlternate(alt.Title)
+		}
+	}
+	return variants
+}
+
+// tvMatchCandidates converts TV search results into match candidates.
+func tvMatchCandidates(results *tmdb.TvSearchResults) []provider.MatchCandidate {
+	candidates := make([]provider.MatchCandidate, len(results.Results))
+	for i, result := range results.Results {
+		candidates[i] = provider.MatchCandidate{
+			Title:     result.Name,
+			Titles:    []string{result.OriginalName},
+			Year:      yearFromDate(result.FirstAirDate),
+			MediaType: provider.MediaTypeShow,
+		}
+	}
+	return candidates
+}
+
+// yearFromDate returns the year of a TMDB "YYYY-MM-DD" date.
+func yearFromDate(date string) string {
+	if len(date) >= 4 {
+		return date[:4]
 	}
-	return strings.Join(parts, ":")
+	return ""
 }
 
 func (p *Provider) getLanguage(request provider.FetchRequest) string {
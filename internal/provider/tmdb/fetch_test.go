@@ -0,0 +1,313 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+func TestMovieToMetadataCollection(t *testing.T) {
+	t.Parallel()
+
+	p := &Provider{}
+	movie := &tmdb.Movie{ID: 603, Title: "The Matrix", ReleaseDate: "1999-03-30"}
+	movie.BelongsToCollection = tmdb.CollectionShort{ID: 2344, Name: "The Matrix Collection"}
+
+	meta := p.movieToMetadata(movie)
+	if got := meta.Extended["collection"]; got != "The Matrix Collection" {
+		t.Errorf("collection = %v, want The Matrix Collection", got)
+	}
+	if got := meta.Extended["collection_id"]; got != "2344" {
+		t.Errorf("collection_id = %v, want 2344", got)
+	}
+
+	standalone := p.movieToMetadata(&tmdb.Movie{ID: 949, Title: "Heat", ReleaseDate: "1995-12-15"})
+	if _, ok := standalone.Extended["collection"]; ok {
+		t.Errorf("collection set for a standalone movie: %v", standalone.Extended["collection"])
+	}
+}
+
+func TestMovieToMetadataCredits(t *testing.T) {
+	t.Parallel()
+
+	p := &Provider{certificationCountry: "GB"}
+	var movie tmdb.Movie
+	body := `{
+		"id": 603,
+		"title": "The Matrix",
+		"release_date": "1999-03-30",
+		"production_companies": [{"id": 174, "name": "Warner Bros. Pictures"}],
+		"credits": {
+			"cast": [
+				{"name": "Carrie-Anne Moss", "order": 2},
+				{"name": "Keanu Reeves", "order": 0},
+				{"name": "Hugo Weaving", "order": 3},
+				{"name": "Laurence Fishburne", "order": 1}
+			],
+			"crew": [
+				{"name": "Lana Wachowski", "job": "Director"},
+				{"name": "Joel Silver", "job": "Producer"},
+				{"name": "Lilly Wachowski", "job": "Director"}
+			]
+		},
+		"releases": {
+			"countries": [
+				{"iso_3166_1": "US", "certification": "R"},
+				{"iso_3166_1": "GB", "certification": "15"}
+			]
+		}
+	}`
+	if err := json.Unmarshal([]byte(body), &movie); err != nil {
+		t.Fatalf("unmarshal movie: %v", err)
+	}
+
+	meta := p.movieToMetadata(&movie)
+	want := map[string]string{
+		"director":      "Lana Wachowski, Lilly Wachowski",
+		"cast":          "Keanu Reeves, Laurence Fishburne, Carrie-Anne Moss",
+		"studio":        "Warner Bros. Pictures",
+		"certification": "15",
+	}
+	for key, value := range want {
+		if got := meta.Extended[key]; got != value {
+			t.Errorf("%s = %v, want %s", key, got, value)
+		}
+	}
+}
+
+func TestMovieAppend(t *testing.T) {
+	t.Parallel()
+
+	if got := (&Provider{}).movieAppend(); got != "alternative_titles,translations" {
+		t.Errorf("movieAppend() = %q without credits or certification", got)
+	}
+	p := &Provider{credits: true, certificationCountry: "US"}
+	if got := p.movieAppend(); got != "alternative_titles,translations,credits,releases" {
+		t.Errorf("movieAppend() = %q, want credits and releases appended", got)
+	}
+}
+
+type fakeRatings map[int][]ContentRating
+
+func (f fakeRatings) GetTvContentRatings(showID int) (*ContentRatings, error) {
+	return &ContentRatings{Results: f[showID]}, nil
+}
+
+func TestShowCertification(t *testing.T) {
+	t.Parallel()
+
+	ratings := fakeRatings{1399: {{Country: "DE", Rating: "16"}, {Country: "US", Rating: "TV-MA"}}}
+	p := &Provider{certificationCountry: "US", ratings: ratings}
+	if got := p.showCertification(1399); got != "TV-MA" {
+		t.Errorf("showCertification() = %q, want TV-MA", got)
+	}
+
+	// No configured country means no extra request
+	p = &Provider{ratings: ratings}
+	if got := p.showCertification(1399); got != "" {
+		t.Errorf("showCertification() = %q without a country, want empty", got)
+	}
+}
+
+func TestEpisodeToMetadataDirector(t *testing.T) {
+	t.Parallel()
+
+	var episode tmdb.TvEpisode
+	body := `{"id": 63056, "name": "Winter Is Coming", "season_number": 1, "episode_number": 1,
+		"crew": [{"name": "Tim Van Patten", "job": "Director"}, {"name": "David Benioff", "job": "Writer"}]}`
+	if err := json.Unmarshal([]byte(body), &episode); err != nil {
+		t.Fatalf("unmarshal episode: %v", err)
+	}
+
+	meta := (&Provider{}).episodeToMetadata(&episode, nil, 1399)
+	if got := meta.Extended["director"]; got != "Tim Van Patten" {
+		t.Errorf("director = %v, want Tim Van Patten", got)
+	}
+}
+
+func TestFetchSeasonEpisodesShowCredits(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/tv/1399/season/1":
+			_, _ = w.Write([]byte(`{"season_number": 1, "episodes": [
+				{"id": 63056, "name": "Winter Is Coming", "season_number": 1, "episode_number": 1},
+				{"id": 63057, "name": "The Kingsroad", "season_number": 1, "episode_number": 2}]}`))
+		case "/tv/1399":
+			if got := r.URL.Query().Get("append_to_response"); !strings.Contains(got, "credits") {
+				t.Errorf("show append_to_response = %q, want credits", got)
+			}
+			_, _ = w.Write([]byte(`{"id": 1399, "name": "Game of Thrones", "credits": {"cast": [
+				{"name": "Kit Harington", "order": 1}, {"name": "Emilia Clarke", "order": 0}]}}`))
+		case "/tv/1399/content_ratings":
+			_, _ = w.Write([]byte(`{"results": [{"iso_3166_1": "US", "rating": "TV-MA"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	p := New()
+	if err := p.Configure(map[string]interface{}{"api_key": "key", "credits": true, "certification_country": "US"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	p.api.baseURL = srv.URL
+
+	episodes, err := p.FetchSeasonEpisodes(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeSeason, ID: "1399", Season: 1})
+	if err != nil {
+		t.Fatalf("FetchSeasonEpisodes() unexpected error: %v", err)
+	}
+	for num := 1; num <= 2; num++ {
+		meta := episodes[num]
+		if meta == nil {
+			t.Fatalf("episode %d missing", num)
+		}
+		if got := meta.Extended["cast"]; got != "Emilia Clarke, Kit Harington" {
+			t.Errorf("episode %d cast = %v, want the show's Emilia Clarke, Kit Harington", num, got)
+		}
+		if got := meta.Extended["certification"]; got != "TV-MA" {
+			t.Errorf("episode %d certification = %v, want the show's TV-MA", num, got)
+		}
+	}
+	// The show and its ratings are fetched once for the whole season
+	if len(requests) != 3 {
+		t.Errorf("made %d requests, want 3: %v", len(requests), requests)
+	}
+}
+
+// unusedClient panics if the TMDB client is called.
+type unusedClient struct {
+	TMDBClient
+}
+
+func TestSearchCandidatesShowOverview(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/tv" || r.URL.Query().Get("query") != "The Office" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results": [
+			{"id": 2316, "name": "The Office", "first_air_date": "2005-03-24", "overview": "A mockumentary on a group of office workers."},
+			{"id": 2996, "name": "The Office", "first_air_date": "2001-07-09", "overview": "The story of an office in Slough."}
+		]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	api := newHTTPClient("key", nil)
+	api.baseURL = srv.URL
+	p := &Provider{client: unusedClient{}, shows: api, language: "en-US"}
+
+	candidates, err := p.SearchCandidates(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "The Office"}, 5)
+	if err != nil {
+		t.Fatalf("SearchCandidates() unexpected error: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("SearchCandidates() returned %d candidates, want 2", len(candidates))
+	}
+	for _, c := range candidates {
+		if c.Overview == "" {
+			t.Errorf("candidate %s (%s) has no overview", c.ID, c.Year)
+		}
+	}
+}
+
+func TestRequestsWaitForRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/tv/1399/season/1/episode/1":
+			_, _ = w.Write([]byte(`{"id": 63056, "name": "Winter Is Coming", "season_number": 1, "episode_number": 1}`))
+		case "/tv/1399":
+			_, _ = w.Write([]byte(`{"id": 1399, "name": "Game of Thrones", "first_air_date": "2011-04-17"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	// One episode lookup takes two requests, so the second lookup has to
+	// wait for the window
+	window := 200 * time.Millisecond
+	p := New()
+	p.SetRateLimiter(provider.NewRateLimiter(2, window))
+	if err := p.Configure(map[string]interface{}{"api_key": "key"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	p.api.baseURL = srv.URL
+
+	request := provider.FetchRequest{MediaType: provider.MediaTypeEpisode, ID: "1399", Season: 1, Episode: 1}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		meta, err := p.Fetch(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Fetch() unexpected error: %v", err)
+		}
+		if meta.Core.EpisodeName != "Winter Is Coming" || meta.Core.Title != "Game of Thrones" {
+			t.Errorf("Fetch() = %q from %q, want Winter Is Coming from Game of Thrones", meta.Core.EpisodeName, meta.Core.Title)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < window {
+		t.Errorf("4 requests took %v, want the last two held for the %v window", elapsed, window)
+	}
+	if len(requests) != 4 {
+		t.Errorf("made %d requests, want 4: %v", len(requests), requests)
+	}
+}
+
+func TestFetchMapsHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		status         int
+		retryAfter     string
+		wantCode       string
+		wantRetryAfter int
+	}{
+		{name: "rate_limited", status: http.StatusTooManyRequests, retryAfter: "7", wantCode: "RATE_LIMITED", wantRetryAfter: 7},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantCode: "AUTH_FAILED"},
+		{name: "unavailable", status: http.StatusServiceUnavailable, wantCode: "UNAVAILABLE"},
+		{name: "server_error", status: http.StatusInternalServerError, wantCode: "UNKNOWN"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			t.Cleanup(srv.Close)
+
+			api := newHTTPClient("key", nil)
+			api.baseURL = srv.URL
+			p := &Provider{client: api, api: api, language: "en-US"}
+
+			// The title holds digits that look like other status codes
+			_, err := p.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeMovie, Name: "Room 401 503"})
+			var provErr *provider.ProviderError
+			if !errors.As(err, &provErr) || provErr.Code != tc.wantCode || provErr.RetryAfter != tc.wantRetryAfter {
+				t.Errorf("Fetch() error = %#v, want %s after %ds", err, tc.wantCode, tc.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -2,13 +2,9 @@ package tmdb
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/provider"
-	"github.com/patrickmn/go-cache"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
@@ -18,16 +14,28 @@ const (
 
 // Provider implements the provider.Provider interface for TMDB
 type Provider struct {
-	client      TMDBClient
-	cache       *cache.Cache
-	cacheFile   string
-	language    string
-	apiKey      string
-	rateLimiter *rateLimiter
-	config      map[string]interface{}
+	client           TMDBClient
+	language         string
+	languagePriority []string
+	apiKey           string
+	config           map[string]interface{}
+	episodeOrders    provider.EpisodeOrders
+	groups           EpisodeGroupClient
+	groupCache       episodeGroups
+	shows            ShowSearchClient
+
+	// credits and certificationCountry are set when templates use {director},
+	// {cast} or {certification}, so the extra data is only fetched then
+	credits              bool
+	certificationCountry string
+	ratings              ContentRatingClient
+
+	// api makes every request, waiting on limiter before each one
+	api     *httpClient
+	limiter *provider.RateLimiter
 }
 
-// TMDBClient interface for testing (matches *tmdb.TMDb exactly)
+// TMDBClient interface for testing (matches the *tmdb.TMDb methods used)
 type TMDBClient interface {
 	SearchMovie(name string, options map[string]string) (*tmdb.MovieSearchResults, error)
 	SearchTv(name string, options map[string]string) (*tmdb.TvSearchResults, error)
@@ -100,6 +108,15 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 		},
 
 		// Production Information
+		{
+			Name:        "studio",
+			DisplayName: "Studio",
+			Description: "Primary production company",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "Warner Bros. Pictures",
+			Category:    "production",
+			Provider:    providerName,
+		},
 		{
 			Name:        "networks",
 			DisplayName: "Networks",
@@ -111,6 +128,39 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Provider:    providerName,
 		},
 
+		// Content Rating
+		{
+			Name:        "certification",
+			DisplayName: "Certification",
+			Description: "Content rating in the configured country",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "R",
+			Category:    "ratings",
+			Provider:    providerName,
+		},
+
+		// Cast and Crew
+		{
+			Name:        "director",
+			DisplayName: "Director",
+			Description: "Directors of the movie or episode",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeEpisode},
+			Example:     "Lana Wachowski, Lilly Wachowski",
+			Category:    "credits",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "cast",
+			DisplayName: "Cast",
+			Description: "Top billed actors of the movie or show",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "Keanu Reeves, Laurence Fishburne, Carrie-Anne Moss",
+			Category:    "credits",
+			Format:      "list",
+			Provider:    providerName,
+		},
+
 		// TV-Specific
 		{
 			Name:        "episode_title",
@@ -122,6 +172,36 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Provider:    providerName,
 		},
 
+		// Titles
+		{
+			Name:        "original_title",
+			DisplayName: "Original Title",
+			Description: "Title in the original language",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "Das Boot",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "localized_title",
+			DisplayName: "Localized Title",
+			Description: "Title in the first available preferred language",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "The Boat",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "alternate_titles",
+			DisplayName: "Alternate Titles",
+			Description: "Other known titles",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "U-96, Boat",
+			Category:    "basic",
+			Format:      "list",
+			Provider:    providerName,
+		},
+
 		// Marketing
 		{
 			Name:        "tagline",
@@ -133,6 +213,26 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Provider:    providerName,
 		},
 
+		// Collections
+		{
+			Name:        "collection",
+			DisplayName: "Collection",
+			Description: "Collection or franchise the movie belongs to",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie},
+			Example:     "The Matrix Collection",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "collection_id",
+			DisplayName: "Collection ID",
+			Description: "The Movie Database ID of the collection",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie},
+			Example:     "2344",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+
 		// Identifiers
 		{
 			Name:        "imdb_id",
@@ -186,26 +286,20 @@ func (p *Provider) ConfigSchema() provider.ConfigSchema {
 				},
 			},
 			{
-				Name:        "cache_enabled",
-				DisplayName: "Enable Cache",
-				Type:        provider.ConfigFieldTypeBool,
+				Name:        "language_priority",
+				DisplayName: "Title Languages",
+				Type:        provider.ConfigFieldTypeList,
 				Required:    false,
-				Default:     true,
-				Description: "Cache API responses to reduce requests",
+				Description: "Ordered title languages for {title}; use \"original\" for the original title",
 			},
 			{
-				Name:        "cache_duration",
-				DisplayName: "Cache Duration (hours)",
-				Type:        provider.ConfigFieldTypeInt,
+				Name:        "certification_country",
+				DisplayName: "Certification Country",
+				Type:        provider.ConfigFieldTypeString,
 				Required:    false,
-				Default:     168, // 7 days
-				Description: "How long to cache metadata",
-				DependsOn:   "cache_enabled",
-				Validation: &provider.ConfigFieldValidation{
-					MinValue: 1,
-					MaxValue: 8760, // 1 year
-				},
+				Description: "ISO 3166-1 country code whose content rating fills {certification}, e.g. US",
 			},
+			provider.EpisodeOrderField(),
 		},
 	}
 }
@@ -227,92 +321,46 @@ func (p *Provider) Configure(config map[string]interface{}) error {
 	} else {
 		p.language = "en-US"
 	}
+	p.languagePriority = provider.ParseLanguagePriority(config["language_priority"])
 
-	// Initialize TMDB client
-	tmdbConfig := tmdb.Config{
-		APIKey:   p.apiKey,
-		Proxies:  nil,
-		UseProxy: false,
-	}
-	p.client = tmdb.Init(tmdbConfig)
-
-	// Set up cache if enabled
-	cacheEnabled := true
-	if enabled, ok := config["cache_enabled"].(bool); ok {
-		cacheEnabled = enabled
-	}
+	p.credits, _ = config["credits"].(bool)
+	country, _ := config["certification_country"].(string)
+	p.certificationCountry = strings.ToUpper(strings.TrimSpace(country))
 
-	if cacheEnabled {
-		cacheDuration := 168 // Default 7 days
-		if duration, ok := config["cache_duration"].(int); ok {
-			cacheDuration = duration
-		}
-
-		// Set up cache file path
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			cacheDir := filepath.Join(homeDir, ".title-tidy", "tmdb_cache")
-			os.MkdirAll(cacheDir, 0755)
-			p.cacheFile = filepath.Join(cacheDir, "tmdb_cache.gob")
-
-			// Create cache with configured expiration
-			p.cache = cache.New(time.Duration(cacheDuration)*time.Hour, 10*time.Minute)
-
-			// Try to load existing cache from disk
-			if _, err := os.Stat(p.cacheFile); err == nil {
-				_ = p.cache.LoadFile(p.cacheFile)
-			}
-		}
+	orders, err := provider.ParseEpisodeOrders(config)
+	if err != nil {
+		return err
 	}
+	p.episodeOrders = orders
 
-	// Initialize rate limiter
-	p.rateLimiter = newRateLimiter(38, 10*time.Second) // 38 requests per 10 seconds
+	api := newHTTPClient(p.apiKey, p.limiter)
+	p.api = api
+	p.client = api
+	p.groups = api
+	p.ratings = api
+	p.shows = api
+	p.groupCache = episodeGroups{}
 
 	return nil
 }
 
-// SaveCache persists the cache to disk
-func (p *Provider) SaveCache() error {
-	if p.cache != nil && p.cacheFile != "" {
-		return p.cache.SaveFile(p.cacheFile)
+// SetRateLimiter makes every request to TMDB wait on limiter. A single
+// lookup can take several requests, and TMDB limits requests.
+func (p *Provider) SetRateLimiter(limiter *provider.RateLimiter) {
+	p.limiter = limiter
+	if p.api != nil {
+		p.api.limiter = limiter
 	}
-	return nil
 }
 
-// mapError maps TMDB errors to provider errors
+// mapError maps TMDB errors to provider errors by their HTTP status.
 func (p *Provider) mapError(err error) error {
 	if err == nil {
 		return nil
 	}
-
-	errStr := strings.ToLower(err.Error())
-	if strings.Contains(errStr, "401") || strings.Contains(errStr, "unauthorized") {
-		return &provider.ProviderError{
-			Provider: providerName,
-			Code:     "AUTH_FAILED",
-			Message:  "TMDB authentication failed: " + err.Error(),
-			Retry:    false,
-		}
+	if provErr := provider.MapStatusError(providerName, "TMDB", err); provErr != nil {
+		return provErr
 	}
-	if strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit") {
-		return &provider.ProviderError{
-			Provider:   providerName,
-			Code:       "RATE_LIMITED",
-			Message:    "TMDB rate limit exceeded",
-			Retry:      true,
-			RetryAfter: 10,
-		}
-	}
-	if strings.Contains(errStr, "503") || strings.Contains(errStr, "unavailable") {
-		return &provider.ProviderError{
-			Provider:   providerName,
-			Code:       "UNAVAILABLE",
-			Message:    "TMDB service unavailable",
-			Retry:      true,
-			RetryAfter: 30,
-		}
-	}
-
 	return &provider.ProviderError{
 		Provider: providerName,
 		Code:     "UNKNOWN",
@@ -0,0 +1,141 @@
+package tvdb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/dashotv/tvdb/openapi/models/shared"
+)
+
+// castLimit is how many top-billed actors fill {cast}.
+const castLimit = 3
+
+// TVDB primary company types
+const (
+	companyTypeStudio     = 2
+	companyTypeProduction = 3
+)
+
+// alpha3Countries maps ISO 3166-1 alpha-2 codes to the lowercase alpha-3
+// codes TVDB uses for content ratings.
+var alpha3Countries = map[string]string{
+	"AR": "arg", "AT": "aut", "AU": "aus", "BE": "bel", "BR": "bra",
+	"CA": "can", "CH": "che", "CN": "chn", "CZ": "cze", "DE": "deu",
+	"DK": "dnk", "ES": "esp", "FI": "fin", "FR": "fra", "GB": "gbr",
+	"HK": "hkg", "HU": "hun", "IE": "irl", "IN": "ind", "IT": "ita",
+	"JP": "jpn", "KR": "kor", "MX": "mex", "NL": "nld", "NO": "nor",
+	"NZ": "nzl", "PL": "pol", "PT": "prt", "RU": "rus", "SE": "swe",
+	"SG": "sgp", "TR": "tur", "TW": "twn", "US": "usa", "ZA": "zaf",
+}
+
+// ratingCountry converts a configured country to TVDB's code. Alpha-3 codes
+// pass through unchanged.
+func ratingCountry(country string) string {
+	country = strings.TrimSpace(country)
+	if code, ok := alpha3Countries[strings.ToUpper(country)]; ok {
+		return code
+	}
+	return strings.ToLower(country)
+}
+
+// applyCredits adds {director} and {cast} from a record's characters.
+func applyCredits(metadata *provider.Metadata, characters []shared.Character) {
+	if directors := peopleNames(characters, "Director", 0); directors != "" {
+		metadata.Extended["director"] = directors
+		metadata.Sources["director"] = providerName
+	}
+	if cast := peopleNames(characters, "Actor", castLimit); cast != "" {
+		metadata.Extended["cast"] = cast
+		metadata.Sources["cast"] = providerName
+	}
+}
+
+// peopleNames lists the people of one type in sort order, up to limit
+// names when limit is positive.
+func peopleNames(characters []shared.Character, peopleType string, limit int) string {
+	matches := []shared.Character{}
+	for _, c := range characters {
+		if pointerToString(c.PeopleType) == peopleType && strings.TrimSpace(pointerToString(c.PersonName)) != "" {
+			matches = append(matches, c)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return pointerToInt64(matches[i].Sort) < pointerToInt64(matches[j].Sort)
+	})
+
+	names := []string{}
+	for _, c := range matches {
+		name := strings.TrimSpace(pointerToString(c.PersonName))
+		if stringSliceContains(names, name) {
+			continue
+		}
+		names = append(names, name)
+		if limit > 0 && len(names) == limit {
+			break
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyCertification adds {certification} for the configured country.
+func (p *Provider) applyCertification(metadata *provider.Metadata, ratings []shared.ContentRating) {
+	if p.certificationCountry == "" {
+		return
+	}
+	country := ratingCountry(p.certificationCountry)
+	for _, r := range ratings {
+		if strings.EqualFold(pointerToString(r.Country), country) {
+			if name := strings.TrimSpace(pointerToString(r.Name)); name != "" {
+				metadata.Extended["certification"] = name
+				metadata.Sources["certification"] = providerName
+				return
+			}
+		}
+	}
+}
+
+// applyStudio adds {studio} when a studio name was found.
+func applyStudio(metadata *provider.Metadata, studio string) {
+	if studio = strings.TrimSpace(studio); studio != "" {
+		metadata.Extended["studio"] = studio
+		metadata.Sources["studio"] = providerName
+	}
+}
+
+// movieStudio picks a movie's primary studio, falling back to its first
+// production company.
+func movieStudio(movie *shared.MovieExtendedRecord) string {
+	for _, s := range movie.Studios {
+		if name := pointerToString(s.Name); strings.TrimSpace(name) != "" {
+			return name
+		}
+	}
+	if movie.Companies == nil {
+		return ""
+	}
+	for _, list := range [][]shared.Company{movie.Companies.Studio, movie.Companies.Production} {
+		for _, c := range list {
+			if name := pointerToString(c.Name); strings.TrimSpace(name) != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// seriesStudio picks a series' primary studio, falling back to its first
+// production company.
+func seriesStudio(companies []shared.Company) string {
+	for _, companyType := range []int64{companyTypeStudio, companyTypeProduction} {
+		for _, c := range companies {
+			if pointerToInt64(c.PrimaryCompanyType) != companyType {
+				continue
+			}
+			if name := pointerToString(c.Name); strings.TrimSpace(name) != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package tvdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/Digital-Shane/title-tidy/internal/provider"
 	tvdbapi "github.com/dashotv/tvdb"
 	"github.com/dashotv/tvdb/openapi/models/operations"
+	"github.com/dashotv/tvdb/openapi/models/sdkerrors"
 	"github.com/dashotv/tvdb/openapi/models/shared"
 )
 
@@ -20,13 +22,19 @@ type TVDBClient interface {
 	GetSeriesExtended(id float64, meta *operations.GetSeriesExtendedQueryParamMeta, short *bool) (*tvdbapi.GetSeriesExtendedResponse, error)
 	GetMovieExtended(id float64, meta *operations.QueryParamMeta, short *bool) (*tvdbapi.GetMovieExtendedResponse, error)
 	GetSeriesEpisodes(request operations.GetSeriesEpisodesRequest) (*tvdbapi.GetSeriesEpisodesResponse, error)
+	GetSearchResultsByRemoteID(remoteID string) (*tvdbapi.GetSearchResultsByRemoteIDResponse, error)
 }
 
 // Provider implements the provider.Provider interface for TVDB.
 type Provider struct {
-	client TVDBClient
-	apiKey string
-	config map[string]interface{}
+	client           TVDBClient
+	apiKey           string
+	languagePriority []string
+	episodeOrders    provider.EpisodeOrders
+	config           map[string]interface{}
+
+	// certificationCountry is set when templates use {certification}
+	certificationCountry string
 }
 
 // New creates a new TVDB provider instance.
@@ -91,6 +99,44 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Format:      "list",
 			Provider:    providerName,
 		},
+		{
+			Name:        "studio",
+			DisplayName: "Studio",
+			Description: "Primary studio or production company",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "Warner Bros. Pictures",
+			Category:    "production",
+			Provider:    providerName,
+		},
+		{
+			Name:        "certification",
+			DisplayName: "Certification",
+			Description: "Content rating in the configured country",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "R",
+			Category:    "ratings",
+			Provider:    providerName,
+		},
+		{
+			Name:        "director",
+			DisplayName: "Director",
+			Description: "Directors of the movie or show",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "Lana Wachowski, Lilly Wachowski",
+			Category:    "credits",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "cast",
+			DisplayName: "Cast",
+			Description: "Top billed actors",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "Keanu Reeves, Laurence Fishburne, Carrie-Anne Moss",
+			Category:    "credits",
+			Format:      "list",
+			Provider:    providerName,
+		},
 		{
 			Name:        "episode_title",
 			DisplayName: "Episode Title",
@@ -100,6 +146,34 @@ func (p *Provider) SupportedVariables() []provider.TemplateVariable {
 			Category:    "basic",
 			Provider:    providerName,
 		},
+		{
+			Name:        "original_title",
+			DisplayName: "Original Title",
+			Description: "Title in the original language",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "Das Boot",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "localized_title",
+			DisplayName: "Localized Title",
+			Description: "Title in the first available preferred language",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "The Boat",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "alternate_titles",
+			DisplayName: "Alternate Titles",
+			Description: "Other known titles",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeMovie, provider.MediaTypeShow},
+			Example:     "U-96, Boat",
+			Category:    "basic",
+			Format:      "list",
+			Provider:    providerName,
+		},
 		{
 			Name:        "imdb_id",
 			DisplayName: "IMDB ID",
@@ -129,6 +203,21 @@ func (p *Provider) ConfigSchema() provider.ConfigSchema {
 					Pattern:   "^[A-Za-z0-9]+$",
 				},
 			},
+			{
+				Name:        "language_priority",
+				DisplayName: "Title Languages",
+				Type:        provider.ConfigFieldTypeList,
+				Required:    false,
+				Description: "Ordered title languages for {title}; use \"original\" for the original title",
+			},
+			{
+				Name:        "certification_country",
+				DisplayName: "Certification Country",
+				Type:        provider.ConfigFieldTypeString,
+				Required:    false,
+				Description: "ISO 3166-1 country code whose content rating fills {certification}, e.g. US",
+			},
+			provider.EpisodeOrderField(),
 		},
 	}
 }
@@ -145,12 +234,20 @@ func (p *Provider) Configure(config map[string]interface{}) error {
 		return fmt.Errorf("api_key is required")
 	}
 
+	orders, err := provider.ParseEpisodeOrders(config)
+	if err != nil {
+		return err
+	}
+
 	client, err := tvdbapi.Login(apiKey)
 	if err != nil {
 		return p.mapError(err)
 	}
 
 	p.apiKey = apiKey
+	p.episodeOrders = orders
+	p.languagePriority = provider.ParseLanguagePriority(config["language_priority"])
+	p.certificationCountry, _ = config["certification_country"].(string)
 	p.config = config
 	p.client = client
 
@@ -214,15 +311,22 @@ func (p *Provider) fetchMovie(request provider.FetchRequest) (*provider.Metadata
 		},
 		Extended:   make(map[string]interface{}),
 		Sources:    make(map[string]string),
-		IDs:        make(map[string]string),
-		Confidence: 0.9,
+		IDs:        map[string]string{"tvdb_id": strconv.FormatInt(record.ID, 10)},
+		Confidence: record.Score,
 	}
+	metadata.Sources["tvdb_id"] = providerName
+
+	titleVariants(metadata.Core.Title, pointerToString(movie.OriginalLanguage), movie.Translations, movie.Aliases).Apply(metadata, p.languagePriority)
 
 	if runtime := pointerToInt64(movie.Runtime); runtime > 0 {
 		metadata.Extended["runtime"] = int(runtime)
 		metadata.Sources["runtime"] = providerName
 	}
 
+	applyStudio(metadata, movieStudio(movie))
+	applyCredits(metadata, movie.Characters)
+	p.applyCertification(metadata, movie.ContentRatings)
+
 	if imdbID := findRemoteID(movie.RemoteIds, "imdb"); imdbID != "" {
 		metadata.IDs["imdb_id"] = imdbID
 		metadata.Sources["imdb_id"] = providerName
@@ -291,9 +395,12 @@ func (p *Provider) fetchShow(request provider.FetchRequest) (*provider.Metadata,
 		},
 		Extended:   make(map[string]interface{}),
 		Sources:    make(map[string]string),
-		IDs:        make(map[string]string),
-		Confidence: 0.9,
+		IDs:        map[string]string{"tvdb_id": strconv.FormatInt(record.ID, 10)},
+		Confidence: record.Score,
 	}
+	metadata.Sources["tvdb_id"] = providerName
+
+	titleVariants(metadata.Core.Title, metadata.Core.Language, series.Translations, series.Aliases).Apply(metadata, p.languagePriority)
 
 	if len(networks) > 0 {
 		metadata.Extended["networks"] = strings.Join(networks, ", ")
@@ -303,6 +410,9 @@ func (p *Provider) fetchShow(request provider.FetchRequest) (*provider.Metadata,
 		metadata.Extended["runtime"] = int(runtime)
 		metadata.Sources["runtime"] = providerName
 	}
+	applyStudio(metadata, seriesStudio(series.Companies))
+	applyCredits(metadata, series.Characters)
+	p.applyCertification(metadata, series.ContentRatings)
 	if imdbID := findRemoteID(series.RemoteIds, "imdb"); imdbID != "" {
 		metadata.IDs["imdb_id"] = imdbID
 		metadata.Sources["imdb_id"] = providerName
@@ -340,7 +450,7 @@ func (p *Provider) fetchSeason(request provider.FetchRequest) (*provider.Metadat
 	seasonNum := int64(request.Season)
 	episodes, err := p.client.GetSeriesEpisodes(operations.GetSeriesEpisodesRequest{
 		ID:         float64(record.ID),
-		SeasonType: "official",
+		SeasonType: seasonType(p.episodeOrders.For(request.ShowName(), request.Name)),
 		Season:     &seasonNum,
 		Page:       0,
 	})
@@ -365,9 +475,10 @@ func (p *Provider) fetchSeason(request provider.FetchRequest) (*provider.Metadat
 		},
 		Extended:   make(map[string]interface{}),
 		Sources:    make(map[string]string),
-		IDs:        make(map[string]string),
-		Confidence: 0.8,
+		IDs:        map[string]string{"tvdb_show_id": strconv.FormatInt(record.ID, 10)},
+		Confidence: record.Score,
 	}
+	metadata.Sources["tvdb_show_id"] = providerName
 
 	metadata.Extended["episode_count"] = len(episodes.Data.Episodes)
 	if title != "" {
@@ -377,8 +488,11 @@ func (p *Provider) fetchSeason(request provider.FetchRequest) (*provider.Metadat
 		metadata.Sources["year"] = providerName
 	}
 
-	seriesExt, extErr := p.client.GetSeriesExtended(float64(record.ID), nil, nil)
+	extMeta := operations.GetSeriesExtendedQueryParamMetaTranslations
+	seriesExt, extErr := p.client.GetSeriesExtended(float64(record.ID), &extMeta, nil)
 	if extErr == nil && seriesExt != nil && seriesExt.Data != nil {
+		series := seriesExt.Data
+		titleVariants(firstNonEmptyString(pointerToString(series.Name), title), pointerToString(series.OriginalLanguage), series.Translations, series.Aliases).Apply(metadata, p.languagePriority)
 		if imdbID := findRemoteID(seriesExt.Data.RemoteIds, "imdb"); imdbID != "" {
 			metadata.IDs["imdb_id"] = imdbID
 			metadata.Sources["imdb_id"] = providerName
@@ -398,15 +512,21 @@ func (p *Provider) fetchEpisode(request provider.FetchRequest) (*provider.Metada
 		return nil, err
 	}
 
+	order := p.episodeOrders.For(request.ShowName(), request.Name)
 	seasonNum := int64(request.Season)
 	episodeNum := int64(request.Episode)
-	episodes, err := p.client.GetSeriesEpisodes(operations.GetSeriesEpisodesRequest{
+	episodesRequest := operations.GetSeriesEpisodesRequest{
 		ID:            float64(record.ID),
-		SeasonType:    "official",
+		SeasonType:    seasonType(order),
 		Season:        &seasonNum,
 		EpisodeNumber: &episodeNum,
 		Page:          0,
-	})
+	}
+	if order == provider.EpisodeOrderAbsolute {
+		// Absolute numbers run across seasons
+		episodesRequest.Season = nil
+	}
+	episodes, err := p.client.GetSeriesEpisodes(episodesRequest)
 	if err != nil {
 		return nil, p.mapError(err)
 	}
@@ -443,9 +563,10 @@ func (p *Provider) fetchEpisode(request provider.FetchRequest) (*provider.Metada
 		},
 		Extended:   make(map[string]interface{}),
 		Sources:    make(map[string]string),
-		IDs:        make(map[string]string),
-		Confidence: 0.85,
+		IDs:        map[string]string{"tvdb_show_id": strconv.FormatInt(record.ID, 10)},
+		Confidence: record.Score,
 	}
+	metadata.Sources["tvdb_show_id"] = providerName
 
 	if runtime := pointerToInt64(episode.Runtime); runtime > 0 {
 		metadata.Extended["runtime"] = int(runtime)
@@ -464,8 +585,11 @@ func (p *Provider) fetchEpisode(request provider.FetchRequest) (*provider.Metada
 		metadata.Sources["year"] = providerName
 	}
 
-	seriesExt, extErr := p.client.GetSeriesExtended(float64(record.ID), nil, nil)
+	extMeta := operations.GetSeriesExtendedQueryParamMetaTranslations
+	seriesExt, extErr := p.client.GetSeriesExtended(float64(record.ID), &extMeta, nil)
 	if extErr == nil && seriesExt != nil && seriesExt.Data != nil {
+		series := seriesExt.Data
+		titleVariants(firstNonEmptyString(pointerToString(series.Name), title), pointerToString(series.OriginalLanguage), series.Translations, series.Aliases).Apply(metadata, p.languagePriority)
 		metadata.Core.Rating = pointerToFloat32(seriesExt.Data.Score)
 		if metadata.Core.Rating > 0 {
 			metadata.Sources["rating"] = providerName
@@ -479,13 +603,37 @@ func (p *Provider) fetchEpisode(request provider.FetchRequest) (*provider.Metada
 	return metadata, nil
 }
 
+// seasonType returns the TVDB season type that numbers episodes in order.
+func seasonType(order provider.EpisodeOrder) string {
+	switch order {
+	case provider.EpisodeOrderDVD:
+		return "dvd"
+	case provider.EpisodeOrderAbsolute:
+		return "absolute"
+	case provider.EpisodeOrderStory:
+		return "alternate"
+	default:
+		return "official"
+	}
+}
+
 type searchRecord struct {
-	ID   int64
-	Name string
-	Year string
+	ID    int64
+	Name  string
+	Year  string
+	Score float64
 }
 
 func (p *Provider) searchSeriesRecord(request provider.FetchRequest) (*searchRecord, error) {
+	if id, ok := exactRecordID(request.ID, "series"); ok {
+		return &searchRecord{ID: id, Name: request.Name, Year: request.Year, Score: 1.0}, nil
+	}
+	if request.ID == "" {
+		if record := p.remoteRecord(request, "series"); record != nil {
+			return record, nil
+		}
+	}
+
 	query := strings.TrimSpace(request.Name)
 	if request.ID != "" {
 		query = strings.TrimSpace(request.ID)
@@ -510,20 +658,27 @@ func (p *Provider) searchSeriesRecord(request provider.FetchRequest) (*searchRec
 		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("no results found for show: %s", request.Name), Retry: false}
 	}
 
-	for _, candidate := range resp.Data {
-		r := toSearchRecord(candidate)
-		if r.ID == 0 {
-			continue
-		}
-		if strings.EqualFold(pointerToString(candidate.Type), "series") {
-			return r, nil
-		}
+	record := bestSearchRecord(resp.Data, "series", request.Name, request.Year, provider.MediaTypeShow)
+	if record == nil {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: "series not found", Retry: false}
 	}
-
-	return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: "series not found", Retry: false}
+	if request.ID != "" {
+		// Lookups by ID are exact, so the title comparison is meaningless
+		record.Score = 1.0
+	}
+	return record, nil
 }
 
 func (p *Provider) searchMovieRecord(request provider.FetchRequest) (*searchRecord, error) {
+	if id, ok := exactRecordID(request.ID, "movie"); ok {
+		return &searchRecord{ID: id, Name: request.Name, Year: request.Year, Score: 1.0}, nil
+	}
+	if request.ID == "" {
+		if record := p.remoteRecord(request, "movie"); record != nil {
+			return record, nil
+		}
+	}
+
 	query := strings.TrimSpace(request.Name)
 	if query == "" {
 		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "movie fetch requires a title", Retry: false}
@@ -545,17 +700,162 @@ func (p *Provider) searchMovieRecord(request provider.FetchRequest) (*searchReco
 		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("no results found for movie: %s", request.Name), Retry: false}
 	}
 
-	for _, candidate := range resp.Data {
-		r := toSearchRecord(candidate)
-		if r.ID == 0 {
+	record := bestSearchRecord(resp.Data, "movie", request.Name, request.Year, provider.MediaTypeMovie)
+	if record == nil {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: "movie not found", Retry: false}
+	}
+	return record, nil
+}
+
+// remoteRecord looks the request up by the IMDb or TMDB ID another provider
+// resolved. It returns nil when no ID is known or TVDB has no record of the
+// wanted type for it, leaving the caller to search by name. IMDb IDs are
+// unique, but a bare TMDB number is shared by a movie and a show and may be
+// another site's ID too, so it only counts when it matches one record of the
+// wanted type.
+func (p *Provider) remoteRecord(request provider.FetchRequest, recordType string) *searchRecord {
+	for _, key := range []string{"imdb_id", "tmdb_id"} {
+		remoteID := request.KnownID(key)
+		if remoteID == "" {
+			continue
+		}
+		resp, err := p.client.GetSearchResultsByRemoteID(remoteID)
+		if err != nil || resp == nil {
+			continue
+		}
+		var records []searchRecord
+		for _, result := range resp.Data {
+			switch {
+			case recordType == "series" && result.Series != nil && result.Series.ID != nil:
+				records = append(records, searchRecord{ID: *result.Series.ID, Name: pointerToString(result.Series.Name), Year: pointerToString(result.Series.Year), Score: 1.0})
+			case recordType == "movie" && result.Movie != nil && result.Movie.ID != nil:
+				records = append(records, searchRecord{ID: *result.Movie.ID, Name: pointerToString(result.Movie.Name), Year: pointerToString(result.Movie.Year), Score: 1.0})
+			}
+		}
+		if len(records) == 1 || (len(records) > 1 && key == "imdb_id") {
+			return &records[0]
+		}
+	}
+	return nil
+}
+
+// ExactID returns the TVDB record ID ("series-<id>" or "movie-<id>") behind
+// metadata from this provider. Seasons and episodes report their series.
+func (p *Provider) ExactID(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	if id := meta.IDs["tvdb_id"]; id != "" {
+		if meta.Core.MediaType == provider.MediaTypeMovie {
+			return "movie-" + id
+		}
+		return "series-" + id
+	}
+	if id := meta.IDs["tvdb_show_id"]; id != "" {
+		return "series-" + id
+	}
+	return ""
+}
+
+// SearchCandidates lists the movie or series search results for a request,
+// best match first. Candidate IDs use TVDB's "series-<id>" and "movie-<id>"
+// record format so fetching one skips the search.
+func (p *Provider) SearchCandidates(ctx context.Context, request provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	if p.client == nil || p.apiKey == "" {
+		return nil, fmt.Errorf("provider not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var resultType string
+	switch request.MediaType {
+	case provider.MediaTypeMovie:
+		resultType = "movie"
+	case provider.MediaTypeShow:
+		resultType = "series"
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", request.MediaType)
+	}
+
+	query := strings.TrimSpace(request.Name)
+	if query == "" {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "candidate search requires a title", Retry: false}
+	}
+	req := operations.GetSearchResultsRequest{Query: &query, Type: &resultType}
+	if yr, err := strconv.Atoi(strings.TrimSpace(request.Year)); err == nil && request.MediaType == provider.MediaTypeMovie {
+		yf := float64(yr)
+		req.Year = &yf
+	}
+
+	resp, err := p.client.GetSearchResults(req)
+	if err != nil {
+		return nil, p.mapError(err)
+	}
+	if resp == nil {
+		return nil, nil
+	}
+
+	candidates := make([]provider.Candidate, 0, len(resp.Data))
+	for _, result := range resp.Data {
+		r := toSearchRecord(result)
+		if r.ID == 0 || !strings.EqualFold(pointerToString(result.Type), resultType) {
 			continue
 		}
-		if strings.EqualFold(pointerToString(candidate.Type), "movie") {
-			return r, nil
+		candidates = append(candidates, provider.Candidate{
+			ID:        fmt.Sprintf("%s-%d", resultType, r.ID),
+			Title:     r.Name,
+			Year:      r.Year,
+			MediaType: request.MediaType,
+			Overview:  firstNonEmptyString(result.Overviews["eng"], pointerToString(result.Overview)),
+		})
+	}
+
+	return provider.RankCandidates(request.Name, request.Year, request.MediaType, candidates, limit), nil
+}
+
+// exactRecordID parses a TVDB record ID such as "series-81189" and reports
+// whether it names a record of the wanted type.
+func exactRecordID(id, recordType string) (int64, bool) {
+	value, found := strings.CutPrefix(strings.TrimSpace(id), recordType+"-")
+	if !found {
+		return 0, false
+	}
+	parsed := parseInt64(value)
+	return parsed, parsed > 0
+}
+
+// bestSearchRecord scores the search results of the wanted type against the
+// query and returns the best one with its score, or nil when none qualify.
+func bestSearchRecord(results []shared.SearchResult, resultType, name, year string, mediaType provider.MediaType) *searchRecord {
+	records := make([]*searchRecord, 0, len(results))
+	candidates := make([]provider.MatchCandidate, 0, len(results))
+	for _, result := range results {
+		r := toSearchRecord(result)
+		if r.ID == 0 || !strings.EqualFold(pointerToString(result.Type), resultType) {
+			continue
+		}
+
+		titles := make([]string, 0, len(result.Aliases)+len(result.Translations))
+		titles = append(titles, result.Aliases...)
+		for _, translated := range result.Translations {
+			titles = append(titles, translated)
 		}
+		records = append(records, r)
+		candidates = append(candidates, provider.MatchCandidate{
+			Title:     r.Name,
+			Titles:    titles,
+			Year:      r.Year,
+			MediaType: mediaType,
+		})
 	}
 
-	return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: "movie not found", Retry: false}
+	best, score := provider.BestMatch(name, year, mediaType, candidates)
+	if best < 0 {
+		return nil
+	}
+	records[best].Score = score
+	return records[best]
 }
 
 func toSearchRecord(result shared.SearchResult) *searchRecord {
@@ -570,6 +870,27 @@ func toSearchRecord(result shared.SearchResult) *searchRecord {
 	return &searchRecord{ID: id, Name: name, Year: year}
 }
 
+// titleVariants collects TVDB's primary name (which is in the original
+// language), its name translations and its aliases.
+func titleVariants(name, originalLanguage string, translations *shared.TranslationExtended, aliases []shared.Alias) provider.TitleVariants {
+	variants := provider.TitleVariants{Localized: name, Original: name}
+	for _, tr := range translations.GetNameTranslations() {
+		if tr.IsAlias != nil && *tr.IsAlias {
+			variants.AddAlternate(pointerToString(tr.Name))
+			continue
+		}
+		variants.AddTranslation(pointerToString(tr.Language), pointerToString(tr.Name))
+	}
+	variants.AddTranslation(originalLanguage, name)
+	for _, alias := range translations.GetAlias() {
+		variants.AddAlternate(alias)
+	}
+	for _, alias := range aliases {
+		variants.AddAlternate(pointerToString(alias.Name))
+	}
+	return variants
+}
+
 func findRemoteID(ids []shared.RemoteID, source string) string {
 	needle := strings.ToLower(strings.TrimSpace(source))
 	for _, remote := range ids {
@@ -625,24 +946,20 @@ func stringSliceContains(values []string, value string) bool {
 	return false
 }
 
+// mapError maps TVDB errors to provider errors by the HTTP status the API
+// client reports.
 func (p *Provider) mapError(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	msg := err.Error()
-	lower := strings.ToLower(msg)
-
-	switch {
-	case strings.Contains(lower, "401"), strings.Contains(lower, "unauthorized"), strings.Contains(lower, "apikey"):
-		return &provider.ProviderError{Provider: providerName, Code: "AUTH_FAILED", Message: "TVDB authentication failed: " + msg, Retry: false}
-	case strings.Contains(lower, "429"), strings.Contains(lower, "too many"):
-		return &provider.ProviderError{Provider: providerName, Code: "RATE_LIMITED", Message: msg, Retry: true, RetryAfter: 5}
-	case strings.Contains(lower, "404"), strings.Contains(lower, "not found"):
-		return &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: msg, Retry: false}
-	case strings.Contains(lower, "503"), strings.Contains(lower, "unavailable"):
-		return &provider.ProviderError{Provider: providerName, Code: "UNAVAILABLE", Message: msg, Retry: true, RetryAfter: 30}
-	default:
-		return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: msg, Retry: false}
+	var sdkErr *sdkerrors.SDKError
+	if errors.As(err, &sdkErr) {
+		statusErr := &provider.StatusError{Status: sdkErr.StatusCode, Message: sdkErr.Message}
+		if sdkErr.RawResponse != nil {
+			statusErr = provider.NewStatusError(sdkErr.RawResponse, sdkErr.Message)
+		}
+		return provider.MapStatusError(providerName, "TVDB", statusErr)
 	}
+	return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: "TVDB error: " + err.Error(), Retry: false}
 }
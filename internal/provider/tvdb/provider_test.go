@@ -0,0 +1,259 @@
+package tvdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	tvdbapi "github.com/dashotv/tvdb"
+	"github.com/dashotv/tvdb/openapi/models/operations"
+	"github.com/dashotv/tvdb/openapi/models/sdkerrors"
+	"github.com/google/go-cmp/cmp"
+)
+
+// stubClient answers TVDB calls from canned JSON bodies and records the
+// episode requests it gets. Calls it has no answer for panic.
+type stubClient struct {
+	TVDBClient
+	remote   map[string]string // remote ID -> search results body
+	series   string
+	movie    string
+	episodes string
+	requests *[]operations.GetSeriesEpisodesRequest
+}
+
+func (c stubClient) GetSeriesExtended(id float64, meta *operations.GetSeriesExtendedQueryParamMeta, short *bool) (*tvdbapi.GetSeriesExtendedResponse, error) {
+	var resp tvdbapi.GetSeriesExtendedResponse
+	return &resp, json.Unmarshal([]byte(c.series), &resp)
+}
+
+func (c stubClient) GetMovieExtended(id float64, meta *operations.QueryParamMeta, short *bool) (*tvdbapi.GetMovieExtendedResponse, error) {
+	var resp tvdbapi.GetMovieExtendedResponse
+	return &resp, json.Unmarshal([]byte(c.movie), &resp)
+}
+
+func (c stubClient) GetSeriesEpisodes(request operations.GetSeriesEpisodesRequest) (*tvdbapi.GetSeriesEpisodesResponse, error) {
+	*c.requests = append(*c.requests, request)
+	var resp tvdbapi.GetSeriesEpisodesResponse
+	return &resp, json.Unmarshal([]byte(c.episodes), &resp)
+}
+
+func (c stubClient) GetSearchResultsByRemoteID(remoteID string) (*tvdbapi.GetSearchResultsByRemoteIDResponse, error) {
+	var resp tvdbapi.GetSearchResultsByRemoteIDResponse
+	if err := json.Unmarshal([]byte(c.remote[remoteID]), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func TestRemoteRecord(t *testing.T) {
+	t.Parallel()
+
+	client := stubClient{remote: map[string]string{
+		"tt0411008": `{"data": [{"series": {"id": 73739, "name": "Lost", "year": "2004"}}]}`,
+		"4607":      `{"data": [{"movie": {"id": 1, "name": "Some Movie"}}, {"series": {"id": 73739, "name": "Lost", "year": "2004"}}]}`,
+		"1399":      `{"data": [{"series": {"id": 121361, "name": "Game of Thrones"}}, {"series": {"id": 5, "name": "Another Show"}}]}`,
+		"949":       `{"data": [{"movie": {"id": 1234, "name": "Heat"}}]}`,
+	}}
+	p := &Provider{client: client}
+
+	tests := []struct {
+		name       string
+		ids        map[string]string
+		recordType string
+		wantID     int64
+	}{
+		{"imdb", map[string]string{"imdb_id": "tt0411008", "tmdb_id": "1399"}, "series", 73739},
+		{"tmdb_one_of_type", map[string]string{"tmdb_id": "4607"}, "series", 73739},
+		{"tmdb_ambiguous", map[string]string{"tmdb_id": "1399"}, "series", 0},
+		{"tmdb_other_type", map[string]string{"tmdb_id": "949"}, "series", 0},
+		{"tmdb_movie", map[string]string{"tmdb_id": "949"}, "movie", 1234},
+		{"none", nil, "series", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := provider.FetchRequest{Extra: map[string]interface{}{provider.ExtraKnownIDs: tc.ids}}
+			record := p.remoteRecord(request, tc.recordType)
+			var got int64
+			if record != nil {
+				got = record.ID
+			}
+			if got != tc.wantID {
+				t.Errorf("remoteRecord(%v, %s) = %d, want %d", tc.ids, tc.recordType, got, tc.wantID)
+			}
+		})
+	}
+}
+
+func TestFetchEpisodeOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		order          string
+		wantSeasonType string
+		wantSeason     bool
+	}{
+		{order: "aired", wantSeasonType: "official", wantSeason: true},
+		{order: "dvd", wantSeasonType: "dvd", wantSeason: true},
+		// Absolute numbers run across seasons, so no season is sent
+		{order: "absolute", wantSeasonType: "absolute", wantSeason: false},
+		{order: "story", wantSeasonType: "alternate", wantSeason: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.order, func(t *testing.T) {
+			t.Parallel()
+
+			var requests []operations.GetSeriesEpisodesRequest
+			p := &Provider{client: stubClient{
+				series:   `{"data": {"name": "Cowboy Bebop"}}`,
+				episodes: `{"data": {"series": {"name": "Cowboy Bebop"}, "episodes": [{"number": 3, "name": "Honky Tonk Women"}]}}`,
+				requests: &requests,
+			}}
+			orders, err := provider.ParseEpisodeOrders(map[string]interface{}{"episode_order": tc.order})
+			if err != nil {
+				t.Fatalf("ParseEpisodeOrders() unexpected error: %v", err)
+			}
+			p.episodeOrders = orders
+
+			meta, err := p.fetchEpisode(provider.FetchRequest{MediaType: provider.MediaTypeEpisode, ID: "series-76885", Name: "Cowboy Bebop", Season: 1, Episode: 3})
+			if err != nil {
+				t.Fatalf("fetchEpisode() unexpected error: %v", err)
+			}
+			if meta.Core.EpisodeName != "Honky Tonk Women" {
+				t.Errorf("EpisodeName = %q, want Honky Tonk Women", meta.Core.EpisodeName)
+			}
+			if len(requests) != 1 {
+				t.Fatalf("GetSeriesEpisodes called %d times, want 1", len(requests))
+			}
+			req := requests[0]
+			if req.SeasonType != tc.wantSeasonType {
+				t.Errorf("SeasonType = %q, want %q", req.SeasonType, tc.wantSeasonType)
+			}
+			if got := req.Season != nil; got != tc.wantSeason {
+				t.Errorf("Season sent = %v, want %v", got, tc.wantSeason)
+			}
+			if req.EpisodeNumber == nil || *req.EpisodeNumber != 3 {
+				t.Errorf("EpisodeNumber = %v, want 3", req.EpisodeNumber)
+			}
+		})
+	}
+}
+
+func TestFetchEpisodeOrderByParsedShowName(t *testing.T) {
+	t.Parallel()
+
+	var requests []operations.GetSeriesEpisodesRequest
+	p := &Provider{client: stubClient{
+		series:   `{"data": {"name": "Cowboy Bebop"}}`,
+		episodes: `{"data": {"series": {"name": "Cowboy Bebop"}, "episodes": [{"number": 3, "name": "Honky Tonk Women"}]}}`,
+		requests: &requests,
+	}}
+	orders, err := provider.ParseEpisodeOrders(map[string]interface{}{
+		"episode_orders": map[string]string{"Kaubōi Bibappu": "dvd"},
+	})
+	if err != nil {
+		t.Fatalf("ParseEpisodeOrders() unexpected error: %v", err)
+	}
+	p.episodeOrders = orders
+
+	// The folder is named after the original title, TVDB matched the English one
+	_, err = p.fetchEpisode(provider.FetchRequest{
+		MediaType: provider.MediaTypeEpisode,
+		ID:        "series-76885",
+		Name:      "Cowboy Bebop",
+		Season:    1,
+		Episode:   3,
+		Extra:     map[string]interface{}{provider.ExtraShowName: "Kaubōi Bibappu"},
+	})
+	if err != nil {
+		t.Fatalf("fetchEpisode() unexpected error: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("GetSeriesEpisodes called %d times, want 1", len(requests))
+	}
+	if got := requests[0].SeasonType; got != "dvd" {
+		t.Errorf("SeasonType = %q, want dvd", got)
+	}
+}
+
+func TestFetchMovieCreditsAndCertification(t *testing.T) {
+	t.Parallel()
+
+	p := &Provider{certificationCountry: "GB", client: stubClient{movie: `{"data": {
+		"name": "The Matrix",
+		"year": "1999",
+		"studios": [{"name": "Warner Bros."}],
+		"characters": [
+			{"peopleType": "Actor", "personName": "Carrie-Anne Moss", "sort": 3},
+			{"peopleType": "Director", "personName": "Lana Wachowski", "sort": 1},
+			{"peopleType": "Actor", "personName": "Keanu Reeves", "sort": 1},
+			{"peopleType": "Actor", "personName": "Hugo Weaving", "sort": 4},
+			{"peopleType": "Director", "personName": "Lilly Wachowski", "sort": 2},
+			{"peopleType": "Actor", "personName": "Laurence Fishburne", "sort": 2}
+		],
+		"contentRatings": [
+			{"country": "usa", "name": "R"},
+			{"country": "gbr", "name": "15"}
+		]
+	}}`}}
+
+	meta, err := p.fetchMovie(provider.FetchRequest{MediaType: provider.MediaTypeMovie, ID: "movie-169", Name: "The Matrix"})
+	if err != nil {
+		t.Fatalf("fetchMovie() unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"director":      "Lana Wachowski, Lilly Wachowski",
+		"cast":          "Keanu Reeves, Laurence Fishburne, Carrie-Anne Moss",
+		"certification": "15",
+		"studio":        "Warner Bros.",
+	}
+	got := map[string]interface{}{}
+	for key := range want {
+		got[key] = meta.Extended[key]
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Extended mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without a configured country no certification is picked
+	p.certificationCountry = ""
+	meta, err = p.fetchMovie(provider.FetchRequest{MediaType: provider.MediaTypeMovie, ID: "movie-169", Name: "The Matrix"})
+	if err != nil {
+		t.Fatalf("fetchMovie() unexpected error: %v", err)
+	}
+	if certification, ok := meta.Extended["certification"]; ok {
+		t.Errorf("certification = %v without a country, want none", certification)
+	}
+}
+
+func TestMapError(t *testing.T) {
+	t.Parallel()
+
+	limited := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"9"}}}
+	tests := []struct {
+		name           string
+		err            error
+		wantCode       string
+		wantRetryAfter int
+	}{
+		{"unauthorized", sdkerrors.NewSDKError("API error occurred", http.StatusUnauthorized, "", nil), "AUTH_FAILED", 0},
+		{"not_found", sdkerrors.NewSDKError("API error occurred", http.StatusNotFound, "", nil), "NOT_FOUND", 0},
+		{"rate_limited", sdkerrors.NewSDKError("API error occurred", limited.StatusCode, "", limited), "RATE_LIMITED", 9},
+		{"unavailable", sdkerrors.NewSDKError("API error occurred", http.StatusServiceUnavailable, "", nil), "UNAVAILABLE", 0},
+		// Series IDs and titles can hold digits that look like status codes
+		{"plain_error", errors.New("series 401503: no episodes"), "UNKNOWN", 0},
+	}
+
+	p := &Provider{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var provErr *provider.ProviderError
+			if err := p.mapError(tc.err); !errors.As(err, &provErr) || provErr.Code != tc.wantCode || provErr.RetryAfter != tc.wantRetryAfter {
+				t.Errorf("mapError(%v) = %#v, want %s after %ds", tc.err, err, tc.wantCode, tc.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package tvmaze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+type show struct {
+	ID             int      `json:"id"`
+	Name           string   `json:"name"`
+	Genres         []string `json:"genres"`
+	Premiered      string   `json:"premiered"`
+	Runtime        int      `json:"runtime"`
+	AverageRuntime int      `json:"averageRuntime"`
+	Rating         rating   `json:"rating"`
+	Network        *network `json:"network"`
+	WebChannel     *network `json:"webChannel"`
+	Externals      struct {
+		TheTVDB int    `json:"thetvdb"`
+		IMDB    string `json:"imdb"`
+	} `json:"externals"`
+	Summary string `json:"summary"`
+}
+
+type network struct {
+	Name string `json:"name"`
+}
+
+type rating struct {
+	Average float64 `json:"average"`
+}
+
+type searchResult struct {
+	Score float64 `json:"score"`
+	Show  show    `json:"show"`
+}
+
+type season struct {
+	ID           int      `json:"id"`
+	Number       int      `json:"number"`
+	EpisodeOrder int      `json:"episodeOrder"`
+	PremiereDate string   `json:"premiereDate"`
+	Network      *network `json:"network"`
+	WebChannel   *network `json:"webChannel"`
+	Summary      string   `json:"summary"`
+}
+
+type episode struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Season  int    `json:"season"`
+	Number  int    `json:"number"`
+	Airdate string `json:"airdate"`
+	Runtime int    `json:"runtime"`
+	Rating  rating `json:"rating"`
+	Summary string `json:"summary"`
+}
+
+func (p *Provider) fetchShow(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	s, score, err := p.resolveShow(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := showMetadata(s, provider.MediaTypeShow, score)
+	meta.Core.Overview = stripHTML(s.Summary)
+	meta.Core.Rating = float32(s.Rating.Average)
+	meta.Core.Genres = s.Genres
+	if s.Premiered != "" {
+		meta.Extended["first_air_date"] = s.Premiered
+	}
+	if runtime := firstPositive(s.AverageRuntime, s.Runtime); runtime > 0 {
+		meta.Extended["runtime"] = runtime
+	}
+	credit(meta)
+	return meta, nil
+}
+
+func (p *Provider) fetchSeason(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if request.Season <= 0 {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "season fetch requires a valid season number", Retry: false}
+	}
+
+	s, score, err := p.resolveShow(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var seasons []season
+	if err := p.getJSON(ctx, fmt.Sprintf("/shows/%d/seasons", s.ID), nil, &seasons); err != nil {
+		return nil, p.mapError(err)
+	}
+
+	var found *season
+	for i := range seasons {
+		if seasons[i].Number == request.Season {
+			found = &seasons[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("season %d not found for %s", request.Season, s.Name), Retry: false}
+	}
+
+	meta := showMetadata(s, provider.MediaTypeSeason, score)
+	meta.Core.SeasonNum = found.Number
+	meta.Core.Overview = stripHTML(found.Summary)
+	if name := networkName(found.Network, found.WebChannel); name != "" {
+		meta.Extended["networks"] = name
+	}
+	if found.PremiereDate != "" {
+		meta.Extended["first_air_date"] = found.PremiereDate
+	}
+	if found.EpisodeOrder > 0 {
+		meta.Extended["episode_count"] = found.EpisodeOrder
+	}
+	credit(meta)
+	return meta, nil
+}
+
+func (p *Provider) fetchEpisode(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if request.Season <= 0 || request.Episode <= 0 {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "episode fetch requires valid season and episode numbers", Retry: false}
+	}
+
+	s, score, err := p.resolveShow(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("season", strconv.Itoa(request.Season))
+	query.Set("number", strconv.Itoa(request.Episode))
+	var ep episode
+	if err := p.getJSON(ctx, fmt.Sprintf("/shows/%d/episodebynumber", s.ID), query, &ep); err != nil {
+		return nil, p.mapError(err)
+	}
+
+	meta := showMetadata(s, provider.MediaTypeEpisode, score)
+	meta.Core.SeasonNum = request.Season
+	meta.Core.EpisodeNum = request.Episode
+	meta.Core.EpisodeName = strings.TrimSpace(ep.Name)
+	meta.Core.Overview = stripHTML(ep.Summary)
+	meta.Core.Rating = float32(ep.Rating.Average)
+	if ep.Airdate != "" {
+		meta.Extended["air_date"] = ep.Airdate
+	}
+	if ep.Runtime > 0 {
+		meta.Extended["runtime"] = ep.Runtime
+	}
+	credit(meta)
+	return meta, nil
+}
+
+// SearchCandidates lists the show search results for a request, best match
+// first. Candidate IDs are TVmaze show IDs.
+func (p *Provider) SearchCandidates(ctx context.Context, request provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if request.MediaType != provider.MediaTypeShow {
+		return nil, fmt.Errorf("unsupported media type: %s", request.MediaType)
+	}
+
+	results, err := p.search(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]provider.Candidate, 0, len(results))
+	for _, result := range results {
+		candidates = append(candidates, provider.Candidate{
+			ID:        strconv.Itoa(result.Show.ID),
+			Title:     result.Show.Name,
+			Year:      yearOf(result.Show.Premiered),
+			MediaType: provider.MediaTypeShow,
+			Overview:  stripHTML(result.Show.Summary),
+		})
+	}
+	return provider.RankCandidates(request.Name, request.Year, provider.MediaTypeShow, candidates, limit), nil
+}
+
+// resolveShow finds the show behind a request and the confidence of the
+// match. A TVmaze show ID or an IMDb ID skips the search, as do IMDb and TVDB
+// IDs other providers resolved when TVmaze knows them.
+func (p *Provider) resolveShow(ctx context.Context, request provider.FetchRequest) (*show, float64, error) {
+	id := strings.TrimSpace(request.ID)
+	if _, err := strconv.Atoi(id); err == nil {
+		var s show
+		if err := p.getJSON(ctx, "/shows/"+id, nil, &s); err != nil {
+			return nil, 0, p.mapError(err)
+		}
+		return &s, 1.0, nil
+	}
+	if strings.HasPrefix(id, "tt") {
+		var s show
+		if err := p.getJSON(ctx, "/lookup/shows", url.Values{"imdb": {id}}, &s); err != nil {
+			return nil, 0, p.mapError(err)
+		}
+		return &s, 1.0, nil
+	}
+	if id == "" {
+		for _, lookup := range []struct{ param, key string }{{"imdb", "imdb_id"}, {"thetvdb", "tvdb_id"}} {
+			known := request.KnownID(lookup.key)
+			if known == "" {
+				continue
+			}
+			var s show
+			err := p.getJSON(ctx, "/lookup/shows", url.Values{lookup.param: {known}}, &s)
+			if err == nil {
+				return &s, 1.0, nil
+			}
+			// Shows TVmaze doesn't know by that ID are still searched by name
+			if mapped := p.mapError(err); !provider.IsNotFound(mapped) {
+				return nil, 0, mapped
+			}
+		}
+	}
+
+	results, err := p.search(ctx, request)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 {
+		return nil, 0, &provider.ProviderError{Provider: providerName, Code: "NOT_FOUND", Message: fmt.Sprintf("no results found for show: %s", request.Name), Retry: false}
+	}
+
+	candidates := make([]provider.MatchCandidate, 0, len(results))
+	for _, result := range results {
+		candidates = append(candidates, provider.MatchCandidate{
+			Title:     result.Show.Name,
+			Year:      yearOf(result.Show.Premiered),
+			MediaType: provider.MediaTypeShow,
+		})
+	}
+	best, score := provider.BestMatch(request.Name, request.Year, provider.MediaTypeShow, candidates)
+	return &results[best].Show, score, nil
+}
+
+func (p *Provider) search(ctx context.Context, request provider.FetchRequest) ([]searchResult, error) {
+	query := strings.TrimSpace(request.Name)
+	if query == "" {
+		return nil, &provider.ProviderError{Provider: providerName, Code: "INVALID_REQUEST", Message: "show search requires a title", Retry: false}
+	}
+
+	var results []searchResult
+	if err := p.getJSON(ctx, "/search/shows", url.Values{"q": {query}}, &results); err != nil {
+		return nil, p.mapError(err)
+	}
+	return results, nil
+}
+
+// getJSON issues a GET against the API and decodes the JSON body into out.
+// Non-200 responses are returned as provider.StatusError.
+func (p *Provider) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := p.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return provider.NewStatusError(resp, "GET "+path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: invalid response: %w", path, err)
+	}
+	return nil
+}
+
+// showMetadata fills the fields shared by show, season and episode results.
+func showMetadata(s *show, mediaType provider.MediaType, score float64) *provider.Metadata {
+	meta := &provider.Metadata{
+		Core: provider.CoreMetadata{
+			Title:     strings.TrimSpace(s.Name),
+			Year:      yearOf(s.Premiered),
+			MediaType: mediaType,
+		},
+		Extended:   make(map[string]interface{}),
+		Sources:    make(map[string]string),
+		IDs:        map[string]string{"tvmaze_id": strconv.Itoa(s.ID)},
+		Confidence: score,
+	}
+	if s.Externals.TheTVDB > 0 {
+		meta.IDs["tvdb_id"] = strconv.Itoa(s.Externals.TheTVDB)
+	}
+	if s.Externals.IMDB != "" {
+		meta.IDs["imdb_id"] = s.Externals.IMDB
+	}
+	if name := networkName(s.Network, s.WebChannel); name != "" {
+		meta.Extended["networks"] = name
+	}
+	return meta
+}
+
+// credit records this provider as the source of every populated field.
+func credit(meta *provider.Metadata) {
+	populated := map[string]bool{
+		"title":         meta.Core.Title != "",
+		"year":          meta.Core.Year != "",
+		"episode_title": meta.Core.EpisodeName != "",
+		"overview":      meta.Core.Overview != "",
+		"rating":        meta.Core.Rating > 0,
+		"genres":        len(meta.Core.Genres) > 0,
+	}
+	for field, ok := range populated {
+		if ok {
+			meta.Sources[field] = providerName
+		}
+	}
+	for key := range meta.Extended {
+		meta.Sources[key] = providerName
+	}
+	for key := range meta.IDs {
+		meta.Sources[key] = providerName
+	}
+}
+
+func networkName(candidates ...*network) string {
+	for _, n := range candidates {
+		if n != nil && strings.TrimSpace(n.Name) != "" {
+			return strings.TrimSpace(n.Name)
+		}
+	}
+	return ""
+}
+
+func yearOf(date string) string {
+	if len(date) >= 4 {
+		return date[:4]
+	}
+	return ""
+}
+
+func firstPositive(values ...int) int {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML removes the markup TVmaze wraps around summaries.
+func stripHTML(value string) string {
+	return strings.TrimSpace(htmlTag.ReplaceAllString(value, ""))
+}
@@ -0,0 +1,224 @@
+package tvmaze
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+)
+
+const (
+	providerName = "tvmaze"
+
+	// DefaultURL is the public TVmaze API. It needs no API key.
+	DefaultURL = "https://api.tvmaze.com"
+)
+
+// Provider implements the provider.Provider interface for TVmaze.
+type Provider struct {
+	httpClient *http.Client
+	baseURL    string
+	config     map[string]interface{}
+}
+
+// New creates a new TVmaze provider instance.
+func New() *Provider {
+	return &Provider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    DefaultURL,
+		config:     make(map[string]interface{}),
+	}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// Description returns a human readable description of the provider.
+func (p *Provider) Description() string {
+	return "TVmaze show and episode metadata (no API key required)"
+}
+
+// Capabilities returns what this provider can handle.
+func (p *Provider) Capabilities() provider.ProviderCapabilities {
+	return provider.ProviderCapabilities{
+		MediaTypes: []provider.MediaType{
+			provider.MediaTypeShow,
+			provider.MediaTypeSeason,
+			provider.MediaTypeEpisode,
+		},
+		RequiresAuth: false,
+		Priority:     85,
+	}
+}
+
+// SupportedVariables returns the template variables supported by TVmaze.
+func (p *Provider) SupportedVariables() []provider.TemplateVariable {
+	return []provider.TemplateVariable{
+		{
+			Name:        "episode_title",
+			DisplayName: "Episode Title",
+			Description: "Title of the episode",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeEpisode},
+			Example:     "Pilot",
+			Category:    "basic",
+			Provider:    providerName,
+		},
+		{
+			Name:        "air_date",
+			DisplayName: "Air Date",
+			Description: "Original air date of the episode",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeEpisode},
+			Example:     "2008-01-20",
+			Category:    "dates",
+			Format:      "date",
+			Provider:    providerName,
+		},
+		{
+			Name:        "first_air_date",
+			DisplayName: "First Air Date",
+			Description: "Premiere date of the show or season",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason},
+			Example:     "2008-01-20",
+			Category:    "dates",
+			Format:      "date",
+			Provider:    providerName,
+		},
+		{
+			Name:        "networks",
+			DisplayName: "Networks",
+			Description: "TV network or streaming service",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode},
+			Example:     "AMC",
+			Category:    "production",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "rating",
+			DisplayName: "Rating",
+			Description: "Average user rating",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeEpisode},
+			Example:     "9.2",
+			Category:    "ratings",
+			Format:      "number",
+			Provider:    providerName,
+		},
+		{
+			Name:        "genres",
+			DisplayName: "Genres",
+			Description: "List of genres",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow},
+			Example:     "Drama, Crime",
+			Category:    "basic",
+			Format:      "list",
+			Provider:    providerName,
+		},
+		{
+			Name:        "imdb_id",
+			DisplayName: "IMDB ID",
+			Description: "Internet Movie Database ID of the show",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode},
+			Example:     "tt0903747",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "tvdb_id",
+			DisplayName: "TVDB ID",
+			Description: "TheTVDB ID of the show",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode},
+			Example:     "81189",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+		{
+			Name:        "tvmaze_id",
+			DisplayName: "TVmaze ID",
+			Description: "TVmaze ID of the show",
+			MediaTypes:  []provider.MediaType{provider.MediaTypeShow, provider.MediaTypeSeason, provider.MediaTypeEpisode},
+			Example:     "169",
+			Category:    "identifiers",
+			Provider:    providerName,
+		},
+	}
+}
+
+// ConfigSchema returns the configuration schema for this provider.
+func (p *Provider) ConfigSchema() provider.ConfigSchema {
+	return provider.ConfigSchema{
+		Fields: []provider.ConfigField{
+			{
+				Name:        "base_url",
+				DisplayName: "Base URL",
+				Type:        provider.ConfigFieldTypeString,
+				Required:    false,
+				Default:     DefaultURL,
+				Description: "TVmaze API address; point it at a local stand-in for testing",
+			},
+		},
+	}
+}
+
+// Configure applies configuration to the provider. TVmaze needs no
+// credentials, so every setting is optional.
+func (p *Provider) Configure(config map[string]interface{}) error {
+	baseURL := DefaultURL
+	if raw, ok := config["base_url"]; ok {
+		value, isString := raw.(string)
+		if !isString {
+			return fmt.Errorf("base_url must be a string")
+		}
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			baseURL = trimmed
+		}
+	}
+
+	if p.httpClient == nil {
+		p.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	p.baseURL = strings.TrimRight(baseURL, "/")
+	p.config = config
+	return nil
+}
+
+// Fetch retrieves metadata for the given request.
+func (p *Provider) Fetch(ctx context.Context, request provider.FetchRequest) (*provider.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch request.MediaType {
+	case provider.MediaTypeShow:
+		return p.fetchShow(ctx, request)
+	case provider.MediaTypeSeason:
+		return p.fetchSeason(ctx, request)
+	case provider.MediaTypeEpisode:
+		return p.fetchEpisode(ctx, request)
+	default:
+		return nil, &provider.ProviderError{Provider: providerName, Code: "UNSUPPORTED_MEDIA_TYPE", Message: fmt.Sprintf("unsupported media type: %s", request.MediaType), Retry: false}
+	}
+}
+
+// ExactID returns the TVmaze show ID behind metadata from this provider.
+func (p *Provider) ExactID(meta *provider.Metadata) string {
+	if meta == nil {
+		return ""
+	}
+	return meta.IDs["tvmaze_id"]
+}
+
+// mapError maps TVmaze HTTP errors to provider errors by their status.
+func (p *Provider) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if provErr := provider.MapStatusError(providerName, "TVmaze", err); provErr != nil {
+		return provErr
+	}
+	return &provider.ProviderError{Provider: providerName, Code: "UNKNOWN", Message: "TVmaze error: " + err.Error(), Retry: false}
+}
@@ -0,0 +1,217 @@
+package tvmaze
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/google/go-cmp/cmp"
+)
+
+const breakingBad = `{
+	"id": 169,
+	"name": "Breaking Bad",
+	"genres": ["Drama", "Crime", "Thriller"],
+	"premiered": "2008-01-20",
+	"averageRuntime": 47,
+	"rating": {"average": 9.2},
+	"network": {"name": "AMC"},
+	"webChannel": null,
+	"externals": {"thetvdb": 81189, "imdb": "tt0903747"},
+	"summary": "<p><b>Breaking Bad</b> follows a chemistry teacher.</p>"
+}`
+
+// newStandIn starts a local server answering the TVmaze routes the provider
+// uses and returns a provider pointed at it.
+func newStandIn(t *testing.T, routes map[string]string) *Provider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"base_url": srv.URL + "/"}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	return prov
+}
+
+func TestConfigureBaseURL(t *testing.T) {
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+	if prov.baseURL != DefaultURL {
+		t.Errorf("baseURL = %q, want %q", prov.baseURL, DefaultURL)
+	}
+	if err := prov.Configure(map[string]interface{}{"base_url": 42}); err == nil {
+		t.Errorf("Configure(base_url: 42) succeeded, want error")
+	}
+}
+
+func TestFetchShow(t *testing.T) {
+	prov := newStandIn(t, map[string]string{
+		"/search/shows?q=Breaking+Bad": `[{"score": 0.9, "show": ` + breakingBad + `}]`,
+	})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeShow,
+		Name:      "Breaking Bad",
+		Year:      "2008",
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+
+	wantCore := provider.CoreMetadata{
+		Title:     "Breaking Bad",
+		Year:      "2008",
+		Overview:  "Breaking Bad follows a chemistry teacher.",
+		Rating:    9.2,
+		Genres:    []string{"Drama", "Crime", "Thriller"},
+		MediaType: provider.MediaTypeShow,
+	}
+	if diff := cmp.Diff(wantCore, meta.Core); diff != "" {
+		t.Errorf("Core mismatch (-want +got):\n%s", diff)
+	}
+	wantIDs := map[string]string{"tvmaze_id": "169", "tvdb_id": "81189", "imdb_id": "tt0903747"}
+	if diff := cmp.Diff(wantIDs, meta.IDs); diff != "" {
+		t.Errorf("IDs mismatch (-want +got):\n%s", diff)
+	}
+	wantExtended := map[string]interface{}{"networks": "AMC", "first_air_date": "2008-01-20", "runtime": 47}
+	if diff := cmp.Diff(wantExtended, meta.Extended); diff != "" {
+		t.Errorf("Extended mismatch (-want +got):\n%s", diff)
+	}
+	if meta.Sources["imdb_id"] != providerName || meta.Sources["title"] != providerName {
+		t.Errorf("Sources = %v, want fields credited to %s", meta.Sources, providerName)
+	}
+	if meta.Confidence < 0.9 {
+		t.Errorf("Confidence = %v, want an exact match score", meta.Confidence)
+	}
+}
+
+func TestFetchSeason(t *testing.T) {
+	prov := newStandIn(t, map[string]string{
+		"/shows/169":         breakingBad,
+		"/shows/169/seasons": `[{"id": 1, "number": 1, "episodeOrder": 7, "premiereDate": "2008-01-20", "network": {"name": "AMC"}}, {"id": 2, "number": 2, "episodeOrder": 13, "premiereDate": "2009-03-08", "network": {"name": "AMC"}}]`,
+	})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeSeason,
+		ID:        "169",
+		Season:    2,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Core.SeasonNum != 2 || meta.Core.Title != "Breaking Bad" {
+		t.Errorf("Core = %+v, want Breaking Bad season 2", meta.Core)
+	}
+	if meta.Extended["first_air_date"] != "2009-03-08" || meta.Extended["episode_count"] != 13 {
+		t.Errorf("Extended = %v, want season 2 details", meta.Extended)
+	}
+
+	_, err = prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeSeason, ID: "169", Season: 9})
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "NOT_FOUND" {
+		t.Errorf("Fetch(season 9) error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestFetchEpisode(t *testing.T) {
+	prov := newStandIn(t, map[string]string{
+		"/lookup/shows?imdb=tt0903747":                 breakingBad,
+		"/shows/169/episodebynumber?number=7&season=1": `{"id": 7, "name": "A No-Rough-Stuff-Type Deal", "season": 1, "number": 7, "airdate": "2008-03-09", "runtime": 48, "rating": {"average": 8.7}, "summary": "<p>Walt and Jesse try to up production.</p>"}`,
+		"/search/shows?q=Unknown+Show":                 `[]`,
+	})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeEpisode,
+		ID:        "tt0903747",
+		Season:    1,
+		Episode:   7,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Core.EpisodeName != "A No-Rough-Stuff-Type Deal" {
+		t.Errorf("EpisodeName = %q, want A No-Rough-Stuff-Type Deal", meta.Core.EpisodeName)
+	}
+	if meta.Extended["air_date"] != "2008-03-09" || meta.Extended["networks"] != "AMC" {
+		t.Errorf("Extended = %v, want air_date and networks", meta.Extended)
+	}
+	if meta.IDs["tvdb_id"] != "81189" || meta.IDs["imdb_id"] != "tt0903747" {
+		t.Errorf("IDs = %v, want show IDs", meta.IDs)
+	}
+	if prov.ExactID(meta) != "169" {
+		t.Errorf("ExactID() = %q, want 169", prov.ExactID(meta))
+	}
+
+	tests := []struct {
+		name    string
+		request provider.FetchRequest
+		code    string
+	}{
+		{"invalid numbers", provider.FetchRequest{MediaType: provider.MediaTypeEpisode, ID: "tt0903747"}, "INVALID_REQUEST"},
+		{"no search results", provider.FetchRequest{MediaType: provider.MediaTypeEpisode, Name: "Unknown Show", Season: 1, Episode: 1}, "NOT_FOUND"},
+		{"missing episode", provider.FetchRequest{MediaType: provider.MediaTypeEpisode, ID: "tt0903747", Season: 1, Episode: 8}, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := prov.Fetch(context.Background(), tt.request)
+			var provErr *provider.ProviderError
+			if !errors.As(err, &provErr) || provErr.Code != tt.code {
+				t.Errorf("Fetch() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestFetchRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "4")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	prov := New()
+	if err := prov.Configure(map[string]interface{}{"base_url": srv.URL}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+
+	_, err := prov.Fetch(context.Background(), provider.FetchRequest{MediaType: provider.MediaTypeShow, Name: "Breaking Bad"})
+	var provErr *provider.ProviderError
+	if !errors.As(err, &provErr) || provErr.Code != "RATE_LIMITED" || !provErr.Retry || provErr.RetryAfter != 4 {
+		t.Errorf("Fetch() error = %#v, want RATE_LIMITED retried after the 4s Retry-After", err)
+	}
+}
+
+func TestFetchShowByKnownIDs(t *testing.T) {
+	// TVmaze doesn't know the IMDb ID, so the TVDB ID is tried next and the
+	// misleading name is never searched.
+	prov := newStandIn(t, map[string]string{
+		"/lookup/shows?thetvdb=81189": breakingBad,
+	})
+
+	meta, err := prov.Fetch(context.Background(), provider.FetchRequest{
+		MediaType: provider.MediaTypeShow,
+		Name:      "Braking Bad",
+		Extra:     map[string]interface{}{provider.ExtraKnownIDs: map[string]string{"imdb_id": "tt9999999", "tvdb_id": "81189"}},
+	})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error: %v", err)
+	}
+	if meta.Core.Title != "Breaking Bad" || meta.Confidence != 1.0 {
+		t.Errorf("Fetch() = %q with confidence %v, want Breaking Bad with 1.0", meta.Core.Title, meta.Confidence)
+	}
+}
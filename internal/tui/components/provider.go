@@ -66,6 +66,14 @@ func deletionError() func(*treeview.Node[treeview.FileInfo]) bool {
 	})
 }
 
+// suspect matches files the integrity check flagged that haven't been
+// renamed yet
+func suspect() func(*treeview.Node[treeview.FileInfo]) bool {
+	return metaRule(func(mm *core.MediaMeta) bool {
+		return mm.Suspect != "" && mm.RenameStatus == core.RenameStatusNone
+	})
+}
+
 // CreateRenameProvider constructs the [treeview.DefaultNodeProvider] used by
 // the TUI and instant execution paths. It wires together:
 //   - icon rules (status precedes type so success/error override type icons)
@@ -81,6 +89,7 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 	deletionSuccessIconRule := treeview.WithIconRule(deletionSuccess(), iconSet["success"])
 	deletionErrorIconRule := treeview.WithIconRule(deletionError(), iconSet["delete"])
 	markedForDeletionIconRule := treeview.WithIconRule(markedForDeletion(), iconSet["delete"])
+	suspectIconRule := treeview.WithIconRule(suspect(), iconSet["suspect"])
 	// Regular status icons
 	successIconRule := treeview.WithIconRule(statusIs(core.RenameStatusSuccess), iconSet["success"])
 	errorIconRule := treeview.WithIconRule(statusIs(core.RenameStatusError), iconSet["error"])
@@ -133,6 +142,11 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 		lipgloss.NewStyle().Foreground(colors.Error),
 		lipgloss.NewStyle().Foreground(colors.Error).Background(colors.Background),
 	)
+	suspectStyleRule := treeview.WithStyleRule(
+		suspect(),
+		lipgloss.NewStyle().Foreground(colors.Warning),
+		lipgloss.NewStyle().Foreground(colors.Background).Background(colors.Warning),
+	)
 	// Deletion style rules
 	markedForDeletionStyleRule := treeview.WithStyleRule(
 		markedForDeletion(),
@@ -154,10 +168,10 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 
 	return treeview.NewDefaultNodeProvider(
 		// Icon rules (order matters - most specific first)
-		deletionSuccessIconRule, deletionErrorIconRule, markedForDeletionIconRule,
+		deletionSuccessIconRule, deletionErrorIconRule, markedForDeletionIconRule, suspectIconRule,
 		successIconRule, errorIconRule, virtualDirIconRule, showIconRule, seasonIconRule, episodeIconRule, movieIconRule, movieFileIconRule, defaultIconRule,
 		// Style rules (order matters - most specific first)
-		deletionSuccessStyleRule, markedForDeletionStyleRule, successStyleRule, errorStyleRule, showStyleRule, seasonStyleRule, episodeStyleRule, movieStyleRule, movieFileStyleRule, defaultStyleRule,
+		deletionSuccessStyleRule, markedForDeletionStyleRule, suspectStyleRule, successStyleRule, errorStyleRule, showStyleRule, seasonStyleRule, episodeStyleRule, movieStyleRule, movieFileStyleRule, defaultStyleRule,
 		// Formatter
 		formatterRule,
 	)
@@ -166,6 +180,8 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 // RenameFormatter produces the display label for a node during visualization.
 //
 //   - If no metadata or no proposed NewName exists, the original name is returned unchanged.
+//   - Nodes marked for deletion show why they were marked until they are deleted.
+//   - Suspect files show why the integrity check flagged them until they are renamed.
 //   - On success, only the new name is shown (keeps the tree clean post-apply).
 //   - On error, the original name plus the error message are shown.
 //   - For virtual directory creation, a [NEW] prefix is prepended to the proposed name.
@@ -177,14 +193,26 @@ func RenameFormatter(node *treeview.Node[treeview.FileInfo]) (string, bool) {
 		return node.Name(), true
 	}
 
-	// File marked for deletion - show just the filename (icon handles the status)
+	// File marked for deletion - show the filename and any reason (icon handles the status)
 	if mm.MarkedForDeletion {
 		if mm.RenameStatus == core.RenameStatusError {
 			return fmt.Sprintf("%s: %s", node.Name(), mm.RenameError), true
 		}
+		if mm.DeletionReason != "" && mm.RenameStatus != core.RenameStatusSuccess {
+			return fmt.Sprintf("%s (%s)", node.Name(), mm.DeletionReason), true
+		}
 		return node.Name(), true
 	}
 
+	// Suspect file - show the pending label and why it was flagged
+	if mm.Suspect != "" && mm.RenameStatus == core.RenameStatusNone {
+		label := node.Name()
+		if mm.NewName != "" && mm.NewName != node.Name() {
+			label = fmt.Sprintf("%s ← %s", mm.NewName, node.Name())
+		}
+		return fmt.Sprintf("%s (%s)", label, mm.Suspect), true
+	}
+
 	if mm.NewName == "" {
 		// no proposed rename
 		return node.Name(), true
@@ -165,6 +165,52 @@ func TestRenameFormatter_MarkedForDeletion(t *testing.T) {
 	}
 }
 
+func TestRenameFormatter_MarkedForDeletionWithReason(t *testing.T) {
+	t.Parallel()
+	n := testNode("heat-sample.mkv", false)
+	mm := core.EnsureMeta(n)
+	mm.MarkedForDeletion = true
+	mm.DeletionReason = "sample: 60 MB beside a 7.8 GB video"
+
+	got, _ := RenameFormatter(n)
+	expected := "heat-sample.mkv (sample: 60 MB beside a 7.8 GB video)"
+	if got != expected {
+		t.Errorf("RenameFormatter(deletion reason) = %q, want %q", got, expected)
+	}
+
+	// Once deleted, the reason is no longer shown
+	mm.RenameStatus = core.RenameStatusSuccess
+	if got, _ := RenameFormatter(n); got != "heat-sample.mkv" {
+		t.Errorf("RenameFormatter(deleted) = %q, want heat-sample.mkv", got)
+	}
+}
+
+func TestRenameFormatter_Suspect(t *testing.T) {
+	t.Parallel()
+	n := testNode("heat.mkv", false)
+	mm := core.EnsureMeta(n)
+	mm.NewName = "Heat (1995).mkv"
+	mm.Suspect = "suspect: 41m of a 2h 50m runtime"
+
+	got, _ := RenameFormatter(n)
+	expected := "Heat (1995).mkv ← heat.mkv (suspect: 41m of a 2h 50m runtime)"
+	if got != expected {
+		t.Errorf("RenameFormatter(suspect) = %q, want %q", got, expected)
+	}
+
+	// Skipped suspects keep their name
+	mm.NewName = ""
+	if got, _ := RenameFormatter(n); got != "heat.mkv (suspect: 41m of a 2h 50m runtime)" {
+		t.Errorf("RenameFormatter(skipped suspect) = %q", got)
+	}
+
+	mm.NewName = "Heat (1995).mkv"
+	mm.RenameStatus = core.RenameStatusSuccess
+	if got, _ := RenameFormatter(n); got != "Heat (1995).mkv" {
+		t.Errorf("RenameFormatter(renamed suspect) = %q, want Heat (1995).mkv", got)
+	}
+}
+
 func TestRenameFormatter_MarkedForDeletionWithError(t *testing.T) {
 	t.Parallel()
 	n := testNode("failed.nfo", false)
@@ -84,6 +84,28 @@ func TestBuildVariablesFiltersByEnabledProviders(t *testing.T) {
 	}
 }
 
+func TestBuildVariablesIncludesPluginProviders(t *testing.T) {
+	reg := config.NewTemplateRegistry()
+	for _, prov := range []fakeProvider{
+		{name: "studio", vars: []provider.TemplateVariable{{Name: "studio_code", Description: "studio code", MediaTypes: []provider.MediaType{provider.MediaTypeMovie}}}},
+		{name: "local", vars: []provider.TemplateVariable{{Name: "title", Description: "local title", MediaTypes: []provider.MediaType{provider.MediaTypeMovie}}}},
+	} {
+		if err := reg.RegisterProvider(prov); err != nil {
+			t.Fatalf("RegisterProvider(%s) error = %v", prov.name, err)
+		}
+	}
+
+	state := buildStateFromConfig(&config.FormatConfig{}, theme.Default())
+	vars := buildVariables(SectionMovie, &state, reg)
+	want := []variable{
+		{name: "{title}", description: "local title"},
+		{name: "{studio_code}", description: "studio code"},
+	}
+	if diff := cmp.Diff(want, vars, cmp.AllowUnexported(variable{})); diff != "" {
+		t.Fatalf("variables diff (-want +got):\n%s", diff)
+	}
+}
+
 func TestBuildPreviewsProviders(t *testing.T) {
 	state := buildStateFromConfig(&config.FormatConfig{}, theme.Default())
 	state.Providers.FFProbeEnabled = true
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -662,6 +663,7 @@ func cloneFormatConfig(cfg *config.FormatConfig) *config.FormatConfig {
 		OMDBAPIKey:           cfg.OMDBAPIKey,
 		EnableOMDBLookup:     cfg.EnableOMDBLookup,
 		EnableFFProbe:        cfg.EnableFFProbe,
+		TitleLanguages:       slices.Clone(cfg.TitleLanguages),
 	}
 }
 
@@ -106,11 +106,25 @@ func buildPreviews(section Section, state *ConfigState, icons map[string]string,
 		},
 		IDs: map[string]string{"imdb_id": "tt0903747"},
 		Extended: map[string]interface{}{
-			"tagline":          "All Hail the King",
-			"networks":         "AMC",
-			"audio_codec":      "aac",
-			"video_codec":      "264",
-			"video_resolution": "1080p",
+			"tagline":                "All Hail the King",
+			"networks":               "AMC",
+			"studio":                 "Sony Pictures Television",
+			"certification":          "TV-MA",
+			"director":               "Tricia Brock",
+			"cast":                   "Bryan Cranston, Aaron Paul, Anna Gunn",
+			"audio_codec":            "aac",
+			"video_codec":            "264",
+			"video_resolution":       "1080p",
+			"video_resolution_label": "1080p",
+			"video_bit_depth":        "8bit",
+			"video_frame_rate":       "23.976",
+			"audio_channels":         "5.1",
+			"audio_languages":        "eng",
+			"subtitle_languages":     "eng, spa",
+			"container":              "mkv",
+			"duration":               "47m",
+			"bitrate":                "6.2 Mbps",
+			"movie_hash":             "a1b2c3d4e5f60718",
 		},
 	}
 
@@ -123,11 +137,28 @@ func buildPreviews(section Section, state *ConfigState, icons map[string]string,
 		},
 		IDs: map[string]string{"imdb_id": "tt0133093"},
 		Extended: map[string]interface{}{
-			"tagline":          "Welcome to the Real World",
-			"studios":          "Warner Bros.",
-			"audio_codec":      "aac",
-			"video_codec":      "264",
-			"video_resolution": "2160p",
+			"tagline":                "Welcome to the Real World",
+			"collection":             "The Matrix Collection",
+			"collection_id":          "2344",
+			"studio":                 "Warner Bros. Pictures",
+			"certification":          "R",
+			"director":               "Lana Wachowski, Lilly Wachowski",
+			"cast":                   "Keanu Reeves, Laurence Fishburne, Carrie-Anne Moss",
+			"audio_codec":            "aac",
+			"video_codec":            "264",
+			"video_resolution":       "2160p",
+			"video_resolution_label": "4K",
+			"hdr_format":             "Dolby Vision",
+			"video_bit_depth":        "10bit",
+			"video_frame_rate":       "23.976",
+			"audio_channels":         "7.1",
+			"audio_object_format":    "Atmos",
+			"audio_languages":        "eng, jpn",
+			"subtitle_languages":     "eng",
+			"container":              "mkv",
+			"duration":               "2h 16m",
+			"bitrate":                "58.3 Mbps",
+			"movie_hash":             "8e245d9679d31e12",
 		},
 	}
 
@@ -47,9 +47,8 @@ func validateTMDBAPIKey(apiKey string) tea.Cmd {
 
 		prov := tmdb.New()
 		cfg := map[string]interface{}{
-			"api_key":       apiKey,
-			"language":      "en-US",
-			"cache_enabled": false,
+			"api_key":  apiKey,
+			"language": "en-US",
 		}
 		if err := prov.Configure(cfg); err != nil {
 			return tmdbValidationMsg{apiKey: apiKey, valid: false}
@@ -35,6 +35,7 @@ func buildVariables(section Section, state *ConfigState, registry *config.Templa
 			{"TVDB API Key", "Generate from thetvdb.com account dashboard", "API key string"},
 			{"TMDB API Key", "Generate from the TMDB web console", "32 hex characters"},
 			{"TMDB Language", "Preferred metadata language code", "en-US, fr-FR, etc."},
+			{"Title Languages", "Set title_languages in config.json to order {title} languages", "[\"de\", \"en\", \"original\"]"},
 			{"ffprobe", "Enable codec metadata extraction", "Adds audio/video codec and resolution variables"},
 		}
 	}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/overrides"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/Digital-Shane/title-tidy/internal/tui/theme"
 
 	"charm.land/bubbles/v2/progress"
@@ -24,14 +26,24 @@ type metadataEventMsg struct {
 }
 
 type metadataRetryFinishedMsg struct {
-	provider core.MetadataProviderType
+	provider string
 	key      string
 	failure  *core.MetadataFailure
 	err      error
 }
 
+type metadataCandidatesMsg struct {
+	provider   string
+	key        string
+	candidates []provider.Candidate
+	err        error
+}
+
 const metadataErrorBaseLines = 6
 
+// metadataCandidateLimit caps how many search results the picker lists.
+const metadataCandidateLimit = 8
+
 func newMetadataSearchInput(th theme.Theme) textinput.Model {
 	ti := textinput.New()
 	ti.Prompt = ""
@@ -73,6 +85,14 @@ type MetadataProgressModel struct {
 	manualStatus        string
 	engineFinished      bool
 
+	candidates          []provider.Candidate
+	selectedCandidate   int
+	pickerActive        bool
+	searchingCandidates bool
+
+	// addAlias saves a title alias created with ctrl+a
+	addAlias func(local.Alias) error
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -95,38 +115,24 @@ func NewMetadataProgressModel(tree *treeview.Tree[treeview.FileInfo], cfg *confi
 	}
 	prog.SetWidth(50)
 
-	tmdbEnabled := cfg.EnableTMDBLookup && cfg.TMDBAPIKey != ""
-	tvdbEnabled := cfg.EnableTVDBLookup && cfg.TVDBAPIKey != ""
-	omdbEnabled := cfg.EnableOMDBLookup && cfg.OMDBAPIKey != ""
-	ffprobeEnabled := cfg.EnableFFProbe
+	localProv := local.New()
+	localProv.SetAliases(cfg.TitleAliases)
 
+	// Providers are read from the global registry, which the caller has
+	// already configured from cfg.
 	engineCfg := core.MetadataEngineConfig{
-		Tree:        tree,
-		WorkerCount: cfg.TMDBWorkerCount,
-		Providers: core.MetadataProvidersConfig{
-			TMDB: core.TMDBProviderConfig{
-				Enabled:  tmdbEnabled,
-				APIKey:   cfg.TMDBAPIKey,
-				Language: cfg.TMDBLanguage,
-			},
-			TVDB: core.TVDBProviderConfig{
-				Enabled: tvdbEnabled,
-				APIKey:  cfg.TVDBAPIKey,
-			},
-			OMDB: core.OMDBProviderConfig{
-				Enabled: omdbEnabled,
-				APIKey:  cfg.OMDBAPIKey,
-			},
-			FFProbe: core.FFProbeProviderConfig{Enabled: ffprobeEnabled},
-		},
-	}
-
-	if engineCfg.Providers.TMDB.Enabled {
-		if engineCfg.Providers.TMDB.Language == "" {
-			engineCfg.Providers.TMDB.Language = "en-US"
-		}
-		cacheEnabled := true
-		engineCfg.Providers.TMDB.CacheEnabled = &cacheEnabled
+		Tree:             tree,
+		LocalProvider:    localProv,
+		WorkerCount:      cfg.TMDBWorkerCount,
+		Registry:         provider.GlobalRegistry,
+		FieldPrecedence:  cfg.FieldPrecedence,
+		SearchStrategies: provider.ParseSearchStrategies(cfg.SearchStrategies),
+		MatchThreshold:   cfg.MatchThreshold,
+		Overrides:        openOverrides(),
+		WriteIDFiles:     cfg.WriteIDFiles,
+		EpisodeVariables: cfg.EpisodeVariables(),
+		FFProbeWorkers:   cfg.FFProbeWorkers,
+		Offline:          cfg.Offline,
 	}
 
 	engine := core.NewMetadataEngine(engineCfg)
@@ -142,7 +148,22 @@ func NewMetadataProgressModel(tree *treeview.Tree[treeview.FileInfo], cfg *confi
 		shouldRun:    len(summary.ActiveProviders) > 0,
 		input:        newMetadataSearchInput(th),
 		manualStatus: "",
+		addAlias:     config.AddTitleAlias,
+	}
+}
+
+// openOverrides loads the manual match override store. Overrides are an
+// optional convenience, so a store that cannot be read is skipped.
+func openOverrides() *overrides.Store {
+	path, err := overrides.DefaultPath()
+	if err != nil {
+		return nil
 	}
+	store, err := overrides.Open(path)
+	if err != nil {
+		return nil
+	}
+	return store
 }
 
 // Init starts the metadata engine if providers are configured.
@@ -198,6 +219,8 @@ func (m *MetadataProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleMetadataEvent(msg)
 	case metadataRetryFinishedMsg:
 		return m.handleRetryFinished(msg)
+	case metadataCandidatesMsg:
+		return m.handleCandidates(msg)
 	case progress.FrameMsg:
 		var cmd tea.Cmd
 		m.progress, cmd = m.progress.Update(msg)
@@ -290,18 +313,49 @@ func (m *MetadataProgressModel) handleRetryFinished(msg metadataRetryFinishedMsg
 	if len(m.failures) == 0 {
 		m.manualActive = false
 		m.done = true
-		m.manualStatus = fmt.Sprintf("Resolved metadata via %s", strings.ToUpper(string(msg.provider)))
+		m.manualStatus = fmt.Sprintf("Resolved metadata via %s", strings.ToUpper(msg.provider))
 		return m, tea.Quit
 	}
 	if m.selectedFailure >= len(m.failures) {
 		m.selectedFailure = len(m.failures) - 1
 	}
-	m.manualStatus = fmt.Sprintf("Resolved via %s. %d remaining.", strings.ToUpper(string(msg.provider)), len(m.failures))
+	m.manualStatus = fmt.Sprintf("Resolved via %s. %d remaining.", strings.ToUpper(msg.provider), len(m.failures))
 	m.prepareInputForSelection()
 	return m, nil
 }
 
+func (m *MetadataProgressModel) handleCandidates(msg metadataCandidatesMsg) (tea.Model, tea.Cmd) {
+	m.searchingCandidates = false
+	if len(m.failures) == 0 {
+		return m, nil
+	}
+	failure := m.failures[m.selectedFailure]
+	if failure.Item.Key != msg.key || failure.Provider != msg.provider {
+		// The selection moved while the search was running
+		return m, nil
+	}
+	if msg.err != nil {
+		m.manualStatus = fmt.Sprintf("Candidate search failed: %v", msg.err)
+		return m, nil
+	}
+	if len(msg.candidates) == 0 {
+		m.manualStatus = fmt.Sprintf("No %s candidates found for %q.", strings.ToUpper(msg.provider), m.input.Value())
+		return m, nil
+	}
+
+	m.candidates = msg.candidates
+	m.selectedCandidate = 0
+	m.pickerActive = true
+	m.input.Blur()
+	m.manualStatus = "Select the correct match and press Enter, or Esc to go back."
+	return m, nil
+}
+
 func (m *MetadataProgressModel) handleManualKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.pickerActive {
+		return m.handlePickerKey(msg)
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "esc":
 		if m.cancel != nil {
@@ -321,8 +375,34 @@ func (m *MetadataProgressModel) handleManualKey(msg tea.KeyPressMsg) (tea.Model,
 		failure := m.failures[m.selectedFailure]
 		query := m.input.Value()
 		m.retrying = true
-		m.manualStatus = fmt.Sprintf("Retrying %s…", strings.ToUpper(string(failure.Provider)))
-		return m, m.retryFailureCmd(failure, query)
+		m.manualStatus = fmt.Sprintf("Retrying %s…", strings.ToUpper(failure.Provider))
+		return m, m.retryFailureCmd(failure, query, "")
+	case "ctrl+f":
+		if m.retrying || m.searchingCandidates || len(m.failures) == 0 {
+			return m, nil
+		}
+		failure := m.failures[m.selectedFailure]
+		m.searchingCandidates = true
+		m.manualStatus = fmt.Sprintf("Searching %s candidates…", strings.ToUpper(failure.Provider))
+		return m, m.searchCandidatesCmd(failure, m.input.Value())
+	case "ctrl+a":
+		if m.retrying || len(m.failures) == 0 {
+			return m, nil
+		}
+		failure := m.failures[m.selectedFailure]
+		query := m.input.Value()
+		alias, ok := aliasForFailure(failure, query)
+		if !ok {
+			m.manualStatus = "Type the correct title to save it as an alias."
+			return m, nil
+		}
+		if err := m.addAlias(alias); err != nil {
+			m.manualStatus = fmt.Sprintf("Saving alias failed: %v", err)
+			return m, nil
+		}
+		m.retrying = true
+		m.manualStatus = fmt.Sprintf("Saved alias %q. Retrying %s…", alias.Name, strings.ToUpper(failure.Provider))
+		return m, m.retryFailureCmd(failure, query, "")
 	case "ctrl+s":
 		m.manualSkipped = true
 		m.manualActive = false
@@ -341,7 +421,70 @@ func (m *MetadataProgressModel) handleManualKey(msg tea.KeyPressMsg) (tea.Model,
 	return m, cmd
 }
 
-func (m *MetadataProgressModel) retryFailureCmd(failure core.MetadataFailure, query string) tea.Cmd {
+func (m *MetadataProgressModel) handlePickerKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tea.Quit
+	case "esc":
+		m.closePicker()
+		m.manualStatus = m.describeFailure(m.failures[m.selectedFailure])
+		return m, nil
+	case "up", "shift+tab":
+		if m.selectedCandidate > 0 {
+			m.selectedCandidate--
+		}
+		return m, nil
+	case "down", "tab":
+		if m.selectedCandidate < len(m.candidates)-1 {
+			m.selectedCandidate++
+		}
+		return m, nil
+	case "enter":
+		if m.retrying || len(m.failures) == 0 || len(m.candidates) == 0 {
+			return m, nil
+		}
+		failure := m.failures[m.selectedFailure]
+		candidate := m.candidates[m.selectedCandidate]
+		m.closePicker()
+		m.retrying = true
+		m.manualStatus = fmt.Sprintf("Fetching %s %s…", strings.ToUpper(failure.Provider), candidate.ID)
+		return m, m.retryFailureCmd(failure, m.input.Value(), candidate.ID)
+	}
+	return m, nil
+}
+
+// aliasForFailure builds the alias mapping the failed item's parsed name to
+// the title and year typed as the query. A parsed year is kept in the alias
+// name so the alias only applies to names parsed with it.
+func aliasForFailure(failure core.MetadataFailure, query string) (local.Alias, bool) {
+	name := failure.ParsedName
+	if name == "" {
+		name = failure.Item.Name
+	}
+	title, year := local.ExtractNameAndYear(strings.TrimSpace(query))
+	if name == "" || title == "" {
+		return local.Alias{}, false
+	}
+	if failure.Item.Year != "" {
+		name += " " + failure.Item.Year
+	}
+	if provider.NormalizeTitle(name) == provider.NormalizeTitle(strings.TrimSpace(title+" "+year)) {
+		return local.Alias{}, false
+	}
+	return local.Alias{Name: name, Title: title, Year: year}, true
+}
+
+func (m *MetadataProgressModel) closePicker() {
+	m.pickerActive = false
+	m.candidates = nil
+	m.selectedCandidate = 0
+	m.input.Focus()
+}
+
+func (m *MetadataProgressModel) retryFailureCmd(failure core.MetadataFailure, query, id string) tea.Cmd {
 	provider := failure.Provider
 	key := failure.Item.Key
 	return func() tea.Msg {
@@ -349,11 +492,24 @@ func (m *MetadataProgressModel) retryFailureCmd(failure core.MetadataFailure, qu
 		if retryCtx == nil || retryCtx.Err() != nil {
 			retryCtx = context.Background()
 		}
-		updated, err := m.engine.RetryProvider(retryCtx, key, provider, query)
+		updated, err := m.engine.RetryProvider(retryCtx, key, provider, query, id)
 		return metadataRetryFinishedMsg{provider: provider, key: key, failure: updated, err: err}
 	}
 }
 
+func (m *MetadataProgressModel) searchCandidatesCmd(failure core.MetadataFailure, query string) tea.Cmd {
+	provider := failure.Provider
+	key := failure.Item.Key
+	return func() tea.Msg {
+		searchCtx := m.ctx
+		if searchCtx == nil || searchCtx.Err() != nil {
+			searchCtx = context.Background()
+		}
+		candidates, err := m.engine.SearchCandidates(searchCtx, key, provider, query, metadataCandidateLimit)
+		return metadataCandidatesMsg{provider: provider, key: key, candidates: candidates, err: err}
+	}
+}
+
 func (m *MetadataProgressModel) updateFailure(updated core.MetadataFailure) {
 	for i := range m.failures {
 		if m.failures[i].Item.Key == updated.Item.Key && m.failures[i].Provider == updated.Provider {
@@ -409,7 +565,7 @@ func (m *MetadataProgressModel) updateInputWidth(width int) {
 }
 
 func (m *MetadataProgressModel) describeFailure(f core.MetadataFailure) string {
-	providerLabel := strings.ToUpper(string(f.Provider))
+	providerLabel := strings.ToUpper(f.Provider)
 	target := core.FormatMetadataProgressMessage(f.Item)
 	query := strings.TrimSpace(f.Query)
 	if query == "" {
@@ -446,8 +602,11 @@ func (m *MetadataProgressModel) View() tea.View {
 	}
 
 	headerText := "Fetching Metadata"
+	if m.summary.Offline {
+		headerText = "Reading Cached Metadata"
+	}
 	if len(m.summary.ActiveProviders) > 0 {
-		headerText = fmt.Sprintf("Fetching Metadata (%s)", strings.Join(m.summary.ActiveProviders, ", "))
+		headerText = fmt.Sprintf("%s (%s)", headerText, strings.Join(m.summary.ActiveProviders, ", "))
 	}
 
 	infoLines := []string{fmt.Sprintf("Items processed: %d/%d", m.summary.ProcessedItems, m.summary.TotalItems)}
@@ -458,6 +617,9 @@ func (m *MetadataProgressModel) View() tea.View {
 		fmt.Sprintf("Progress: %d%%", percent),
 		fmt.Sprintf("Max Worker Pool: %d workers", m.summary.WorkerLimit),
 	}
+	if m.summary.Offline {
+		statsLines = append(statsLines, fmt.Sprintf("Not in cache: %d", m.summary.Unenriched))
+	}
 
 	errors := make([]string, 0, len(m.errors))
 	for _, err := range m.errors {
@@ -511,14 +673,21 @@ func (m *MetadataProgressModel) renderManualResolutionView() string {
 	header := m.theme.HeaderStyle().Width(m.width).Render("Resolve Metadata Search")
 	infoStyle := lipgloss.NewStyle().Foreground(colors.Muted)
 	summaryLine := infoStyle.Width(m.width).Render(fmt.Sprintf("Failures remaining: %d (resolved: %d)", remaining, resolved))
-	instructions := infoStyle.Width(m.width).Render("Use ↑/↓ to choose an item, edit the search text, press Enter to retry, ctrl+s to skip.")
+	instructionText := "Use ↑/↓ to choose an item, edit the search text, press Enter to retry, ctrl+f to pick from matches, ctrl+a to retry and save the search as an alias, ctrl+s to skip."
+	if m.pickerActive {
+		instructionText = "Use ↑/↓ to choose a match, press Enter to use it, Esc to return to the search."
+	}
+	instructions := infoStyle.Width(m.width).Render(instructionText)
 
 	list := m.renderFailureList()
 	inputSection := m.renderSearchInput()
+	if m.pickerActive {
+		inputSection = m.renderCandidatePicker()
+	}
 
 	statusText := m.manualStatus
 	if m.retrying && remaining > 0 {
-		statusText = fmt.Sprintf("Retrying %s…", strings.ToUpper(string(m.failures[m.selectedFailure].Provider)))
+		statusText = fmt.Sprintf("Retrying %s…", strings.ToUpper(m.failures[m.selectedFailure].Provider))
 	}
 	if statusText == "" {
 		statusText = "Adjust the search term and press Enter to retry."
@@ -575,23 +744,76 @@ func (m *MetadataProgressModel) renderFailureList() string {
 		block := fmt.Sprintf(
 			"%s [%s] %s%s\n  query: %q\n  error: %s",
 			indicator,
-			strings.ToUpper(string(failure.Provider)),
+			strings.ToUpper(failure.Provider),
 			core.FormatMetadataProgressMessage(failure.Item),
 			attemptInfo,
 			query,
 			errText,
 		)
+		if len(failure.SearchAttempts) > 1 {
+			tried := make([]string, 0, len(failure.SearchAttempts))
+			for _, attempt := range failure.SearchAttempts {
+				tried = append(tried, attempt.String())
+			}
+			block += "\n  tried: " + strings.Join(tried, ", ")
+		}
 		entries = append(entries, style.Width(panelWidth).Render(block))
 	}
 
 	return panel.Width(panelWidth).Render(strings.Join(entries, "\n\n"))
 }
 
+func (m *MetadataProgressModel) renderCandidatePicker() string {
+	panel := m.theme.PanelStyle()
+	panelWidth := m.width - panel.GetHorizontalFrameSize()
+	if panelWidth < 20 {
+		panelWidth = 20
+	}
+
+	colors := m.theme.Colors()
+	normalStyle := lipgloss.NewStyle().Foreground(colors.Primary)
+	selectedStyle := lipgloss.NewStyle().Foreground(colors.Accent).Bold(true)
+	overviewStyle := lipgloss.NewStyle().Foreground(colors.Muted)
+
+	entries := make([]string, 0, len(m.candidates))
+	for i, candidate := range m.candidates {
+		indicator := "•"
+		style := normalStyle
+		if i == m.selectedCandidate {
+			indicator = "➜"
+			style = selectedStyle
+		}
+		title := candidate.Title
+		if candidate.Year != "" {
+			title = fmt.Sprintf("%s (%s)", title, candidate.Year)
+		}
+		line := fmt.Sprintf("%s %s · %s · ID %s · %.0f%% match", indicator, title, candidate.MediaType, candidate.ID, candidate.Confidence*100)
+		entry := style.Width(panelWidth).Render(line)
+		if overview := strings.TrimSpace(candidate.Overview); overview != "" {
+			entry += "\n" + overviewStyle.Width(panelWidth).Render("  "+truncateRunes(overview, panelWidth-4))
+		}
+		entries = append(entries, entry)
+	}
+
+	providerLabel := strings.ToUpper(m.failures[m.selectedFailure].Provider)
+	header := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%s matches for %q", providerLabel, m.input.Value()))
+	return panel.Width(panelWidth).Render(header + "\n" + strings.Join(entries, "\n"))
+}
+
+// truncateRunes shortens text to at most width runes, adding an ellipsis.
+func truncateRunes(text string, width int) string {
+	runes := []rune(text)
+	if width <= 1 || len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
+
 func (m *MetadataProgressModel) renderSearchInput() string {
 	if len(m.failures) == 0 {
 		return ""
 	}
-	providerLabel := strings.ToUpper(string(m.failures[m.selectedFailure].Provider))
+	providerLabel := strings.ToUpper(m.failures[m.selectedFailure].Provider)
 	title := fmt.Sprintf("Search term (%s): ", providerLabel)
 	text := m.input.View()
 	return lipgloss.NewStyle().Width(m.width).Render(title + text)
@@ -665,6 +887,11 @@ func (m *MetadataProgressModel) renderMetadataErrorBlock(errors []string) string
 	return errorStyle.Render(strings.Join(lines, "\n"))
 }
 
+// Summary returns the latest progress summary.
+func (m *MetadataProgressModel) Summary() core.MetadataSummary {
+	return m.summary
+}
+
 // Metadata returns the fetched metadata.
 func (m *MetadataProgressModel) Metadata() map[string]*provider.Metadata {
 	if m.engine == nil {
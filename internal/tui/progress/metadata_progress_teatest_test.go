@@ -15,6 +15,7 @@ import (
 	"github.com/Digital-Shane/title-tidy/internal/config"
 	"github.com/Digital-Shane/title-tidy/internal/core"
 	"github.com/Digital-Shane/title-tidy/internal/provider"
+	"github.com/Digital-Shane/title-tidy/internal/provider/local"
 	"github.com/Digital-Shane/title-tidy/internal/tui/theme"
 	"github.com/Digital-Shane/treeview/v2"
 	"github.com/charmbracelet/x/exp/teatest/v2"
@@ -78,19 +79,13 @@ func newMetadataFakeProvider(name string, fetch func(provider.FetchRequest) (*pr
 }
 
 func configureTestEngine(model *MetadataProgressModel, tree *treeview.Tree[treeview.FileInfo], prov provider.Provider, workerCount int) {
-	cache := true
+	reg := provider.NewRegistry()
+	_ = reg.Register("tmdb", prov, 100)
+	_ = reg.Enable("tmdb")
 	cfg := core.MetadataEngineConfig{
 		Tree:        tree,
 		WorkerCount: workerCount,
-		Providers: core.MetadataProvidersConfig{
-			TMDB: core.TMDBProviderConfig{
-				Enabled:      true,
-				APIKey:       "test-key",
-				Language:     "en-US",
-				CacheEnabled: &cache,
-				Provider:     prov,
-			},
-		},
+		Registry:    reg,
 	}
 	engine := core.NewMetadataEngine(cfg)
 	model.engine = engine
@@ -466,3 +461,103 @@ func TestMetadataProgressManualSkipAllowsContinue(t *testing.T) {
 		t.Errorf("Err() = %v, want nil when skipping", finalModel.Err())
 	}
 }
+
+type metadataCandidateFakeProvider struct {
+	*metadataFakeProvider
+	candidates []provider.Candidate
+}
+
+func (p *metadataCandidateFakeProvider) SearchCandidates(_ context.Context, req provider.FetchRequest, limit int) ([]provider.Candidate, error) {
+	return provider.RankCandidates(req.Name, req.Year, req.MediaType, p.candidates, limit), nil
+}
+
+func TestMetadataProgressCandidatePickerResolvesFailure(t *testing.T) {
+	tree := newSingleMovieTree()
+
+	cfg := &config.FormatConfig{TMDBWorkerCount: 1}
+	model := NewMetadataProgressModel(tree, cfg, theme.Default())
+	fake := &metadataCandidateFakeProvider{
+		metadataFakeProvider: newMetadataFakeProvider("fakeTMDB", func(req provider.FetchRequest) (*provider.Metadata, error) {
+			if req.ID == "42" {
+				return &provider.Metadata{Core: provider.CoreMetadata{Title: "Picked Movie", MediaType: req.MediaType}}, nil
+			}
+			return nil, &provider.ProviderError{Provider: "fakeTMDB", Code: "NOT_FOUND", Message: fmt.Sprintf("no results for %s", req.Name), Retry: false}
+		}),
+		candidates: []provider.Candidate{
+			{ID: "41", Title: "Manual Movie", Year: "1980", MediaType: provider.MediaTypeMovie},
+			{ID: "42", Title: "Manual Movie", Year: "2022", MediaType: provider.MediaTypeMovie, Overview: "The one we want."},
+		},
+	}
+	configureTestEngine(model, tree, fake, 1)
+
+	tm := newMetadataProgressTestModel(t, model, teatest.WithInitialTermSize(120, 30))
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("Resolve Metadata Search"))
+	}, teatest.WithDuration(2*time.Second))
+
+	tm.Send(tea.KeyPressMsg{Code: 'f', Mod: tea.ModCtrl})
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("The one we want."))
+	}, teatest.WithDuration(2*time.Second))
+
+	tm.Send(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+	finalModel := finalMetadataProgressModel(t, tm)
+
+	if !finalModel.done {
+		t.Error("done = false, want true after picking a candidate")
+	}
+	if finalModel.pickerActive {
+		t.Error("pickerActive = true, want false after picking a candidate")
+	}
+	found := false
+	for _, meta := range finalModel.Metadata() {
+		if meta != nil && meta.Core.Title == "Picked Movie" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Metadata() missing picked title; metadata = %+v", finalModel.Metadata())
+	}
+}
+
+func TestMetadataProgressManualAliasSavesAndRetries(t *testing.T) {
+	tree := newSingleMovieTree()
+
+	cfg := &config.FormatConfig{TMDBWorkerCount: 1}
+	model := NewMetadataProgressModel(tree, cfg, theme.Default())
+	var saved []local.Alias
+	model.addAlias = func(alias local.Alias) error {
+		saved = append(saved, alias)
+		return nil
+	}
+	provider := newMetadataFakeProvider("fakeTMDB", func(req provider.FetchRequest) (*provider.Metadata, error) {
+		if req.Name == "Canonical Movie" {
+			return &provider.Metadata{Core: provider.CoreMetadata{Title: req.Name, Year: req.Year, MediaType: req.MediaType}}, nil
+		}
+		return nil, &provider.ProviderError{Provider: "fakeTMDB", Code: "NOT_FOUND", Message: fmt.Sprintf("no results for %s", req.Name), Retry: false}
+	})
+	configureTestEngine(model, tree, provider, 1)
+
+	tm := newMetadataProgressTestModel(t, model, teatest.WithInitialTermSize(90, 20))
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("Resolve Metadata Search"))
+	}, teatest.WithDuration(2*time.Second))
+
+	tm.Send(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	tm.Type("Canonical Movie")
+	tm.Send(tea.KeyPressMsg{Code: 'a', Mod: tea.ModCtrl})
+
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+	finalModel := finalMetadataProgressModel(t, tm)
+
+	want := []local.Alias{{Name: "Manual Movie 2022", Title: "Canonical Movie"}}
+	if diff := cmp.Diff(want, saved); diff != "" {
+		t.Errorf("saved aliases mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(0, len(finalModel.failures)); diff != "" {
+		t.Errorf("failures length mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -291,6 +291,39 @@ func TestLinkVirtualDir(t *testing.T) {
 }
 
 // Helper functions for creating test nodes
+func TestLinkVirtualDirUsesDestinationPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	linkPath := filepath.Join(tmpDir, "destination")
+
+	srcFile := filepath.Join(tmpDir, "video.mkv")
+	if err := os.WriteFile(srcFile, []byte("video content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	virtualDir := createTestDirNode("Test Movie (2024)", "")
+	mm := core.EnsureMeta(virtualDir)
+	mm.NewName = "Test Movie (2024)"
+	mm.IsVirtual = true
+	mm.NeedsDirectory = true
+	mm.DestinationPath = filepath.Join(linkPath, "Test Collection", "Test Movie (2024)")
+
+	child := createTestFileNode("video.mkv", srcFile)
+	virtualDir.AddChild(child)
+	core.EnsureMeta(child).NewName = "Test Movie (2024).mkv"
+
+	if _, errs := core.LinkVirtualDir(virtualDir, mm, linkPath); len(errs) > 0 {
+		t.Fatalf("core.LinkVirtualDir errors = %v, want none", errs)
+	}
+
+	linked := filepath.Join(linkPath, "Test Collection", "Test Movie (2024)", "Test Movie (2024).mkv")
+	if _, err := os.Stat(linked); err != nil {
+		t.Errorf("core.LinkVirtualDir child not linked under the destination path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(linkPath, "Test Movie (2024)")); !os.IsNotExist(err) {
+		t.Errorf("core.LinkVirtualDir created the folder at the link path, want only the destination path")
+	}
+}
+
 func createTestFileNode(name, path string) *treeview.Node[treeview.FileInfo] {
 	return treeview.NewNode(name, name, treeview.FileInfo{
 		FileInfo: &testFileInfo{name: name, isDir: false},
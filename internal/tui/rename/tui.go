@@ -630,6 +630,9 @@ func (m *RenameModel) updateStatsContent() {
 	if stats.toDeleteCount > 0 {
 		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("delete"), "To delete:", stats.toDeleteCount)
 	}
+	if stats.suspectCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("suspect"), "Suspect:", stats.suspectCount)
+	}
 
 	if stats.successCount > 0 || stats.errorCount > 0 {
 		b.WriteString("\nLast Operation:\n")
@@ -683,6 +686,7 @@ func (m *RenameModel) updateStatsContent() {
 //   - noChangeCount: nodes with a proposed name identical to current name.
 //   - successCount / errorCount: results from the last performRenames run.
 //   - toDeleteCount: nodes marked for deletion.
+//   - suspectCount: files the integrity check flagged.
 type Statistics struct {
 	showCount       int
 	seasonCount     int
@@ -695,6 +699,7 @@ type Statistics struct {
 	successCount    int
 	errorCount      int
 	toDeleteCount   int
+	suspectCount    int
 }
 
 // calculateStats walks the tree to produce aggregate counts while preserving
@@ -730,6 +735,9 @@ func (m *RenameModel) calculateStats() Statistics {
 		if !node.Data().IsDir() && local.IsSubtitle(node.Data().Name()) {
 			stats.subtitleCount++
 		}
+		if mm.Suspect != "" {
+			stats.suspectCount++
+		}
 		if mm.MarkedForDeletion {
 			stats.toDeleteCount++
 		} else if m.IsLinkMode {
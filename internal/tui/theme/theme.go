@@ -33,6 +33,7 @@ type Colors struct {
 	Muted      color.Color
 	Success    color.Color
 	Error      color.Color
+	Warning    color.Color
 }
 
 // Borders defines reusable border styles.
@@ -98,6 +99,7 @@ func New(opts ...Option) Theme {
 			Muted:      lipgloss.Color("#9ba8c0"),
 			Success:    lipgloss.Color("#5dc796"),
 			Error:      lipgloss.Color("#f04c56"),
+			Warning:    lipgloss.Color("#e5a93d"),
 		}),
 		WithBorders(Borders{Panel: lipgloss.RoundedBorder()}),
 		WithSpacing(Spacing{PanelPadding: 1, PanelGap: 2, StatusHPadding: 1}),
@@ -234,6 +236,7 @@ var emojiIcons = IconSet{
 	"success":    "✅",
 	"error":      "❌",
 	"delete":     "❌",
+	"suspect":    "❗",
 	"check":      "✅",
 	"needrename": "✓",
 	"nochange":   "=",
@@ -268,6 +271,7 @@ var asciiIcons = IconSet{
 	"success":    "[v]",
 	"error":      "[!]",
 	"delete":     "[x]",
+	"suspect":    "[?]",
 	"check":      "[✓]",
 	"needrename": "[+]",
 	"nochange":   "[=]",